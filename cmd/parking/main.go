@@ -11,8 +11,10 @@ import (
 
 	"github.com/PIRSON21/parking/internal/config"
 	"github.com/PIRSON21/parking/internal/http-server/handler/parking"
+	"github.com/PIRSON21/parking/internal/http-server/handler/simulation"
 	"github.com/PIRSON21/parking/internal/http-server/handler/user"
 	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
+	sim "github.com/PIRSON21/parking/internal/simulation"
 	"github.com/PIRSON21/parking/internal/storage/postgresql"
 	"github.com/PIRSON21/parking/internal/ws"
 	"github.com/go-chi/chi/v5"
@@ -61,6 +63,9 @@ func main() {
 	db := postgresql.MustConnectDB(cfg)
 	log.Info("DB connected successfully", slog.String("host", cfg.DBHost), slog.String("name", cfg.DBName))
 
+	// реестр сессий симуляции, к которым можно переподключиться
+	sessions := sim.NewRegistry(cfg.SessionGracePeriod, log)
+
 	// установка роутера chi
 	router := chi.NewRouter()
 
@@ -93,14 +98,23 @@ func main() {
 		usr.Route("/parking", func(r chi.Router) {
 			r.Get("/", parking.AllParkingsHandler(log, db, cfg))
 			r.Get("/{id}", parking.GetParkingHandler(log, db, cfg))
+			r.Get("/{id}/price", parking.PriceHandler(log, db, cfg))
 		})
 		usr.Get("/role", user.GetRoleHandler(log, cfg))
+		usr.Get("/ws/observe", ws.ObserveHandler(log, cfg, sessions))
 	})
 
 	router.Group(func(manager chi.Router) {
 		manager.Use(authMiddleware.AuthMiddleware(log, db))
 		manager.Use(authMiddleware.ManagerMiddleware)
-		manager.Get("/ws/simulate", ws.WebSocketHandler(log, cfg))
+		manager.Get("/ws/simulate", ws.WebSocketHandler(log, cfg, sessions, db))
+		manager.Get("/ws/replay/{id}", ws.ReplayHandler(log, cfg, db))
+		manager.Post("/simulate/batch", simulation.BatchHandler(log, cfg))
+		manager.Post("/simulate/batch/export", simulation.BatchExportHandler(log, cfg))
+		manager.Post("/simulate/replications", simulation.ReplicationsHandler(log, cfg))
+		manager.Get("/parking/{id}/runs", simulation.RunsHandler(log, db, cfg))
+		manager.Get("/simulate/runs/{id}", simulation.RunHandler(log, db, cfg))
+		manager.Get("/simulate/runs/{id}/export", simulation.RunExportHandler(log, db, cfg))
 	})
 
 	router.Group(func(admin chi.Router) {
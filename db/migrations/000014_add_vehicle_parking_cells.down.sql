@@ -0,0 +1,6 @@
+DELETE FROM parking_cell WHERE cell_type IN ('M', 'V', 'T');
+
+ALTER TABLE parking_cell DROP CONSTRAINT IF EXISTS parking_cell_cell_type_check;
+
+ALTER TABLE parking_cell
+    ADD CONSTRAINT parking_cell_cell_type_check CHECK (cell_type IN ('P', 'D', 'I', 'O'));
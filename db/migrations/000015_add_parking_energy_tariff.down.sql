@@ -0,0 +1,9 @@
+DELETE FROM parking_cell WHERE cell_type = 'E';
+
+ALTER TABLE parking_cell DROP CONSTRAINT IF EXISTS parking_cell_cell_type_check;
+
+ALTER TABLE parking_cell
+    ADD CONSTRAINT parking_cell_cell_type_check CHECK (cell_type IN ('P', 'M', 'V', 'T', 'D', 'I', 'O'));
+
+ALTER TABLE parkings
+DROP COLUMN energy_tariff;
@@ -0,0 +1,7 @@
+ALTER TABLE parkings
+ADD energy_tariff INT DEFAULT 0;
+
+ALTER TABLE parking_cell DROP CONSTRAINT IF EXISTS parking_cell_cell_type_check;
+
+ALTER TABLE parking_cell
+    ADD CONSTRAINT parking_cell_cell_type_check CHECK (cell_type IN ('P', 'M', 'V', 'T', 'E', 'D', 'I', 'O'));
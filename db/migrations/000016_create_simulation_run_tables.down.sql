@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS simulation_event;
+
+DROP TABLE IF EXISTS simulation_run;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS simulation_run (
+    run_id SERIAL PRIMARY KEY,
+    parking_id INT NULL REFERENCES parkings(parking_id) ON DELETE SET NULL,
+    manager_id INT NOT NULL REFERENCES manager(manager_id) ON DELETE CASCADE,
+    seed BIGINT NOT NULL,
+    strategy VARCHAR(32) NOT NULL,
+    params JSONB NOT NULL,
+    summary JSONB NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_simulation_run_parking_id ON simulation_run(parking_id);
+
+CREATE TABLE IF NOT EXISTS simulation_event (
+    run_id INT NOT NULL REFERENCES simulation_run(run_id) ON DELETE CASCADE,
+    seq INT NOT NULL,
+    event_type VARCHAR(16) NOT NULL,
+    sim_time BIGINT NOT NULL,
+    car_id VARCHAR(36) NOT NULL,
+    payload JSONB NOT NULL,
+    PRIMARY KEY (run_id, seq)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE parkings DROP COLUMN IF EXISTS holidays;
+
+ALTER TABLE parkings DROP COLUMN IF EXISTS tariff_periods;
@@ -0,0 +1,5 @@
+ALTER TABLE parkings
+ADD COLUMN IF NOT EXISTS tariff_periods JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE parkings
+ADD COLUMN IF NOT EXISTS holidays JSONB NOT NULL DEFAULT '[]';
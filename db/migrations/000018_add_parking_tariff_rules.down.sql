@@ -0,0 +1 @@
+ALTER TABLE parkings DROP COLUMN IF EXISTS tariff_rules;
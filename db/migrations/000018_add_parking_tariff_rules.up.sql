@@ -0,0 +1,2 @@
+ALTER TABLE parkings
+ADD COLUMN IF NOT EXISTS tariff_rules JSONB NULL;
@@ -0,0 +1,24 @@
+UPDATE parkings
+SET tariff_rules = tariff_rules
+    || CASE WHEN tariff_rules ? 'daily_cap'
+        THEN jsonb_build_object('daily_cap', round((tariff_rules->'daily_cap'->>'amount')::numeric / 100))
+        ELSE '{}' END
+    || CASE WHEN tariff_rules ? 'min_charge'
+        THEN jsonb_build_object('min_charge', round((tariff_rules->'min_charge'->>'amount')::numeric / 100))
+        ELSE '{}' END
+WHERE tariff_rules IS NOT NULL;
+
+UPDATE parkings
+SET tariff_periods = (
+    SELECT COALESCE(jsonb_agg(
+        period || jsonb_build_object('rate', round((period->'rate'->>'amount')::numeric / 100))
+    ), '[]')
+    FROM jsonb_array_elements(tariff_periods) AS period
+)
+WHERE jsonb_array_length(tariff_periods) > 0;
+
+ALTER TABLE parkings ALTER COLUMN energy_tariff TYPE INT USING round(energy_tariff / 100.0);
+ALTER TABLE parkings ALTER COLUMN night_tariff TYPE INT USING round(night_tariff / 100.0);
+ALTER TABLE parkings ALTER COLUMN day_tariff TYPE INT USING round(day_tariff / 100.0);
+
+ALTER TABLE parkings DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,25 @@
+ALTER TABLE parkings
+ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE parkings ALTER COLUMN day_tariff TYPE BIGINT USING day_tariff * 100;
+ALTER TABLE parkings ALTER COLUMN night_tariff TYPE BIGINT USING night_tariff * 100;
+ALTER TABLE parkings ALTER COLUMN energy_tariff TYPE BIGINT USING energy_tariff * 100;
+
+UPDATE parkings
+SET tariff_periods = (
+    SELECT COALESCE(jsonb_agg(
+        period || jsonb_build_object('rate', jsonb_build_object('amount', round((period->>'rate')::numeric * 100), 'currency', currency))
+    ), '[]')
+    FROM jsonb_array_elements(tariff_periods) AS period
+)
+WHERE jsonb_array_length(tariff_periods) > 0;
+
+UPDATE parkings
+SET tariff_rules = tariff_rules
+    || CASE WHEN tariff_rules ? 'daily_cap'
+        THEN jsonb_build_object('daily_cap', jsonb_build_object('amount', round((tariff_rules->>'daily_cap')::numeric * 100), 'currency', currency))
+        ELSE '{}' END
+    || CASE WHEN tariff_rules ? 'min_charge'
+        THEN jsonb_build_object('min_charge', jsonb_build_object('amount', round((tariff_rules->>'min_charge')::numeric * 100), 'currency', currency))
+        ELSE '{}' END
+WHERE tariff_rules IS NOT NULL;
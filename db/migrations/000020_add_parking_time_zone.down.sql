@@ -0,0 +1 @@
+ALTER TABLE parkings DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,2 @@
+ALTER TABLE parkings
+ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT 'Europe/Moscow';
@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -9,6 +10,8 @@ import (
 type Config struct {
 	Environment string `env:"ENV" 	  envDefault:"prod"`
 	Address     string `env:"ADDRESS" envDefault:"localhost:8000"`
+	// SessionGracePeriod - сколько сессия симуляции ждет переподключения клиента.
+	SessionGracePeriod time.Duration `env:"SESSION_GRACE_PERIOD" envDefault:"5m"`
 	ConfigDB
 }
 
@@ -50,8 +50,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 			},
 			GetParkingsError: nil,
@@ -62,8 +62,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -80,8 +80,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 			},
 			UserID:           1,
@@ -93,8 +93,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -111,8 +111,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 				{
 					ID:          2,
@@ -120,8 +120,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       4,
 					Height:      4,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 			},
 			GetParkingsError: nil,
@@ -132,8 +132,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -141,8 +141,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          2,
 					Name:        "2: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/2",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -159,8 +159,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 					Cells: [][]models.ParkingCell{
 						{
 							".", ".", ".",
@@ -179,8 +179,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       4,
 					Height:      4,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 					Cells: [][]models.ParkingCell{
 						{
 							".", ".", ".",
@@ -203,8 +203,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells: [][]models.ParkingCell{
 						{
@@ -222,8 +222,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          2,
 					Name:        "2: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/2",
 					Cells: [][]models.ParkingCell{
 						{
@@ -250,8 +250,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 					Cells: [][]models.ParkingCell{
 						{
 							".", ".", ".",
@@ -270,8 +270,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       4,
 					Height:      4,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 					Cells: [][]models.ParkingCell{
 						{
 							".", ".", ".",
@@ -294,8 +294,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells: [][]models.ParkingCell{
 						{
@@ -313,8 +313,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          2,
 					Name:        "2: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/2",
 					Cells: [][]models.ParkingCell{
 						{
@@ -341,8 +341,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 				{
 					ID:          2,
@@ -350,8 +350,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       4,
 					Height:      4,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 			},
 			UserID:           1,
@@ -363,8 +363,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -372,8 +372,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          2,
 					Name:        "2: Центр",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/2",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -390,8 +390,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 			},
 			GetParkingsError: nil,
@@ -402,8 +402,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: aboba",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -420,8 +420,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					Address:     "ул. Пушкина, д. Колотушкина",
 					Width:       5,
 					Height:      5,
-					DayTariff:   test.NewInt(5),
-					NightTariff: test.NewInt(1),
+					DayTariff:   test.NewMoney(500),
+					NightTariff: test.NewMoney(100),
 				},
 			},
 			UserID:           1,
@@ -433,8 +433,8 @@ func TestAllParkingsHandler(t *testing.T) {
 					ID:          1,
 					Name:        "1: aboba",
 					Address:     "ул. Пушкина, д. Колотушкина",
-					DayTariff:   5,
-					NightTariff: 1,
+					DayTariff:   *test.NewMoney(500),
+					NightTariff: *test.NewMoney(100),
 					URL:         "/parking/1",
 					Cells:       [][]models.ParkingCell{},
 				},
@@ -570,8 +570,8 @@ func TestGetParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       4,
 				Height:      4,
-				DayTariff:   test.NewInt(0),
-				NightTariff: test.NewInt(0),
+				DayTariff:   test.NewMoney(0),
+				NightTariff: test.NewMoney(0),
 				Cells: [][]models.ParkingCell{
 					{
 						"P", "P", "P", "P",
@@ -596,8 +596,8 @@ func TestGetParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       4,
 				Height:      4,
-				DayTariff:   test.NewInt(0),
-				NightTariff: test.NewInt(0),
+				DayTariff:   test.NewMoney(0),
+				NightTariff: test.NewMoney(0),
 				Cells: [][]models.ParkingCell{
 					{
 						"P", "P", "P", "P",
@@ -623,8 +623,8 @@ func TestGetParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       4,
 				Height:      4,
-				DayTariff:   test.NewInt(0),
-				NightTariff: test.NewInt(0),
+				DayTariff:   test.NewMoney(0),
+				NightTariff: test.NewMoney(0),
 				Cells:       nil,
 			},
 			ResponseCode:    http.StatusOK,
@@ -636,8 +636,8 @@ func TestGetParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       4,
 				Height:      4,
-				DayTariff:   test.NewInt(0),
-				NightTariff: test.NewInt(0),
+				DayTariff:   test.NewMoney(0),
+				NightTariff: test.NewMoney(0),
 				Cells:       nil,
 			}),
 			JSON: true,
@@ -666,8 +666,8 @@ func TestGetParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       4,
 				Height:      4,
-				DayTariff:   test.NewInt(0),
-				NightTariff: test.NewInt(0),
+				DayTariff:   test.NewMoney(0),
+				NightTariff: test.NewMoney(0),
 				Cells:       nil,
 			},
 			ResponseCode:    http.StatusInternalServerError,
@@ -694,8 +694,8 @@ func TestGetParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       4,
 				Height:      4,
-				DayTariff:   test.NewInt(1),
-				NightTariff: test.NewInt(2),
+				DayTariff:   test.NewMoney(100),
+				NightTariff: test.NewMoney(200),
 				Cells:       nil,
 				Manager:     &models.Manager{ID: 1},
 			},
@@ -708,8 +708,8 @@ func TestGetParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       4,
-				DayTariff:   test.NewInt(1),
-				NightTariff: test.NewInt(2),
+				DayTariff:   test.NewMoney(100),
+				NightTariff: test.NewMoney(200),
 				Height:      4,
 				Cells:       nil,
 				Manager:     &models.Manager{ID: 1},
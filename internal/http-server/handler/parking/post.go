@@ -10,6 +10,7 @@ import (
 	"github.com/PIRSON21/parking/internal/config"
 	resp "github.com/PIRSON21/parking/internal/lib/api/response"
 	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/money"
 	customValidator "github.com/PIRSON21/parking/internal/lib/validator"
 	"github.com/PIRSON21/parking/internal/models"
 	"github.com/go-chi/chi/v5"
@@ -129,15 +130,24 @@ func DeleteParkingHandler(log *slog.Logger, db ParkingSetter, cfg *config.Config
 }
 
 type ParkingPatch struct {
-	ID          int                    `json:"id,omitempty"`
-	Name        *string                `json:"name,omitempty" validate:"omitempty,min=3,max=10"`
-	Address     *string                `json:"address,omitempty" validate:"omitempty,min=10,max=30"`
-	Width       *int                   `json:"width,omitempty" validate:"omitempty,gte=4,lte=6"`
-	Height      *int                   `json:"height,omitempty" validate:"omitempty,gte=4,lte=6"`
-	DayTariff   *int                   `json:"day_tariff,omitempty" validate:"omitempty,gte=0,lte=1000"`
-	NightTariff *int                   `json:"night_tariff,omitempty" validate:"omitempty,gte=0,lte=1000"`
-	Cells       [][]models.ParkingCell `json:"cells,omitempty"`
-	Manager     *models.Manager        `json:"manager,omitempty"`
+	ID           int          `json:"id,omitempty"`
+	Name         *string      `json:"name,omitempty" validate:"omitempty,min=3,max=10"`
+	Address      *string      `json:"address,omitempty" validate:"omitempty,min=10,max=30"`
+	Width        *int         `json:"width,omitempty" validate:"omitempty,gte=4,lte=6"`
+	Height       *int         `json:"height,omitempty" validate:"omitempty,gte=4,lte=6"`
+	DayTariff    *money.Money `json:"day_tariff,omitempty" validate:"omitempty,gte=0,lte=100000"`
+	NightTariff  *money.Money `json:"night_tariff,omitempty" validate:"omitempty,gte=0,lte=100000"`
+	EnergyTariff *money.Money `json:"energy_tariff,omitempty" validate:"omitempty,gte=0,lte=100000"`
+	// TariffPeriods заменяет все тарифные периоды парковки, пустой список возвращает дневной и ночной тарифы.
+	TariffPeriods []models.TariffPeriod `json:"tariff_periods,omitempty" validate:"omitempty,tariff_periods,dive"`
+	// TariffRules заменяет правила тарификации парковки.
+	TariffRules *models.TariffRules `json:"tariff_rules,omitempty"`
+	// Holidays заменяет список праздников парковки.
+	Holidays []string `json:"holidays,omitempty" validate:"omitempty,dive,datetime=2006-01-02"`
+	// TimeZone меняет часовой пояс IANA парковки.
+	TimeZone *string                `json:"time_zone,omitempty" validate:"omitempty,timezone"`
+	Cells    [][]models.ParkingCell `json:"cells,omitempty"`
+	Manager  *models.Manager        `json:"manager,omitempty"`
 }
 
 // UpdateParkingHandler обновляет данные о парковке.
@@ -207,6 +217,12 @@ func UpdateParkingHandler(log *slog.Logger, db ParkingSetter, cfg *config.Config
 		log.Debug("updating parkings")
 		parking, err := db.UpdateParking(&parkingUpdates, cellStruct)
 		if err != nil {
+			if errors.Is(err, custErr.ErrCurrencyMismatch) {
+				log.Debug("currency mismatch", slog.String("err", err.Error()))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.UnknownError(custErr.ErrCurrencyMismatch.Error()))
+				return
+			}
 			log.Error("error while updating parking", slog.String("err", err.Error()))
 			resp.ErrorHandler(w, r, cfg, err)
 			return
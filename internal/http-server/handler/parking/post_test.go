@@ -13,6 +13,7 @@ import (
 	"github.com/PIRSON21/parking/internal/http-server/handler/parking"
 	"github.com/PIRSON21/parking/internal/http-server/handler/parking/mocks"
 	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/money"
 	"github.com/PIRSON21/parking/internal/lib/test"
 	"github.com/PIRSON21/parking/internal/models"
 	"github.com/go-chi/chi/v5"
@@ -67,8 +68,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
@@ -84,8 +85,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Cells: [][]models.ParkingCell{
 					{".", ".", ".", ".", "."},
 					{".", "P", "P", "P", "."},
@@ -106,8 +107,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -121,8 +122,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Width:       5,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -136,8 +137,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -151,8 +152,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -166,8 +167,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "a",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
@@ -182,8 +183,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
@@ -198,8 +199,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "a",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
@@ -215,8 +216,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкинаaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
 				Width:       5,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -231,8 +232,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       1,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -247,8 +248,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       10,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -263,8 +264,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
 				Height:      1,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
@@ -278,8 +279,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      10,
 			}),
 			AddParkingError:         nil,
@@ -294,8 +295,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      5,
 				Cells: [][]models.ParkingCell{
 					{".", ".", ".", ".", ".", "."},
@@ -318,8 +319,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      5,
 				Cells: [][]models.ParkingCell{
 					{".", ".", ".", ".", "."},
@@ -343,8 +344,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 				Height:      5,
 				Cells: [][]models.ParkingCell{
 					{".", ".", ".", ".", "."},
@@ -368,8 +369,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         xerrors.Errorf("test parking error"),
 			AddCellsForParkingError: nil,
@@ -385,8 +386,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
 				Height:      5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
 			}),
 			AddParkingError:         xerrors.Errorf("test parking error"),
 			AddCellsForParkingError: nil,
@@ -401,14 +402,14 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(1001),
-				NightTariff: test.NewInt(5),
+				DayTariff:   test.NewMoney(100001),
+				NightTariff: test.NewMoney(500),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
 			ResponseCode:            http.StatusBadRequest,
-			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "day_tariff", fmt.Sprintf(test.Lte, 1000)),
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "day_tariff", fmt.Sprintf(test.Lte, 100000)),
 			JSON:                    true,
 		},
 		{
@@ -417,8 +418,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(-1),
-				NightTariff: test.NewInt(5),
+				DayTariff:   test.NewMoney(-1),
+				NightTariff: test.NewMoney(500),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
@@ -433,14 +434,30 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(1001),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100001),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
 			AddCellsForParkingError: nil,
 			ResponseCode:            http.StatusBadRequest,
-			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "night_tariff", fmt.Sprintf(test.Lte, 1000)),
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "night_tariff", fmt.Sprintf(test.Lte, 100000)),
+			JSON:                    true,
+		},
+		{
+			Name: "NightTariff currency mismatch",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: money.NewPtr(100, "USD"),
+				Height:      5,
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "night_tariff", "Сумма должна быть в валюте парковки RUB"),
 			JSON:                    true,
 		},
 		{
@@ -449,8 +466,8 @@ func TestAddParkingHandler(t *testing.T) {
 				Name:        "1: Центр",
 				Address:     "ул. Пушкина, д. Колотушкина",
 				Width:       5,
-				DayTariff:   test.NewInt(5),
-				NightTariff: test.NewInt(-1),
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(-1),
 				Height:      5,
 			}),
 			AddParkingError:         nil,
@@ -459,6 +476,208 @@ func TestAddParkingHandler(t *testing.T) {
 			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "night_tariff", fmt.Sprintf(test.Gte, 0)),
 			JSON:                    true,
 		},
+		{
+			Name: "Success with tariff periods",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TariffPeriods: []models.TariffPeriod{
+					{Name: "День", DayType: models.Weekday, Start: "07:00", Rate: test.NewMoney(10000)},
+					{Name: "Ночь", DayType: models.Weekday, Start: "21:00", Rate: test.NewMoney(3000)},
+					{Name: "Выходной", DayType: models.Weekend, Start: "00:00", Rate: test.NewMoney(5000)},
+					{Name: "Праздник", DayType: models.Holiday, Start: "00:00", Rate: test.NewMoney(0)},
+				},
+				Holidays: []string{"2025-01-01", "2025-05-09"},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusCreated,
+			ExpectedResponse:        "",
+			JSON:                    false,
+		},
+		{
+			Name: "Wrong tariff period start",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TariffPeriods: []models.TariffPeriod{
+					{DayType: models.Weekday, Start: "7 утра", Rate: test.NewMoney(10000)},
+				},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "start", "Значение должно быть в формате 15:04"),
+			JSON:                    true,
+		},
+		{
+			Name: "Wrong tariff period day type",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TariffPeriods: []models.TariffPeriod{
+					{DayType: "monday", Start: "07:00", Rate: test.NewMoney(10000)},
+				},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "day_type", "Значение должно быть одним из: weekday weekend holiday"),
+			JSON:                    true,
+		},
+		{
+			Name: "Tariff period rate upper max",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TariffPeriods: []models.TariffPeriod{
+					{DayType: models.Weekend, Start: "07:00", Rate: test.NewMoney(100001)},
+				},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "rate", fmt.Sprintf(test.Lte, 100000)),
+			JSON:                    true,
+		},
+		{
+			Name: "Repeated tariff period start",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TariffPeriods: []models.TariffPeriod{
+					{DayType: models.Weekday, Start: "07:00", Rate: test.NewMoney(10000)},
+					{DayType: models.Weekday, Start: "07:00", Rate: test.NewMoney(3000)},
+				},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "tariff_periods", "Время начала периодов одного типа дня не должно повторяться"),
+			JSON:                    true,
+		},
+		{
+			Name: "Wrong billing unit",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TariffRules: &models.TariffRules{BillingUnit: "day"},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "billing_unit", "Значение должно быть одним из: exact quarter hour"),
+			JSON:                    true,
+		},
+		{
+			Name: "Grace period upper max",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TariffRules: &models.TariffRules{GracePeriod: 1441},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "grace_period", fmt.Sprintf(test.Lte, 1440)),
+			JSON:                    true,
+		},
+		{
+			Name: "Wrong holiday date",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				Holidays:    []string{"01.01.2025"},
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "holidays[0]", "Значение должно быть в формате 2006-01-02"),
+			JSON:                    true,
+		},
+		{
+			Name: "Success with time zone",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TimeZone:    "Asia/Vladivostok",
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusCreated,
+			ExpectedResponse:        "",
+			JSON:                    false,
+		},
+		{
+			Name: "Wrong time zone",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TimeZone:    "Moscow",
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "time_zone", "Значение должно быть часовым поясом IANA, например Europe/Moscow"),
+			JSON:                    true,
+		},
+		{
+			Name: "Local time zone",
+			RequestBody: test.MustMarshal(models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       5,
+				DayTariff:   test.NewMoney(500),
+				NightTariff: test.NewMoney(100),
+				Height:      5,
+				TimeZone:    "Local",
+			}),
+			AddParkingError:         nil,
+			AddCellsForParkingError: nil,
+			ResponseCode:            http.StatusBadRequest,
+			ExpectedResponse:        fmt.Sprintf(test.ExpectedValidationError, "time_zone", "Значение должно быть часовым поясом IANA, например Europe/Moscow"),
+			JSON:                    true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,97 @@
+package parking
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/pricing"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"golang.org/x/xerrors"
+)
+
+// maxStay - максимальная длительность стоянки, для которой рассчитывается стоимость.
+const maxStay = 31 * 24 * time.Hour
+
+// errInvalidStay - ошибка параметров стоянки в запросе расчета стоимости.
+var errInvalidStay = errors.New("параметры from и to должны быть unix-временем, from не позже to, стоянка не дольше 31 дня")
+
+// PriceHandler рассчитывает стоимость стоянки на парковке по ее тарифам и правилам тарификации.
+//
+// Время въезда и выезда передается в параметрах ?from= и ?to= (unix-время в секундах).
+// Тарифные периоды и сутки для максимума платы определяются в часовом поясе парковки.
+func PriceHandler(log *slog.Logger, parkingGetter ParkingGetter, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http-server.handler.parking.PriceHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("reqID", middleware.GetReqID(r.Context())),
+		)
+
+		parkingID, err := getParkingID(r)
+		if err != nil {
+			log.Error("error while getting ID from url", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(err.Error()))
+			return
+		}
+
+		entered, left, err := getStay(r)
+		if err != nil {
+			log.Error("error while getting stay from query", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(err.Error()))
+			return
+		}
+
+		userID := getUserID(r)
+		if userID == -1 {
+			log.Error("error while getting userID from context", slog.String("err", "userID not found in context"))
+			resp.ErrorHandler(w, r, cfg, xerrors.Errorf("%s: error while getting userID", op))
+			return
+		}
+
+		parking, err := parkingGetter.GetParkingByID(parkingID, userID)
+		if err != nil {
+			if errors.Is(err, custErr.ErrParkingNotFound) || errors.Is(err, custErr.ErrParkingAccessDenied) {
+				log.Debug("parking not found", slog.Int("parkingID", parkingID), slog.Int("userID", userID))
+				http.NotFound(w, r)
+				return
+			}
+
+			log.Error("error while getting Parking from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		quote := pricing.NewEngine(parking).Quote(entered, left)
+		log.Debug("price calculated", slog.Int("parkingID", parkingID), slog.String("price", quote.Price.String()))
+
+		render.JSON(w, r, quote)
+	}
+}
+
+// getStay получает время въезда и выезда из параметров ?from= и ?to=.
+// Стоянка не может быть длиннее maxStay.
+func getStay(r *http.Request) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+
+	from, err := strconv.ParseInt(query.Get("from"), 10, 64)
+	if err != nil {
+		return time.Time{}, time.Time{}, errInvalidStay
+	}
+
+	to, err := strconv.ParseInt(query.Get("to"), 10, 64)
+	if err != nil || to < from || to-from > int64(maxStay/time.Second) {
+		return time.Time{}, time.Time{}, errInvalidStay
+	}
+
+	return time.Unix(from, 0), time.Unix(to, 0), nil
+}
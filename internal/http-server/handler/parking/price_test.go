@@ -0,0 +1,164 @@
+package parking_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PIRSON21/parking/internal/config"
+	"github.com/PIRSON21/parking/internal/http-server/handler/parking"
+	"github.com/PIRSON21/parking/internal/http-server/handler/parking/mocks"
+	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+const urlParkingPrice = "/parking/%d/price"
+
+func TestPriceHandler(t *testing.T) {
+	const stayError = "параметры from и to должны быть unix-временем, from не позже to, стоянка не дольше 31 дня"
+
+	// newParking создает парковку с одинаковой ставкой днем и ночью, чтобы цена не зависела от часового пояса.
+	newParking := func(rules *models.TariffRules) *models.Parking {
+		return &models.Parking{
+			ID:          1,
+			Name:        "1: Центр",
+			Address:     "ул. Пушкина, д. Колотушкина",
+			Width:       4,
+			Height:      4,
+			DayTariff:   test.NewMoney(6000),
+			NightTariff: test.NewMoney(6000),
+			TariffRules: rules,
+		}
+	}
+
+	cases := []struct {
+		Name string
+		// Query - параметры запроса
+		Query string
+		// Parking - парковка из БД. Если nil, запроса к БД быть не должно
+		Parking         *models.Parking
+		GetParkingError error
+		Environment     string
+		ResponseCode    int
+		ResponseBody    string
+		JSON            bool
+	}{
+		{
+			Name:         "Success pro-rata",
+			Query:        "?from=1736330400&to=1736335800",
+			Parking:      newParking(nil),
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"price":{"amount":9000,"currency":"RUB"},"minutes":90}`,
+			JSON:         true,
+		},
+		{
+			Name:         "Success per started hour with minimum charge",
+			Query:        "?from=1736330400&to=1736330700",
+			Parking:      newParking(&models.TariffRules{BillingUnit: models.BillingHour, MinCharge: test.NewMoney(10000)}),
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"price":{"amount":10000,"currency":"RUB"},"minutes":5,"min_charge":true}`,
+			JSON:         true,
+		},
+		{
+			Name:         "Success grace period",
+			Query:        "?from=1736330400&to=1736330700",
+			Parking:      newParking(&models.TariffRules{GracePeriod: 10}),
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"price":{"amount":0,"currency":"RUB"},"minutes":5,"free":true}`,
+			JSON:         true,
+		},
+		{
+			Name:         "No from",
+			Query:        "?to=1736330700",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, stayError),
+			JSON:         true,
+		},
+		{
+			Name:         "To before from",
+			Query:        "?from=1736330700&to=1736330400",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, stayError),
+			JSON:         true,
+		},
+		{
+			Name:         "Stay over max",
+			Query:        "?from=0&to=9000000000000000",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, stayError),
+			JSON:         true,
+		},
+		{
+			Name:            "Parking not found",
+			Query:           "?from=1736330400&to=1736335800",
+			Parking:         &models.Parking{ID: 1},
+			GetParkingError: custErr.ErrParkingNotFound,
+			ResponseCode:    http.StatusNotFound,
+			ResponseBody:    test.NotFound,
+		},
+		{
+			Name:            "Error while getting from DB on Prod",
+			Query:           "?from=1736330400&to=1736335800",
+			Parking:         &models.Parking{ID: 1},
+			GetParkingError: xerrors.Errorf("db: error getting from DB"),
+			Environment:     test.EnvProd,
+			ResponseCode:    http.StatusInternalServerError,
+			ResponseBody:    test.InternalServerErrorMessage,
+		},
+	}
+
+	t.Parallel()
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			const userID = 0
+
+			parkingGetterMock := mocks.NewParkingGetter(t)
+			if tc.Parking != nil {
+				if tc.GetParkingError != nil {
+					parkingGetterMock.On("GetParkingByID", tc.Parking.ID, userID).
+						Return(nil, tc.GetParkingError).
+						Once()
+				} else {
+					parkingGetterMock.On("GetParkingByID", tc.Parking.ID, userID).
+						Return(tc.Parking, nil).
+						Once()
+				}
+			}
+
+			ctx := context.WithValue(context.Background(), authMiddleware.UserIDKey, userID)
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlParkingPrice, 1)+tc.Query, nil)
+
+			log := slogdiscard.NewDiscardLogger()
+			cfg := &config.Config{Environment: test.EnvLocal}
+			if tc.Environment != "" {
+				cfg.Environment = tc.Environment
+			}
+
+			router := chi.NewRouter()
+			router.Use(middleware.URLFormat)
+			router.Get("/parking/{id}/price", parking.PriceHandler(log, parkingGetterMock, cfg))
+
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+			require.Equal(t, tc.ResponseCode, rr.Code)
+
+			if tc.JSON {
+				assert.JSONEq(t, tc.ResponseBody, rr.Body.String())
+			} else {
+				assert.Equal(t, tc.ResponseBody, rr.Body.String())
+			}
+		})
+	}
+}
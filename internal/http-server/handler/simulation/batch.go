@@ -0,0 +1,64 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/PIRSON21/parking/internal/config"
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	customValidator "github.com/PIRSON21/parking/internal/lib/validator"
+	sim "github.com/PIRSON21/parking/internal/simulation"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/xerrors"
+)
+
+// BatchHandler прогоняет симуляцию без WebSocket-клиента и возвращает итоговую статистику.
+func BatchHandler(log *slog.Logger, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http-server.handler.simulation.BatchHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		defer r.Body.Close()
+
+		var params sim.BatchParams
+		if err := render.DecodeJSON(r.Body, &params); err != nil {
+			log.Error("error while decoding JSON", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(fmt.Sprintf("error while decoding JSON: %s", err.Error())))
+			return
+		}
+		log.Debug("batch params from request", slog.Any("params", params))
+
+		valid := customValidator.CreateSimulationValidator()
+		if err := valid.Struct(&params); err != nil {
+			var validErr validator.ValidationErrors
+			if errors.As(err, &validErr) {
+				log.Error("validation error", slog.String("err", err.Error()))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.RecursiveValidationError(validErr))
+				return
+			}
+			log.Error("error while validating batch params", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		report, err := sim.RunBatch(r.Context(), &params, log)
+		if err != nil {
+			log.Error("error while running batch simulation", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, xerrors.Errorf("%s: error while running simulation: %w", op, err))
+			return
+		}
+		log.Debug("batch simulation finished", slog.Int("arrivals", report.Arrivals), slog.String("revenue", report.Revenue.String()))
+
+		render.JSON(w, r, report)
+	}
+}
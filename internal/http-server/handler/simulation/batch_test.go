@@ -0,0 +1,490 @@
+package simulation_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	"github.com/PIRSON21/parking/internal/http-server/handler/simulation"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/money"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	sim "github.com/PIRSON21/parking/internal/simulation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const urlBatch = "/simulate/batch"
+
+// newBatchParams создает детерминированный сценарий: машина приезжает каждые 5 минут
+// и всегда заезжает на 12 минут.
+func newBatchParams(duration int) sim.BatchParams {
+	seed := uint64(42)
+
+	return sim.BatchParams{
+		InitParams: sim.InitParams{
+			Parking: &models.Parking{
+				Name:        "1: Центр",
+				Address:     "ул. Пушкина, д. Колотушкина",
+				Width:       4,
+				Height:      4,
+				DayTariff:   test.NewMoney(10000),
+				NightTariff: test.NewMoney(10000),
+				TimeZone:    "UTC",
+				Cells: [][]models.ParkingCell{
+					{".", "P", "P", "P"},
+					{".", ".", ".", "."},
+					{"D", "D", ".", "."},
+					{".", "I", "O", "."},
+				},
+			},
+			ArrivalConfig: &sim.ArrivalConfig{
+				Type:         "discrete",
+				DiscreteTime: 5,
+				ParkingProb:  1,
+			},
+			ParkingTimeConfig: &sim.ParkingTimeConfig{
+				Type:         "discrete",
+				DiscreteTime: 12,
+			},
+			StartTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC).Unix(),
+			Seed:      &seed,
+		},
+		Duration:       duration,
+		SampleInterval: 30,
+	}
+}
+
+// runBatch выполняет пакетный прогон с параметрами params и возвращает его отчет.
+func runBatch(t *testing.T, params sim.BatchParams) sim.Report {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, urlBatch, bytes.NewReader(test.MustMarshal(params)))
+	rr := httptest.NewRecorder()
+
+	simulation.BatchHandler(slogdiscard.NewDiscardLogger(), &config.Config{}).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var report sim.Report
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+
+	return report
+}
+
+func TestBatchHandler(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name             string
+		RequestBody      []byte
+		ResponseCode     int
+		ExpectedResponse string
+		ExpectedReport   *sim.Report
+	}{
+		{
+			Name:             "Wrong JSON format",
+			RequestBody:      []byte(`{"wrong":"json"`),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: fmt.Sprintf(test.ExpectedError, "error while decoding JSON: unexpected EOF"),
+		},
+		{
+			Name:             "No duration",
+			RequestBody:      test.MustMarshal(newBatchParams(0)),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"duration":"Не указано поле"}}`,
+		},
+		{
+			Name:             "Duration over max",
+			RequestBody:      test.MustMarshal(newBatchParams(50000)),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: fmt.Sprintf(`{"BatchParams":{"duration":%q}}`, fmt.Sprintf(test.Lte, 43200)),
+		},
+		{
+			Name: "Entrance weights do not match entrances",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.ArrivalConfig.EntranceWeights = []float64{1, 2}
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"InitParams":{"entrance_weights":"Количество значений должно быть равно 1"}}}`,
+		},
+		{
+			Name: "Negative discrete arrival time",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.ArrivalConfig.DiscreteTime = -1
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: fmt.Sprintf(`{"BatchParams":{"InitParams":{"arrival_config":{"discrete_time":%q}}}}`, fmt.Sprintf(test.Gte, 1)),
+		},
+		{
+			Name: "Discrete parking time under min",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.ParkingTimeConfig.DiscreteTime = 0.00001
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: fmt.Sprintf(`{"BatchParams":{"InitParams":{"parking_time_config":{"discrete_time":%q}}}}`, fmt.Sprintf(test.Gte, 1)),
+		},
+		{
+			Name: "Unknown strategy",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.Strategy = "closest"
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"InitParams":{"strategy":"Значение должно быть одним из: nearest-entrance nearest-exit random fill-far-first even-wear"}}}`,
+		},
+		{
+			Name: "Stats interval over max",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.StatsInterval = 2000
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: fmt.Sprintf(`{"BatchParams":{"InitParams":{"stats_interval":%q}}}`, fmt.Sprintf(test.Lte, 1440)),
+		},
+		{
+			Name: "Vehicle class without spots",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.VehicleClasses = []sim.VehicleClassConfig{
+					{Class: models.Car, Share: 0.8},
+					{Class: models.Truck, Share: 0.2},
+				}
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"InitParams":{"vehicle_classes":"На парковке нет мест для класса truck"}}}`,
+		},
+		{
+			Name: "EV share without charging config",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.ArrivalConfig.EVShare = 0.3
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"InitParams":{"charging_config":"Не указано поле"}}}`,
+		},
+		{
+			Name: "Overlapping arrival profile",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.ArrivalConfig = &sim.ArrivalConfig{
+					Type: "profile",
+					Profile: []sim.IntensityPeriod{
+						{FromHour: 7, ToHour: 10, Rate: 1},
+						{FromHour: 9, ToHour: 12, Rate: 0.5, Weekdays: []int{1, 2}},
+					},
+					ParkingProb: 1,
+				}
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"InitParams":{"arrival_config":{"profile":"Периоды не должны пересекаться"}}}}`,
+		},
+		{
+			Name: "Pricing thresholds not ascending",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.PricingPolicy = &sim.PricingPolicy{
+					Thresholds: []sim.SurgeThreshold{
+						{Occupancy: 0.8, Multiplier: 2},
+						{Occupancy: 0.5, Multiplier: 1.5},
+					},
+				}
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"InitParams":{"pricing_policy":{"thresholds":"Пороги должны идти по возрастанию загрузки, а множители - не убывать"}}}}`,
+		},
+		{
+			Name: "Pricing multiplier over max",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.PricingPolicy = &sim.PricingPolicy{
+					Thresholds: []sim.SurgeThreshold{{Occupancy: 0.8, Multiplier: 20}},
+				}
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: fmt.Sprintf(`{"BatchParams":{"InitParams":{"pricing_policy":{"thresholds[0]":{"multiplier":%q}}}}}`, fmt.Sprintf(test.Lte, 10)),
+		},
+		{
+			Name: "Not enough spots to reserve",
+			RequestBody: func() []byte {
+				params := newBatchParams(60)
+				params.Subscribers = &sim.SubscriberConfig{
+					Count:         4,
+					Reserved:      true,
+					ArrivalConfig: &sim.ArrivalConfig{Type: "discrete", DiscreteTime: 10, ParkingProb: 1},
+				}
+				return test.MustMarshal(params)
+			}(),
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"BatchParams":{"InitParams":{"subscribers":"На парковке только 3 обычных мест для закрепления за абонентами"}}}`,
+		},
+		{
+			Name:         "Success",
+			RequestBody:  test.MustMarshal(newBatchParams(60)),
+			ResponseCode: http.StatusOK,
+			ExpectedReport: &sim.Report{
+				StartTime:       start.Unix(),
+				EndTime:         start.Add(time.Hour).Unix(),
+				Seed:            42,
+				Strategy:        "nearest-entrance",
+				Capacity:        3,
+				Arrivals:        12,
+				Parked:          12,
+				DroveAway:       0,
+				Left:            9,
+				Revenue:         money.New(18000, money.DefaultCurrency),
+				ChargingRevenue: money.New(0, money.DefaultCurrency),
+				MeanDwell:       12,
+				DwellP50:        12,
+				DwellP90:        12,
+				DwellP95:        12,
+				RejectionRate:   0,
+				Utilisation:     123.0 / 180.0,
+				PeakOccupancy:   3,
+				Occupancy: []sim.OccupancySample{
+					{TimeStamp: start.Unix(), Occupied: 0},
+					{TimeStamp: start.Add(30 * time.Minute).Unix(), Occupied: 2},
+					{TimeStamp: start.Add(60 * time.Minute).Unix(), Occupied: 2},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, urlBatch, bytes.NewReader(tc.RequestBody))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			log := slogdiscard.NewDiscardLogger()
+			cfg := &config.Config{Environment: test.EnvLocal}
+
+			simulation.BatchHandler(log, cfg).ServeHTTP(rr, req)
+			require.Equal(t, tc.ResponseCode, rr.Code)
+
+			if tc.ExpectedReport == nil {
+				assert.JSONEq(t, tc.ExpectedResponse, rr.Body.String())
+				return
+			}
+
+			var report sim.Report
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+
+			assert.InDelta(t, tc.ExpectedReport.MeanDwell, report.MeanDwell, 0.001)
+			assert.InDelta(t, tc.ExpectedReport.Utilisation, report.Utilisation, 0.001)
+			report.MeanDwell = tc.ExpectedReport.MeanDwell
+			report.Utilisation = tc.ExpectedReport.Utilisation
+			assert.Equal(t, *tc.ExpectedReport, report)
+		})
+	}
+}
+
+func TestBatchHandlerSeed(t *testing.T) {
+	params := newBatchParams(24 * 60)
+	params.ArrivalConfig = &sim.ArrivalConfig{
+		Type:        "exponential",
+		Lambda:      0.5,
+		ParkingProb: 0.7,
+	}
+	params.ParkingTimeConfig = &sim.ParkingTimeConfig{
+		Type:        "uniform",
+		MinDuration: 5,
+		MaxDuration: 15,
+	}
+
+	first := runBatch(t, params)
+	assert.Equal(t, first, runBatch(t, params), "одинаковый seed должен давать одинаковый результат")
+
+	otherSeed := uint64(43)
+	params.Seed = &otherSeed
+	assert.NotEqual(t, first, runBatch(t, params), "разный seed должен давать разный результат")
+}
+
+// TestBatchHandlerCharging проверяет оплату зарядки: все машины - электромобили,
+// но станция одна, поэтому за час с нее уезжают три машины. За 12 минут стоянки
+// станция мощностью 30 кВт успевает отдать 6 кВт·ч из запрошенных 10.
+func TestBatchHandlerCharging(t *testing.T) {
+	params := newBatchParams(60)
+	params.Parking.Cells[0] = []models.ParkingCell{".", "E", "P", "P"}
+	params.Parking.EnergyTariff = test.NewMoney(1000)
+	params.ArrivalConfig.EVShare = 1
+	params.ChargingConfig = &sim.ChargingConfig{
+		Type:           "discrete",
+		DiscreteEnergy: 10,
+		Power:          30,
+	}
+
+	report := runBatch(t, params)
+
+	assert.Equal(t, 9, report.Left)
+	assert.InDelta(t, 18, report.Energy, 0.001)
+	assert.Equal(t, money.New(18000, money.DefaultCurrency), report.ChargingRevenue)
+	assert.Equal(t, money.New(18000+18000, money.DefaultCurrency), report.Revenue)
+}
+
+// TestBatchHandlerQueue проверяет очередь на въезд: машины стоят 20 минут, поэтому каждая
+// четвертая не находит места и встает в очередь, но ждет только 3 минуты из нужных 5.
+func TestBatchHandlerQueue(t *testing.T) {
+	params := newBatchParams(60)
+	params.ParkingTimeConfig.DiscreteTime = 20
+	params.SampleInterval = 21
+	params.QueueConfig = &sim.QueueConfig{
+		Capacity: 2,
+		Patience: &sim.ParkingTimeConfig{
+			Type:         "discrete",
+			DiscreteTime: 3,
+		},
+	}
+
+	report := runBatch(t, params)
+
+	assert.Equal(t, 12, report.Arrivals)
+	assert.Equal(t, 9, report.Parked)
+	assert.Equal(t, 3, report.Queued)
+	assert.Equal(t, 2, report.Reneged)
+	assert.Equal(t, 1, report.PeakQueue)
+	assert.Equal(t, 6, report.Left)
+	assert.InDelta(t, 2.0/12.0, report.RejectionRate, 0.001)
+	start := time.Unix(params.StartTime, 0)
+	assert.Equal(t, []sim.OccupancySample{
+		{TimeStamp: start.Unix(), Occupied: 0, Queued: 0},
+		{TimeStamp: start.Add(21 * time.Minute).Unix(), Occupied: 3, Queued: 1},
+		{TimeStamp: start.Add(42 * time.Minute).Unix(), Occupied: 3, Queued: 1},
+	}, report.Occupancy)
+}
+
+// TestBatchHandlerProfile проверяет профиль интенсивности: машины приезжают только в первый час прогона,
+// поэтому через 12 минут после него парковка пустеет и до конца прогона остается пустой.
+func TestBatchHandlerProfile(t *testing.T) {
+	params := newBatchParams(180)
+	start := time.Unix(params.StartTime, 0)
+
+	// модельные часы идут в часовом поясе парковки: 10:00 UTC во Владивостоке - 20:00
+	params.Parking.TimeZone = "Asia/Vladivostok"
+	hour := 20
+	params.ArrivalConfig = &sim.ArrivalConfig{
+		Type: "profile",
+		Profile: []sim.IntensityPeriod{
+			{FromHour: hour, ToHour: hour + 1, Rate: 0.2},
+		},
+		ParkingProb: 1,
+	}
+	params.Parking.Cells[0] = []models.ParkingCell{"P", "P", "P", "P"}
+	params.Parking.Cells[1] = []models.ParkingCell{".", ".", ".", "P"}
+	params.ParkingTimeConfig.DiscreteTime = 12
+
+	report := runBatch(t, params)
+
+	assert.Positive(t, report.Arrivals)
+	assert.Equal(t, report.Arrivals, report.Left+report.DroveAway)
+
+	for _, sample := range report.Occupancy {
+		if sample.TimeStamp >= start.Add(72*time.Minute).Unix() {
+			assert.Zero(t, sample.Occupied)
+		}
+	}
+}
+
+// TestBatchHandlerSurge проверяет динамическую тарификацию: машины приезжают каждые 5 минут
+// и стоят 12 минут, поэтому с 15-й минуты загрузка колеблется между двумя и тремя местами из трех.
+// Каждая машина, кроме первой, заезжает при двух занятых местах и платит обычную ставку,
+// а первая заезжает на пустую парковку со скидкой 50%. Ставка меняется при заезде первой машины,
+// в начале прогона и при каждом заезде и выезде начиная с 15-й минуты.
+func TestBatchHandlerSurge(t *testing.T) {
+	params := newBatchParams(60)
+	params.PricingPolicy = &sim.PricingPolicy{
+		Thresholds:      []sim.SurgeThreshold{{Occupancy: 1, Multiplier: 2}},
+		EmptyMultiplier: 0.5,
+	}
+
+	report := runBatch(t, params)
+
+	assert.Equal(t, 12, report.Parked)
+	assert.Equal(t, 9, report.Left)
+	assert.Equal(t, 2+10+9, report.RateChanges)
+	assert.Equal(t, money.New(17000, money.DefaultCurrency), report.Revenue)
+	assert.Equal(t, money.NewPtr(-1000, money.DefaultCurrency), report.Surcharge)
+}
+
+// TestBatchHandlerSurgeElasticity проверяет эластичность спроса: пока на парковке стоит хотя бы
+// одна машина, ставка утроена, и почти все водители проезжают мимо.
+func TestBatchHandlerSurgeElasticity(t *testing.T) {
+	params := newBatchParams(60)
+	params.PricingPolicy = &sim.PricingPolicy{
+		Thresholds: []sim.SurgeThreshold{{Occupancy: 0.3, Multiplier: 3}},
+		Elasticity: 5,
+	}
+
+	report := runBatch(t, params)
+
+	assert.Equal(t, 12, report.Arrivals)
+	assert.Positive(t, report.DroveAway)
+	assert.Less(t, report.PeakOccupancy, 3)
+}
+
+// TestBatchHandlerSubscribers проверяет абонента с закрепленным местом: он приезжает сразу после отъезда,
+// не платит за стоянку, а разовым посетителям остаются два места.
+func TestBatchHandlerSubscribers(t *testing.T) {
+	params := newBatchParams(60)
+	params.Subscribers = &sim.SubscriberConfig{
+		Count:             1,
+		Reserved:          true,
+		ArrivalConfig:     &sim.ArrivalConfig{Type: "discrete", DiscreteTime: 1, ParkingProb: 1},
+		ParkingTimeConfig: &sim.ParkingTimeConfig{Type: "discrete", DiscreteTime: 30},
+	}
+
+	report := runBatch(t, params)
+
+	assert.Equal(t, 1, report.ReservedSpots)
+	assert.Equal(t, 14, report.Arrivals)
+	assert.Equal(t, 4, report.DroveAway)
+	assert.Equal(t, money.New(12000, money.DefaultCurrency), report.Revenue)
+	assert.InDelta(t, 146.0/180.0, report.Utilisation, 0.001)
+
+	require.NotNil(t, report.Subscribers)
+	assert.InDelta(t, 59.0/180.0, report.Subscribers.Utilisation, 0.001)
+	report.Subscribers.Utilisation = 0
+	assert.Equal(t, sim.SegmentStats{
+		Arrivals:      2,
+		Parked:        2,
+		Left:          1,
+		Revenue:       money.New(0, money.DefaultCurrency),
+		Occupied:      1,
+		PeakOccupancy: 1,
+	}, *report.Subscribers)
+
+	require.NotNil(t, report.WalkIns)
+	assert.InDelta(t, 87.0/180.0, report.WalkIns.Utilisation, 0.001)
+	report.WalkIns.Utilisation = 0
+	assert.Equal(t, sim.SegmentStats{
+		Arrivals:      12,
+		Parked:        8,
+		Left:          6,
+		Revenue:       money.New(12000, money.DefaultCurrency),
+		Occupied:      2,
+		PeakOccupancy: 2,
+	}, *report.WalkIns)
+
+	subscribers := 1
+	assert.Equal(t, &subscribers, report.Occupancy[1].Subscribers)
+}
@@ -0,0 +1,181 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	customValidator "github.com/PIRSON21/parking/internal/lib/validator"
+	sim "github.com/PIRSON21/parking/internal/simulation"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/xerrors"
+)
+
+// maxSampleInterval - максимальный шаг замеров загрузки в выгрузке (в минутах).
+const maxSampleInterval = 1440
+
+// BatchExportHandler прогоняет симуляцию без WebSocket-клиента и по ходу прогона отдает
+// события машин и замеры загрузки файлом в формате из параметра ?format= ("csv" по умолчанию или "ndjson").
+func BatchExportHandler(log *slog.Logger, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http-server.handler.simulation.BatchExportHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		defer r.Body.Close()
+
+		var params sim.BatchParams
+		if err := render.DecodeJSON(r.Body, &params); err != nil {
+			log.Error("error while decoding JSON", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(fmt.Sprintf("error while decoding JSON: %s", err.Error())))
+			return
+		}
+		log.Debug("batch params from request", slog.Any("params", params))
+
+		valid := customValidator.CreateSimulationValidator()
+		if err := valid.Struct(&params); err != nil {
+			var validErr validator.ValidationErrors
+			if errors.As(err, &validErr) {
+				log.Error("validation error", slog.String("err", err.Error()))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.RecursiveValidationError(validErr))
+				return
+			}
+			log.Error("error while validating batch params", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		exporter, ok := newExporter(w, r, log, "simulation")
+		if !ok {
+			return
+		}
+
+		// заголовки уже отправлены, поэтому об ошибке можно только записать в лог
+		if err := sim.StreamBatch(r.Context(), &params, exporter, log); err != nil {
+			log.Error("error while streaming batch simulation", slog.String("err", err.Error()))
+			return
+		}
+		log.Debug("batch simulation exported")
+	}
+}
+
+// RunExportHandler отдает события сохраненного прогона симуляции и замеры загрузки, восстановленные по ним,
+// файлом в формате из параметра ?format= ("csv" по умолчанию или "ndjson").
+// Шаг замеров в минутах задается параметром ?sample_interval= (по умолчанию 15 минут).
+func RunExportHandler(log *slog.Logger, runGetter RunGetter, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http-server.handler.simulation.RunExportHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		runID, err := getURLID(r)
+		if err != nil {
+			log.Error("error while getting ID from url", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(err.Error()))
+			return
+		}
+
+		sampleInterval, err := getSampleInterval(r)
+		if err != nil {
+			log.Error("error while getting sample interval", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(err.Error()))
+			return
+		}
+
+		userID, ok := r.Context().Value(authMiddleware.UserIDKey).(int)
+		if !ok {
+			log.Error("error while getting userID", slog.Any("userID", r.Context().Value(authMiddleware.UserIDKey)))
+			resp.ErrorHandler(w, r, cfg, xerrors.Errorf("%s: error while getting userID", op))
+			return
+		}
+
+		run, err := runGetter.GetSimulationRun(runID, userID)
+		if err != nil {
+			if errors.Is(err, custErr.ErrSimulationRunNotFound) {
+				log.Debug("simulation run not found", slog.Int("runID", runID), slog.Int("userID", userID))
+				http.NotFound(w, r)
+				return
+			}
+
+			log.Error("error while getting simulation run from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		events, err := runGetter.GetSimulationEvents(runID)
+		if err != nil {
+			log.Error("error while getting simulation events from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		exporter, ok := newExporter(w, r, log, fmt.Sprintf("run-%d", runID))
+		if !ok {
+			return
+		}
+
+		if err = sim.ExportRun(exporter, run, events, sampleInterval); err != nil {
+			log.Error("error while exporting simulation run", slog.String("err", err.Error()))
+			return
+		}
+		log.Debug("simulation run exported", slog.Int("runID", runID), slog.Int("events", len(events)))
+	}
+}
+
+// newExporter создает выгрузку в формате из параметра ?format= и отправляет заголовки файла name.
+// Если формат не поддерживается, отвечает клиенту ошибкой и возвращает false.
+func newExporter(w http.ResponseWriter, r *http.Request, log *slog.Logger, name string) (*sim.Exporter, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = sim.ExportCSV
+	}
+
+	exporter, err := sim.NewExporter(w, format)
+	if err != nil {
+		log.Error("error while creating exporter", slog.String("err", err.Error()))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.UnknownError(fmt.Sprintf("%s: %s", custErr.ErrInvalidExportFormat.Error(), format)))
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", exporter.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	w.WriteHeader(http.StatusOK)
+
+	return exporter, true
+}
+
+// getSampleInterval получает шаг замеров загрузки из параметра ?sample_interval= и проверяет его.
+// Если параметр не указан, вернет 0.
+func getSampleInterval(r *http.Request) (time.Duration, error) {
+	str := r.URL.Query().Get("sample_interval")
+	if str == "" {
+		return 0, nil
+	}
+
+	minutes, err := strconv.Atoi(str)
+	if err != nil || minutes < 1 || minutes > maxSampleInterval {
+		return 0, xerrors.Errorf("шаг замеров должен быть числом от 1 до %d", maxSampleInterval)
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
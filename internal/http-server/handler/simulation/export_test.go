@@ -0,0 +1,240 @@
+package simulation_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	"github.com/PIRSON21/parking/internal/http-server/handler/simulation"
+	"github.com/PIRSON21/parking/internal/http-server/handler/simulation/mocks"
+	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	urlBatchExport = "/simulate/batch/export"
+	urlRunExport   = "/simulate/runs/%v/export"
+)
+
+// newExportedRun создает прогон, начатый в 10:00, в котором одна машина стоит с 10:05 до 10:17.
+func newExportedRun() (*models.SimulationRun, []models.SimulationEvent) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC).Unix()
+
+	run := newRun(1, 3)
+	run.Params = json.RawMessage(fmt.Sprintf(`{"start_time":%d}`, start))
+	run.Summary = json.RawMessage(fmt.Sprintf(`{"event":"summary","timestamp":%d}`, start+30*60))
+
+	events := []models.SimulationEvent{
+		{Seq: 1, Type: "arrive", TimeStamp: start + 5*60, CarID: "car-1",
+			Payload: json.RawMessage(fmt.Sprintf(`{"event":"arrive","car_id":"car-1","timestamp":%d}`, start+5*60))},
+		{Seq: 2, Type: "park", TimeStamp: start + 5*60, CarID: "car-1",
+			Payload: json.RawMessage(fmt.Sprintf(`{"event":"park","car_id":"car-1","timestamp":%d,"park_x":1,"park_y":2}`, start+5*60))},
+		{Seq: 3, Type: "leave", TimeStamp: start + 17*60, CarID: "car-1",
+			Payload: json.RawMessage(fmt.Sprintf(`{"event":"leave","car_id":"car-1","timestamp":%d,"park_x":1,"park_y":2,"price":20.5}`, start+17*60))},
+	}
+
+	return run, events
+}
+
+func TestRunExportHandler(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC).Unix()
+
+	cases := []struct {
+		Name string
+		// Query - параметры запроса
+		Query string
+		// Load - ожидается загрузка прогона из БД
+		Load        bool
+		GetRunError error
+		// ResponseCode - ожидаемый код ответа
+		ResponseCode int
+		ContentType  string
+		ResponseBody string
+		JSON         bool
+	}{
+		{
+			Name:         "Success CSV",
+			Query:        "",
+			Load:         true,
+			ResponseCode: http.StatusOK,
+			ContentType:  "text/csv; charset=utf-8",
+			ResponseBody: "record,event,car_id,timestamp,park_x,park_y,price,currency,occupied,queued,multiplier\n" +
+				fmt.Sprintf("occupancy,,,%d,,,,,0,0,\n", start) +
+				fmt.Sprintf("event,arrive,car-1,%d,,,,,,,\n", start+5*60) +
+				fmt.Sprintf("event,park,car-1,%d,1,2,,,,,\n", start+5*60) +
+				fmt.Sprintf("occupancy,,,%d,,,,,1,0,\n", start+15*60) +
+				fmt.Sprintf("event,leave,car-1,%d,1,2,20.50,RUB,,,\n", start+17*60) +
+				fmt.Sprintf("occupancy,,,%d,,,,,0,0,\n", start+30*60),
+		},
+		{
+			Name:         "Success NDJSON with sample interval",
+			Query:        "?format=ndjson&sample_interval=10",
+			Load:         true,
+			ResponseCode: http.StatusOK,
+			ContentType:  "application/x-ndjson",
+			ResponseBody: fmt.Sprintf(`{"record":"occupancy","timestamp":%d,"occupied":0,"queued":0}`+"\n", start) +
+				fmt.Sprintf(`{"record":"event","event":"arrive","car_id":"car-1","timestamp":%d}`+"\n", start+5*60) +
+				fmt.Sprintf(`{"record":"event","event":"park","car_id":"car-1","timestamp":%d,"park_x":1,"park_y":2}`+"\n", start+5*60) +
+				fmt.Sprintf(`{"record":"occupancy","timestamp":%d,"occupied":1,"queued":0}`+"\n", start+10*60) +
+				fmt.Sprintf(`{"record":"event","event":"leave","car_id":"car-1","timestamp":%d,"park_x":1,"park_y":2,"price":{"amount":2050,"currency":"RUB"}}`+"\n", start+17*60) +
+				fmt.Sprintf(`{"record":"occupancy","timestamp":%d,"occupied":0,"queued":0}`+"\n", start+20*60) +
+				fmt.Sprintf(`{"record":"occupancy","timestamp":%d,"occupied":0,"queued":0}`+"\n", start+30*60),
+		},
+		{
+			Name:         "Unknown format",
+			Query:        "?format=xml",
+			Load:         true,
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, "неподдерживаемый формат выгрузки: xml"),
+			JSON:         true,
+		},
+		{
+			Name:         "Bad sample interval",
+			Query:        "?sample_interval=0",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, "шаг замеров должен быть числом от 1 до 1440"),
+			JSON:         true,
+		},
+		{
+			Name:         "Not found",
+			Load:         true,
+			GetRunError:  custErr.ErrSimulationRunNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: test.NotFound,
+		},
+	}
+
+	t.Parallel()
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			const (
+				userID = 1
+				runID  = 1
+			)
+
+			run, events := newExportedRun()
+
+			runGetterMock := mocks.NewRunGetter(t)
+			if tc.Load {
+				if tc.GetRunError != nil {
+					runGetterMock.On("GetSimulationRun", runID, userID).
+						Return(nil, tc.GetRunError).
+						Once()
+				} else {
+					runGetterMock.On("GetSimulationRun", runID, userID).
+						Return(run, nil).
+						Once()
+					runGetterMock.On("GetSimulationEvents", runID).
+						Return(events, nil).
+						Once()
+				}
+			}
+
+			ctx := context.WithValue(context.Background(), authMiddleware.UserIDKey, userID)
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlRunExport, runID)+tc.Query, nil)
+
+			log := slogdiscard.NewDiscardLogger()
+			cfg := &config.Config{Environment: test.EnvLocal}
+
+			router := chi.NewRouter()
+			router.Use(middleware.URLFormat)
+			router.Get("/simulate/runs/{id}/export", simulation.RunExportHandler(log, runGetterMock, cfg))
+
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+			require.Equal(t, tc.ResponseCode, rr.Code)
+
+			if tc.ContentType != "" {
+				assert.Equal(t, tc.ContentType, rr.Header().Get("Content-Type"))
+			}
+
+			if tc.JSON {
+				assert.JSONEq(t, tc.ResponseBody, rr.Body.String())
+			} else {
+				assert.Equal(t, tc.ResponseBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestBatchExportHandler(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC).Unix()
+
+	cases := []struct {
+		Name         string
+		Query        string
+		RequestBody  []byte
+		ResponseCode int
+		ContentType  string
+		ResponseBody string
+		JSON         bool
+	}{
+		{
+			Name:         "Success CSV",
+			RequestBody:  test.MustMarshal(newBatchParams(10)),
+			ResponseCode: http.StatusOK,
+			ContentType:  "text/csv; charset=utf-8",
+			ResponseBody: "record,event,car_id,timestamp,park_x,park_y,price,currency,occupied,queued,multiplier\n" +
+				fmt.Sprintf("occupancy,,,%d,,,,,0,0,\n", start) +
+				fmt.Sprintf("event,arrive,ad3032f7-0952-41da-a654-b3a91449112b,%d,,,,,,,\n", start+5*60) +
+				fmt.Sprintf("event,park,ad3032f7-0952-41da-a654-b3a91449112b,%d,0,2,,,,,\n", start+5*60) +
+				fmt.Sprintf("event,arrive,a8bc5965-6778-487f-b2ff-68e7f5d3d54a,%d,,,,,,,\n", start+10*60) +
+				fmt.Sprintf("event,park,a8bc5965-6778-487f-b2ff-68e7f5d3d54a,%d,0,1,,,,,\n", start+10*60),
+		},
+		{
+			Name:         "Unknown format",
+			Query:        "?format=xlsx",
+			RequestBody:  test.MustMarshal(newBatchParams(10)),
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, "неподдерживаемый формат выгрузки: xlsx"),
+			JSON:         true,
+		},
+		{
+			Name:         "No duration",
+			RequestBody:  test.MustMarshal(newBatchParams(0)),
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: `{"BatchParams":{"duration":"Не указано поле"}}`,
+			JSON:         true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, urlBatchExport+tc.Query, bytes.NewReader(tc.RequestBody))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			log := slogdiscard.NewDiscardLogger()
+			cfg := &config.Config{Environment: test.EnvLocal}
+
+			simulation.BatchExportHandler(log, cfg).ServeHTTP(rr, req)
+			require.Equal(t, tc.ResponseCode, rr.Code)
+
+			if tc.ContentType != "" {
+				assert.Equal(t, tc.ContentType, rr.Header().Get("Content-Type"))
+			}
+
+			if tc.JSON {
+				assert.JSONEq(t, tc.ResponseBody, rr.Body.String())
+			} else {
+				assert.Equal(t, tc.ResponseBody, rr.Body.String())
+			}
+		})
+	}
+}
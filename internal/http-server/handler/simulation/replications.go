@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/PIRSON21/parking/internal/config"
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	customValidator "github.com/PIRSON21/parking/internal/lib/validator"
+	sim "github.com/PIRSON21/parking/internal/simulation"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/xerrors"
+)
+
+// ReplicationsHandler выполняет серию независимых прогонов сценария
+// и возвращает оценки показателей с доверительными интервалами.
+func ReplicationsHandler(log *slog.Logger, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http-server.handler.simulation.ReplicationsHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		defer r.Body.Close()
+
+		var params sim.ReplicationParams
+		if err := render.DecodeJSON(r.Body, &params); err != nil {
+			log.Error("error while decoding JSON", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(fmt.Sprintf("error while decoding JSON: %s", err.Error())))
+			return
+		}
+		log.Debug("replication params from request", slog.Int("replications", params.Replications))
+
+		valid := customValidator.CreateSimulationValidator()
+		if err := valid.Struct(&params); err != nil {
+			var validErr validator.ValidationErrors
+			if errors.As(err, &validErr) {
+				log.Error("validation error", slog.String("err", err.Error()))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.RecursiveValidationError(validErr))
+				return
+			}
+			log.Error("error while validating replication params", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		report, err := sim.RunReplications(r.Context(), &params, log)
+		if err != nil {
+			log.Error("error while running replications", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, xerrors.Errorf("%s: error while running replications: %w", op, err))
+			return
+		}
+		log.Debug("replications finished", slog.Int("replications", report.Replications))
+
+		render.JSON(w, r, report)
+	}
+}
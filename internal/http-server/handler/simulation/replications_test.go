@@ -0,0 +1,102 @@
+package simulation_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PIRSON21/parking/internal/config"
+	"github.com/PIRSON21/parking/internal/http-server/handler/simulation"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	sim "github.com/PIRSON21/parking/internal/simulation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const urlReplications = "/simulate/replications"
+
+func TestReplicationsHandler(t *testing.T) {
+	cases := []struct {
+		Name             string
+		Params           sim.ReplicationParams
+		ResponseCode     int
+		ExpectedResponse string
+	}{
+		{
+			Name:             "No replications",
+			Params:           sim.ReplicationParams{BatchParams: newBatchParams(60)},
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"ReplicationParams":{"replications":"Не указано поле"}}`,
+		},
+		{
+			Name:             "Too many replications",
+			Params:           sim.ReplicationParams{BatchParams: newBatchParams(60), Replications: 1001},
+			ResponseCode:     http.StatusBadRequest,
+			ExpectedResponse: `{"ReplicationParams":{"replications":"Значение не может быть больше 1000"}}`,
+		},
+		{
+			Name:         "Deterministic scenario",
+			Params:       sim.ReplicationParams{BatchParams: newBatchParams(60), Replications: 5},
+			ResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, urlReplications, bytes.NewReader(test.MustMarshal(tc.Params)))
+			rr := httptest.NewRecorder()
+
+			simulation.ReplicationsHandler(slogdiscard.NewDiscardLogger(), &config.Config{Environment: test.EnvLocal}).ServeHTTP(rr, req)
+			require.Equal(t, tc.ResponseCode, rr.Code)
+
+			if tc.ExpectedResponse != "" {
+				assert.JSONEq(t, tc.ExpectedResponse, rr.Body.String())
+				return
+			}
+
+			var report sim.ReplicationReport
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+
+			// в детерминированном сценарии все прогоны одинаковы, разброса нет
+			assert.Equal(t, 5, report.Replications)
+			assert.Len(t, report.Seeds, 5)
+			assert.Equal(t, uint64(42), report.Seed)
+			assert.InDelta(t, 180, report.Revenue.Mean, 0.001)
+			assert.InDelta(t, 0, report.Revenue.StdDev, 0.001)
+			assert.InDelta(t, report.Revenue.Mean, report.Revenue.CILow, 0.001)
+			assert.InDelta(t, report.Revenue.Mean, report.Revenue.CIHigh, 0.001)
+			assert.InDelta(t, 0, report.RejectionRate.Mean, 0.001)
+			assert.InDelta(t, 12, report.MeanDwell.Mean, 0.001)
+			assert.InDelta(t, 123.0/180.0, report.Utilisation.Mean, 0.001)
+		})
+	}
+}
+
+func TestReplicationsHandlerConfidenceInterval(t *testing.T) {
+	params := sim.ReplicationParams{BatchParams: newBatchParams(8 * 60), Replications: 20}
+	params.ArrivalConfig = &sim.ArrivalConfig{Type: "exponential", Lambda: 0.5, ParkingProb: 0.8}
+	params.ParkingTimeConfig = &sim.ParkingTimeConfig{Type: "uniform", MinDuration: 5, MaxDuration: 15}
+
+	req := httptest.NewRequest(http.MethodPost, urlReplications, bytes.NewReader(test.MustMarshal(params)))
+	rr := httptest.NewRecorder()
+
+	simulation.ReplicationsHandler(slogdiscard.NewDiscardLogger(), &config.Config{}).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var report sim.ReplicationReport
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+
+	for name, e := range map[string]sim.Estimate{
+		"revenue":        report.Revenue,
+		"rejection_rate": report.RejectionRate,
+		"utilisation":    report.Utilisation,
+		"mean_dwell":     report.MeanDwell,
+	} {
+		assert.Greater(t, e.StdDev, 0.0, name)
+		assert.Less(t, e.CILow, e.Mean, name)
+		assert.Greater(t, e.CIHigh, e.Mean, name)
+	}
+}
@@ -0,0 +1,138 @@
+package simulation
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/PIRSON21/parking/internal/config"
+	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"golang.org/x/xerrors"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name=RunGetter
+type RunGetter interface {
+	GetSimulationRuns(int, int) ([]*models.SimulationRun, error)
+	GetSimulationRun(int, int) (*models.SimulationRun, error)
+	GetSimulationEvents(int) ([]models.SimulationEvent, error)
+}
+
+// RunDetails - прогон симуляции вместе с его событиями.
+type RunDetails struct {
+	*models.SimulationRun
+	Events []models.SimulationEvent `json:"events"`
+}
+
+// RunsHandler выдает менеджеру его сохраненные прогоны симуляции парковки.
+func RunsHandler(log *slog.Logger, runGetter RunGetter, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http-server.handler.simulation.RunsHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		parkingID, err := getURLID(r)
+		if err != nil {
+			log.Error("error while getting ID from url", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(err.Error()))
+			return
+		}
+
+		userID, ok := r.Context().Value(authMiddleware.UserIDKey).(int)
+		if !ok {
+			log.Error("error while getting userID", slog.Any("userID", r.Context().Value(authMiddleware.UserIDKey)))
+			resp.ErrorHandler(w, r, cfg, xerrors.Errorf("%s: error while getting userID", op))
+			return
+		}
+
+		runs, err := runGetter.GetSimulationRuns(parkingID, userID)
+		if err != nil {
+			log.Error("error while getting simulation runs from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		log.Debug("found simulation runs", slog.Int("parkingID", parkingID), slog.Int("count", len(runs)))
+		if len(runs) == 0 {
+			render.JSON(w, r, []string{})
+			return
+		}
+
+		render.JSON(w, r, runs)
+	}
+}
+
+// RunHandler выдает менеджеру сохраненный прогон симуляции вместе с событиями.
+func RunHandler(log *slog.Logger, runGetter RunGetter, cfg *config.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http-server.handler.simulation.RunHandler"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		runID, err := getURLID(r)
+		if err != nil {
+			log.Error("error while getting ID from url", slog.String("err", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, resp.UnknownError(err.Error()))
+			return
+		}
+
+		userID, ok := r.Context().Value(authMiddleware.UserIDKey).(int)
+		if !ok {
+			log.Error("error while getting userID", slog.Any("userID", r.Context().Value(authMiddleware.UserIDKey)))
+			resp.ErrorHandler(w, r, cfg, xerrors.Errorf("%s: error while getting userID", op))
+			return
+		}
+
+		run, err := runGetter.GetSimulationRun(runID, userID)
+		if err != nil {
+			if errors.Is(err, custErr.ErrSimulationRunNotFound) {
+				log.Debug("simulation run not found", slog.Int("runID", runID), slog.Int("userID", userID))
+				http.NotFound(w, r)
+				return
+			}
+
+			log.Error("error while getting simulation run from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		events, err := runGetter.GetSimulationEvents(runID)
+		if err != nil {
+			log.Error("error while getting simulation events from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		log.Debug("simulation run found", slog.Int("runID", run.ID), slog.Int("events", len(events)))
+		render.JSON(w, r, RunDetails{SimulationRun: run, Events: events})
+	}
+}
+
+// getURLID получает id из url и проверяет его.
+func getURLID(r *http.Request) (int, error) {
+	idStr := chi.URLParam(r, "id")
+	if idStr == "" {
+		return 0, xerrors.Errorf("не указан id")
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, xerrors.Errorf("id %v не может быть преобразовано в число", idStr)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,258 @@
+package simulation_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	"github.com/PIRSON21/parking/internal/http-server/handler/simulation"
+	"github.com/PIRSON21/parking/internal/http-server/handler/simulation/mocks"
+	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+const (
+	urlParkingRuns = "/parking/%v/runs"
+	urlRun         = "/simulate/runs/%v"
+)
+
+// newRun создает сохраненный прогон симуляции парковки parkingID.
+func newRun(runID, parkingID int) *models.SimulationRun {
+	finished := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	return &models.SimulationRun{
+		ID:         runID,
+		ParkingID:  &parkingID,
+		ManagerID:  1,
+		Seed:       42,
+		Strategy:   "nearest",
+		Params:     json.RawMessage(`{"seed":42}`),
+		Summary:    json.RawMessage(`{"event":"summary","arrivals":2}`),
+		StartedAt:  time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+		FinishedAt: &finished,
+	}
+}
+
+func TestRunsHandler(t *testing.T) {
+	cases := []struct {
+		// Name - название теста
+		Name string
+		// ParkingID - id парковки из url. Если nil, в url передается BadID
+		ParkingID *int
+		// BadID - некорректный id парковки в url
+		BadID string
+		// Runs - прогоны, которые вернет БД
+		Runs []*models.SimulationRun
+		// GetRunsError - ошибка от метода GetSimulationRuns. Может быть nil
+		GetRunsError error
+		// Environment - значение из cfg
+		Environment  string
+		ResponseCode int
+		ResponseBody string
+		JSON         bool
+	}{
+		{
+			Name:         "Success",
+			ParkingID:    test.NewInt(3),
+			Runs:         []*models.SimulationRun{newRun(2, 3), newRun(1, 3)},
+			ResponseCode: http.StatusOK,
+			ResponseBody: test.MustMarshalResponse([]*models.SimulationRun{newRun(2, 3), newRun(1, 3)}),
+			JSON:         true,
+		},
+		{
+			Name:         "Success without runs",
+			ParkingID:    test.NewInt(3),
+			Runs:         nil,
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[]`,
+			JSON:         true,
+		},
+		{
+			Name:         "Bad id",
+			BadID:        "abc",
+			ResponseCode: http.StatusBadRequest,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, "id abc не может быть преобразовано в число"),
+			JSON:         true,
+		},
+		{
+			Name:         "Error while getting from DB on Dev",
+			ParkingID:    test.NewInt(3),
+			GetRunsError: xerrors.Errorf("db: error getting from DB"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, "db: error getting from DB"),
+			JSON:         true,
+		},
+		{
+			Name:         "Error while getting from DB on Prod",
+			ParkingID:    test.NewInt(3),
+			GetRunsError: xerrors.Errorf("db: error getting from DB"),
+			Environment:  test.EnvProd,
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: test.InternalServerErrorMessage,
+			JSON:         false,
+		},
+	}
+
+	t.Parallel()
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			const userID = 1
+
+			runGetterMock := mocks.NewRunGetter(t)
+
+			requestURL := fmt.Sprintf(urlParkingRuns, tc.BadID)
+			if tc.ParkingID != nil {
+				runGetterMock.On("GetSimulationRuns", *tc.ParkingID, userID).
+					Return(tc.Runs, tc.GetRunsError).
+					Once()
+				requestURL = fmt.Sprintf(urlParkingRuns, *tc.ParkingID)
+			}
+
+			ctx := context.WithValue(context.Background(), authMiddleware.UserIDKey, userID)
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+
+			log := slogdiscard.NewDiscardLogger()
+			cfg := &config.Config{Environment: test.EnvLocal}
+			if tc.Environment != "" {
+				cfg.Environment = tc.Environment
+			}
+
+			router := chi.NewRouter()
+			router.Use(middleware.URLFormat)
+			router.Get("/parking/{id}/runs", simulation.RunsHandler(log, runGetterMock, cfg))
+
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+			require.Equal(t, tc.ResponseCode, rr.Code)
+
+			if tc.JSON {
+				assert.JSONEq(t, tc.ResponseBody, rr.Body.String())
+			} else {
+				assert.Equal(t, tc.ResponseBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestRunHandler(t *testing.T) {
+	events := []models.SimulationEvent{
+		{
+			Seq:       1,
+			Type:      "arrive",
+			TimeStamp: 1735725600,
+			CarID:     "car-1",
+			Payload:   json.RawMessage(`{"event":"arrive","car_id":"car-1","timestamp":1735725600}`),
+		},
+	}
+
+	cases := []struct {
+		// Name - название теста
+		Name string
+		// Run - прогон, который вернет БД. Может быть nil
+		Run *models.SimulationRun
+		// GetRunError - ошибка от метода GetSimulationRun. Может быть nil
+		GetRunError error
+		// Events - события прогона. Если nil, события не запрашиваются
+		Events []models.SimulationEvent
+		// GetEventsError - ошибка от метода GetSimulationEvents. Может быть nil
+		GetEventsError error
+		// Environment - значение из cfg
+		Environment  string
+		ResponseCode int
+		ResponseBody string
+		JSON         bool
+	}{
+		{
+			Name:         "Success",
+			Run:          newRun(1, 3),
+			Events:       events,
+			ResponseCode: http.StatusOK,
+			ResponseBody: test.MustMarshalResponse(simulation.RunDetails{SimulationRun: newRun(1, 3), Events: events}),
+			JSON:         true,
+		},
+		{
+			Name:         "Not found",
+			GetRunError:  custErr.ErrSimulationRunNotFound,
+			ResponseCode: http.StatusNotFound,
+			ResponseBody: test.NotFound,
+			JSON:         false,
+		},
+		{
+			Name:         "Error while getting run from DB on Dev",
+			GetRunError:  xerrors.Errorf("db: error getting from DB"),
+			ResponseCode: http.StatusInternalServerError,
+			ResponseBody: fmt.Sprintf(test.ExpectedError, "db: error getting from DB"),
+			JSON:         true,
+		},
+		{
+			Name:           "Error while getting events from DB on Prod",
+			Run:            newRun(1, 3),
+			Events:         []models.SimulationEvent{},
+			GetEventsError: xerrors.Errorf("db: error getting from DB"),
+			Environment:    test.EnvProd,
+			ResponseCode:   http.StatusInternalServerError,
+			ResponseBody:   test.InternalServerErrorMessage,
+			JSON:           false,
+		},
+	}
+
+	t.Parallel()
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			const (
+				userID = 1
+				runID  = 1
+			)
+
+			runGetterMock := mocks.NewRunGetter(t)
+			runGetterMock.On("GetSimulationRun", runID, userID).
+				Return(tc.Run, tc.GetRunError).
+				Once()
+			if tc.Events != nil {
+				runGetterMock.On("GetSimulationEvents", runID).
+					Return(tc.Events, tc.GetEventsError).
+					Once()
+			}
+
+			ctx := context.WithValue(context.Background(), authMiddleware.UserIDKey, userID)
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(urlRun, runID), nil)
+
+			log := slogdiscard.NewDiscardLogger()
+			cfg := &config.Config{Environment: test.EnvLocal}
+			if tc.Environment != "" {
+				cfg.Environment = tc.Environment
+			}
+
+			router := chi.NewRouter()
+			router.Use(middleware.URLFormat)
+			router.Get("/simulate/runs/{id}", simulation.RunHandler(log, runGetterMock, cfg))
+
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+			require.Equal(t, tc.ResponseCode, rr.Code)
+
+			if tc.JSON {
+				assert.JSONEq(t, tc.ResponseBody, rr.Body.String())
+			} else {
+				assert.Equal(t, tc.ResponseBody, rr.Body.String())
+			}
+		})
+	}
+}
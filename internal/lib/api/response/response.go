@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/PIRSON21/parking/internal/config"
+	"github.com/PIRSON21/parking/internal/lib/money"
 	"github.com/PIRSON21/parking/internal/models"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
@@ -22,13 +23,18 @@ type RespErrorList struct {
 
 // ParkingResponse - формат информации для response об одной парковке.
 type ParkingResponse struct {
-	ID          int                    `json:"id"`
-	Name        string                 `json:"name"`
-	Address     string                 `json:"address"`
-	DayTariff   int                    `json:"day_tariff"`
-	NightTariff int                    `json:"night_tariff"`
-	Cells       [][]models.ParkingCell `json:"cells"`
-	URL         string                 `json:"url"`
+	ID            int                    `json:"id"`
+	Name          string                 `json:"name"`
+	Address       string                 `json:"address"`
+	DayTariff     money.Money            `json:"day_tariff"`
+	NightTariff   money.Money            `json:"night_tariff"`
+	EnergyTariff  *money.Money           `json:"energy_tariff,omitempty"`
+	TariffPeriods []models.TariffPeriod  `json:"tariff_periods,omitempty"`
+	TariffRules   *models.TariffRules    `json:"tariff_rules,omitempty"`
+	Holidays      []string               `json:"holidays,omitempty"`
+	TimeZone      string                 `json:"time_zone,omitempty"`
+	Cells         [][]models.ParkingCell `json:"cells"`
+	URL           string                 `json:"url"`
 }
 
 // UnknownError - ответ, возвращаемый без конкретного поля ошибки.
@@ -60,13 +66,18 @@ func ListError(field string, errors []error) map[string]interface{} {
 // NewParkingResponse создает ответ ParkingResponse для рендера.
 func NewParkingResponse(p *models.Parking) *ParkingResponse {
 	return &ParkingResponse{
-		ID:          p.ID,
-		Name:        p.Name,
-		Address:     p.Address,
-		DayTariff:   *p.DayTariff,
-		NightTariff: *p.NightTariff,
-		Cells:       append([][]models.ParkingCell{}, p.Cells...),
-		URL:         fmt.Sprintf("/parking/%d", p.ID),
+		ID:            p.ID,
+		Name:          p.Name,
+		Address:       p.Address,
+		DayTariff:     *p.DayTariff,
+		NightTariff:   *p.NightTariff,
+		EnergyTariff:  p.EnergyTariff,
+		TariffPeriods: p.TariffPeriods,
+		TariffRules:   p.TariffRules,
+		Holidays:      p.Holidays,
+		TimeZone:      p.TimeZone,
+		Cells:         append([][]models.ParkingCell{}, p.Cells...),
+		URL:           fmt.Sprintf("/parking/%d", p.ID),
 	}
 }
 
@@ -97,6 +108,20 @@ var validationErrorMessages = map[string]string{
 	"gte":                "Значение не может быть меньше %s",
 	"email":              "Введенное значение не email",
 	"required_with_type": "Необходимо вместе с %s",
+	"len":                "Количество значений должно быть равно %s",
+	"gt_sum":             "Сумма значений должна быть больше %s",
+	"oneof":              "Значение должно быть одним из: %s",
+	"gt":                 "Значение должно быть больше %s",
+	"lt":                 "Значение должно быть меньше %s",
+	"no_overlap":         "Периоды не должны пересекаться",
+	"ascending":          "Пороги должны идти по возрастанию загрузки, а множители - не убывать",
+	"reserved_spots":     "На парковке только %s обычных мест для закрепления за абонентами",
+	"unique":             "Значения не должны повторяться",
+	"no_spots":           "На парковке нет мест для класса %s",
+	"datetime":           "Значение должно быть в формате %s",
+	"tariff_periods":     "Время начала периодов одного типа дня не должно повторяться",
+	"timezone":           "Значение должно быть часовым поясом IANA, например Europe/Moscow",
+	"currency":           "Сумма должна быть в валюте парковки %s",
 }
 
 func ValidationError(validateErr validator.ValidationErrors) map[string]string {
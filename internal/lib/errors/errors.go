@@ -15,3 +15,37 @@ var ErrParkingNotFound = errors.New("парковка не найдена")
 var ErrParkingAccessDenied = errors.New("доступ к парковке запрещен")
 
 var ErrParkingAlreadyExists = errors.New("парковка с таким именем и адресом уже существует")
+
+var ErrSimulationNotStarted = errors.New("симуляция не запущена")
+
+var ErrInvalidSpeed = errors.New("недопустимая скорость симуляции")
+
+var ErrSkipBackwards = errors.New("перемотать симуляцию можно только вперед")
+
+var ErrInvalidSkipTime = errors.New("некорректное время перемотки")
+
+var ErrSimulationAlreadyStarted = errors.New("симуляция уже запущена")
+
+var ErrSimulationStopped = errors.New("симуляция остановлена")
+
+var ErrSimulationNotRunning = errors.New("симуляция не идет")
+
+var ErrSimulationNotPaused = errors.New("симуляция не на паузе")
+
+var ErrCarNotWaiting = errors.New("машина не ждет въезда на парковку")
+
+var ErrSimulationNotFound = errors.New("симуляция не найдена или время ожидания переподключения истекло")
+
+var ErrSimulationAccessDenied = errors.New("доступ к симуляции запрещен")
+
+var ErrSimulationInUse = errors.New("к симуляции уже подключен клиент")
+
+var ErrSimulationRunNotFound = errors.New("прогон симуляции не найден")
+
+var ErrInvalidSeekTime = errors.New("время перемотки вне прогона симуляции")
+
+var ErrInvalidExportFormat = errors.New("неподдерживаемый формат выгрузки")
+
+var ErrUnsupportedCurrency = errors.New("неподдерживаемая валюта")
+
+var ErrCurrencyMismatch = errors.New("валюта тарифов должна совпадать с валютой парковки")
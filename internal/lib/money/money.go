@@ -0,0 +1,151 @@
+// Package money описывает денежные суммы в минимальных единицах валюты (копейках).
+//
+// Правила округления:
+//   - суммы хранятся и складываются только в целых копейках, поэтому итоги не накапливают ошибку;
+//   - дробная сумма (ставка, умноженная на часы или кВт·ч, сумма в рублях из старого формата)
+//     округляется до целой копейки по математическим правилам: половина копейки - от нуля (12.345 -> 12.35);
+//   - стоимость стоянки округляется один раз, после применения максимума за сутки и минимальной платы.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"golang.org/x/xerrors"
+)
+
+// DefaultCurrency - валюта по умолчанию.
+const DefaultCurrency = "RUB"
+
+// minorUnits - количество минимальных единиц в единице валюты.
+const minorUnits = 100
+
+// currencies - поддерживаемые валюты (ISO 4217). У всех в единице 100 минимальных единиц.
+var currencies = map[string]struct{}{
+	"RUB": {},
+	"USD": {},
+	"EUR": {},
+}
+
+// Money - денежная сумма в минимальных единицах валюты.
+//
+// В JSON записывается объектом {"amount": 1250, "currency": "RUB"}, где amount - сумма в копейках.
+// Для совместимости со старым форматом читается и число: сумма в рублях, которая округляется до копейки.
+type Money struct {
+	Amount   int64  // сумма в копейках
+	Currency string // код валюты ISO 4217
+}
+
+// moneyJSON - представление Money в JSON.
+type moneyJSON struct {
+	Amount   *int64 `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New создает сумму amount копеек в валюте currency.
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// NewPtr создает указатель на сумму amount копеек в валюте currency.
+func NewPtr(amount int64, currency string) *Money {
+	m := New(amount, currency)
+	return &m
+}
+
+// FromMajor создает сумму по значению в единицах валюты (рублях), округляя его до копейки.
+func FromMajor(major float64, currency string) Money {
+	return New(Round(major*minorUnits), currency)
+}
+
+// Round округляет дробную сумму в копейках до целой копейки (половина - от нуля).
+func Round(minor float64) int64 {
+	return int64(math.Round(minor))
+}
+
+// Supported проверяет, что валюта currency поддерживается.
+func Supported(currency string) bool {
+	_, ok := currencies[currency]
+	return ok
+}
+
+// Major возвращает сумму в единицах валюты (рублях). Нужна только для статистики и отображения.
+func (m Money) Major() float64 {
+	return float64(m.Amount) / minorUnits
+}
+
+// Add возвращает сумму m и other. Сумма без валюты принимает валюту other.
+//
+// Предусловие: m и other в одной валюте. Валюты не сверяются - это делает валидатор:
+// все суммы парковки должны быть в валюте дневного тарифа. Результат остается в валюте m.
+func (m Money) Add(other Money) Money {
+	if m.Currency == "" {
+		m.Currency = other.Currency
+	}
+	m.Amount += other.Amount
+
+	return m
+}
+
+// Sub возвращает разность m и other. Предусловие то же, что у Add.
+func (m Money) Sub(other Money) Money {
+	other.Amount = -other.Amount
+	return m.Add(other)
+}
+
+// Mul возвращает m, умноженную на factor и округленную до копейки.
+func (m Money) Mul(factor float64) Money {
+	m.Amount = Round(float64(m.Amount) * factor)
+	return m
+}
+
+// String возвращает сумму в единицах валюты с двумя знаками после точки, например "12.50".
+func (m Money) String() string {
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, amount/minorUnits, amount%minorUnits)
+}
+
+// MarshalJSON записывает сумму объектом с суммой в копейках и валютой.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: &m.Amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON читает сумму из объекта {"amount": копейки, "currency": "RUB"} (валюта по умолчанию - RUB)
+// или из числа в рублях.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	const op = "money.Money.UnmarshalJSON"
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		var major float64
+		if err := json.Unmarshal(data, &major); err != nil {
+			return xerrors.Errorf("%s: %w", op, err)
+		}
+		*m = FromMajor(major, DefaultCurrency)
+		return nil
+	}
+
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return xerrors.Errorf("%s: %w", op, err)
+	}
+	if v.Amount == nil {
+		return xerrors.Errorf("%s: amount is required", op)
+	}
+	if v.Currency == "" {
+		v.Currency = DefaultCurrency
+	}
+	if !Supported(v.Currency) {
+		return xerrors.Errorf("%s: %w: %s", op, custErr.ErrUnsupportedCurrency, v.Currency)
+	}
+
+	*m = New(*v.Amount, v.Currency)
+	return nil
+}
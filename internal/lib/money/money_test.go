@@ -0,0 +1,137 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRounding(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Actual   money.Money
+		Expected int64
+	}{
+		{Name: "Mul rounds half up", Actual: money.New(125, money.DefaultCurrency).Mul(0.5), Expected: 63},
+		{Name: "Mul rounds half away from zero for negative", Actual: money.New(-125, money.DefaultCurrency).Mul(0.5), Expected: -63},
+		{Name: "Mul rounds down below half", Actual: money.New(1250, money.DefaultCurrency).Mul(7.0 / 60), Expected: 146},
+		{Name: "FromMajor rounds half kopeck up", Actual: money.FromMajor(12.345, money.DefaultCurrency), Expected: 1235},
+		{Name: "FromMajor rounds half kopeck away from zero for negative", Actual: money.FromMajor(-12.345, money.DefaultCurrency), Expected: -1235},
+		{Name: "FromMajor keeps whole kopecks", Actual: money.FromMajor(20.5, money.DefaultCurrency), Expected: 2050},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, tc.Actual.Amount)
+			assert.Equal(t, money.DefaultCurrency, tc.Actual.Currency)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		Amount   int64
+		Expected string
+	}{
+		{Amount: 0, Expected: "0.00"},
+		{Amount: 5, Expected: "0.05"},
+		{Amount: 1250, Expected: "12.50"},
+		{Amount: 100000, Expected: "1000.00"},
+		{Amount: -5, Expected: "-0.05"},
+		{Amount: -1250, Expected: "-12.50"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Expected, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, money.New(tc.Amount, money.DefaultCurrency).String())
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Input       string
+		Expected    money.Money
+		ExpectedErr error
+		Output      string
+	}{
+		{
+			Name:     "Object",
+			Input:    `{"amount":1250,"currency":"USD"}`,
+			Expected: money.New(1250, "USD"),
+			Output:   `{"amount":1250,"currency":"USD"}`,
+		},
+		{
+			Name:     "Object without currency",
+			Input:    `{"amount":1250}`,
+			Expected: money.New(1250, money.DefaultCurrency),
+			Output:   `{"amount":1250,"currency":"RUB"}`,
+		},
+		{
+			Name:     "Legacy rubles",
+			Input:    `12.5`,
+			Expected: money.New(1250, money.DefaultCurrency),
+			Output:   `{"amount":1250,"currency":"RUB"}`,
+		},
+		{
+			Name:     "Legacy rubles rounded to kopecks",
+			Input:    ` 12.345 `,
+			Expected: money.New(1235, money.DefaultCurrency),
+			Output:   `{"amount":1235,"currency":"RUB"}`,
+		},
+		{
+			Name:        "Unsupported currency",
+			Input:       `{"amount":1250,"currency":"XXX"}`,
+			ExpectedErr: custErr.ErrUnsupportedCurrency,
+		},
+		{
+			Name:  "No amount",
+			Input: `{"currency":"RUB"}`,
+		},
+		{
+			Name:  "Not a number",
+			Input: `"12.50"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var m money.Money
+			err := json.Unmarshal([]byte(tc.Input), &m)
+			if tc.Output == "" {
+				require.Error(t, err)
+				if tc.ExpectedErr != nil {
+					assert.ErrorIs(t, err, tc.ExpectedErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, m)
+
+			data, err := json.Marshal(m)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.Output, string(data))
+
+			var roundTrip money.Money
+			require.NoError(t, json.Unmarshal(data, &roundTrip))
+			assert.Equal(t, m, roundTrip)
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	rub := money.New(1250, money.DefaultCurrency)
+
+	assert.Equal(t, money.New(2500, money.DefaultCurrency), rub.Add(rub))
+	assert.Equal(t, money.New(0, money.DefaultCurrency), rub.Sub(rub))
+	assert.Equal(t, rub, money.Money{}.Add(rub))
+	assert.Equal(t, rub, rub.Add(money.Money{}))
+
+	assert.NotPanics(t, func() { rub.Add(money.New(100, "USD")) })
+	assert.NotPanics(t, func() { rub.Sub(money.New(100, "USD")) })
+}
@@ -1,6 +1,10 @@
 package test
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/PIRSON21/parking/internal/lib/money"
+)
 
 func MustMarshal(v interface{}) []byte {
 	data, err := json.Marshal(v)
@@ -23,6 +27,11 @@ func NewInt(v int) *int {
 	return &v
 }
 
+// NewMoney возвращает указатель на сумму v копеек в рублях.
+func NewMoney(v int64) *money.Money {
+	return money.NewPtr(v, money.DefaultCurrency)
+}
+
 const (
 	EnvLocal = "local"
 	EnvDev   = "dev"
@@ -3,8 +3,11 @@ package validator
 import (
 	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/PIRSON21/parking/internal/lib/money"
 	"github.com/PIRSON21/parking/internal/models"
 	"github.com/PIRSON21/parking/internal/simulation"
 	"github.com/go-playground/validator/v10"
@@ -23,9 +26,162 @@ func CreateNewValidator() *validator.Validate {
 		return name
 	})
 
+	_ = valid.RegisterValidation("tariff_periods", validateTariffPeriods)
+
+	// суммы проверяются в копейках
+	valid.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		return field.Interface().(money.Money).Amount
+	}, money.Money{})
+
+	valid.RegisterStructValidation(ParkingStructLevelValidation, models.Parking{})
+
 	return valid
 }
 
+// ParkingStructLevelValidation проверяет, что все суммы парковки указаны в валюте дневного тарифа.
+func ParkingStructLevelValidation(sl validator.StructLevel) {
+	parking := sl.Current().Interface().(models.Parking)
+	currency := parking.Currency()
+
+	check := func(m *money.Money, field, structField string) {
+		if m != nil && m.Currency != currency {
+			sl.ReportError(m, field, structField, "currency", currency)
+		}
+	}
+
+	check(parking.NightTariff, "night_tariff", "NightTariff")
+	check(parking.EnergyTariff, "energy_tariff", "EnergyTariff")
+	for _, period := range parking.TariffPeriods {
+		check(period.Rate, "tariff_periods", "TariffPeriods")
+	}
+	if parking.TariffRules != nil {
+		check(parking.TariffRules.DailyCap, "daily_cap", "DailyCap")
+		check(parking.TariffRules.MinCharge, "min_charge", "MinCharge")
+	}
+}
+
+// validateTariffPeriods проверяет, что у тарифных периодов одного типа дня не совпадает время начала.
+func validateTariffPeriods(fl validator.FieldLevel) bool {
+	periods, ok := fl.Field().Interface().([]models.TariffPeriod)
+	if !ok {
+		return false
+	}
+
+	seen := make(map[[2]string]struct{}, len(periods))
+	for _, period := range periods {
+		key := [2]string{period.DayType, period.Start}
+		if _, ok = seen[key]; ok {
+			return false
+		}
+		seen[key] = struct{}{}
+	}
+
+	return true
+}
+
+// CreateSimulationValidator создает валидатор для параметров симуляции
+// с кастомными проверками конфигураций моделирования.
+func CreateSimulationValidator() *validator.Validate {
+	valid := CreateNewValidator()
+
+	valid.RegisterStructValidation(ArrivalConfigStructLevelValidation, simulation.ArrivalConfig{})
+	valid.RegisterStructValidation(ParkingTimeConfigStructLevelValidation, simulation.ParkingTimeConfig{})
+	valid.RegisterStructValidation(ChargingConfigStructLevelValidation, simulation.ChargingConfig{})
+	valid.RegisterStructValidation(PricingPolicyStructLevelValidation, simulation.PricingPolicy{})
+	valid.RegisterStructValidation(InitParamsStructLevelValidation, simulation.InitParams{})
+
+	return valid
+}
+
+// InitParamsStructLevelValidation проверяет согласованность параметров симуляции с топологией парковки.
+func InitParamsStructLevelValidation(sl validator.StructLevel) {
+	params := sl.Current().Interface().(simulation.InitParams)
+	if params.Parking == nil || params.ArrivalConfig == nil {
+		return
+	}
+
+	validateVehicleClasses(sl, params)
+	validateSubscribers(sl, params)
+
+	if params.ArrivalConfig.EVShare > 0 && params.ChargingConfig == nil {
+		sl.ReportError(params.ChargingConfig, "charging_config", "ChargingConfig", "required", "")
+	}
+
+	weights := params.ArrivalConfig.EntranceWeights
+	if len(weights) == 0 {
+		return
+	}
+
+	entrances := countCells(params.Parking, func(cell models.ParkingCell) bool { return cell.IsEntrance() })
+	if len(weights) != entrances {
+		sl.ReportError(weights, "entrance_weights", "EntranceWeights", "len", strconv.Itoa(entrances))
+		return
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		sl.ReportError(weights, "entrance_weights", "EntranceWeights", "gt_sum", "0")
+	}
+}
+
+// validateVehicleClasses проверяет, что классы транспорта не повторяются
+// и для каждого класса на парковке есть подходящие места.
+func validateVehicleClasses(sl validator.StructLevel, params simulation.InitParams) {
+	seen := make(map[models.VehicleClass]struct{}, len(params.VehicleClasses))
+
+	for _, c := range params.VehicleClasses {
+		if _, ok := seen[c.Class]; ok {
+			sl.ReportError(params.VehicleClasses, "vehicle_classes", "VehicleClasses", "unique", "")
+			return
+		}
+		seen[c.Class] = struct{}{}
+
+		if countCells(params.Parking, func(cell models.ParkingCell) bool { return cell.Accepts(c.Class) }) == 0 {
+			sl.ReportError(params.VehicleClasses, "vehicle_classes", "VehicleClasses", "no_spots", string(c.Class))
+			return
+		}
+	}
+}
+
+// validateSubscribers проверяет, что абонентам хватает обычных мест для закрепления
+// и что для электромобилей абонентов задана зарядка.
+func validateSubscribers(sl validator.StructLevel, params simulation.InitParams) {
+	subscribers := params.Subscribers
+	if subscribers == nil || subscribers.ArrivalConfig == nil {
+		return
+	}
+
+	if subscribers.ArrivalConfig.EVShare > 0 && params.ChargingConfig == nil {
+		sl.ReportError(params.ChargingConfig, "charging_config", "ChargingConfig", "required", "")
+	}
+
+	if !subscribers.Reserved {
+		return
+	}
+
+	spots := countCells(params.Parking, func(cell models.ParkingCell) bool { return cell == models.Park })
+	if subscribers.Count > spots {
+		sl.ReportError(subscribers, "subscribers", "Subscribers", "reserved_spots", strconv.Itoa(spots))
+	}
+}
+
+// countCells считает клетки топологии парковки, подходящие под условие match.
+func countCells(parking *models.Parking, match func(models.ParkingCell) bool) int {
+	count := 0
+	for _, row := range parking.Cells {
+		for _, cell := range row {
+			if match(cell) {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
 func ArrivalConfigStructLevelValidation(sl validator.StructLevel) {
 	ac := sl.Current().Interface().(simulation.ArrivalConfig)
 
@@ -53,9 +209,60 @@ func ArrivalConfigStructLevelValidation(sl validator.StructLevel) {
 		if ac.DiscreteTime == 0 {
 			sl.ReportError(ac.DiscreteTime, "DiscreteTime", "discrete_time", "required_with_type", "discrete")
 		}
+	case "profile":
+		validateProfile(sl, ac.Profile)
 	}
 }
 
+// validateProfile проверяет профиль интенсивности: периоды не пустые, не пересекаются
+// и хотя бы в одном из них машины приезжают.
+func validateProfile(sl validator.StructLevel, profile []simulation.IntensityPeriod) {
+	if len(profile) == 0 {
+		sl.ReportError(profile, "profile", "Profile", "required_with_type", "profile")
+		return
+	}
+
+	total := 0.0
+	for i, period := range profile {
+		if period.FromHour >= period.ToHour {
+			sl.ReportError(period.FromHour, "from_hour", "FromHour", "lt", strconv.Itoa(period.ToHour))
+			return
+		}
+
+		for _, other := range profile[:i] {
+			if periodsOverlap(period, other) {
+				sl.ReportError(profile, "profile", "Profile", "no_overlap", "")
+				return
+			}
+		}
+
+		total += period.Rate
+	}
+
+	if total <= 0 {
+		sl.ReportError(profile, "profile", "Profile", "gt_sum", "0")
+	}
+}
+
+// periodsOverlap проверяет, пересекаются ли два периода профиля по часам и дням недели.
+func periodsOverlap(a, b simulation.IntensityPeriod) bool {
+	if a.FromHour >= b.ToHour || b.FromHour >= a.ToHour {
+		return false
+	}
+
+	if len(a.Weekdays) == 0 || len(b.Weekdays) == 0 {
+		return true
+	}
+
+	for _, day := range a.Weekdays {
+		if slices.Contains(b.Weekdays, day) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func ParkingTimeConfigStructLevelValidation(sl validator.StructLevel) {
 	tc := sl.Current().Interface().(simulation.ParkingTimeConfig)
 
@@ -86,6 +293,46 @@ func ParkingTimeConfigStructLevelValidation(sl validator.StructLevel) {
 	}
 }
 
+func ChargingConfigStructLevelValidation(sl validator.StructLevel) {
+	cc := sl.Current().Interface().(simulation.ChargingConfig)
+
+	switch cc.Type {
+	case "normal":
+		if cc.Mean == 0 {
+			sl.ReportError(cc.Mean, "mean", "mean", "required_with_type", "normal")
+		}
+		if cc.StdDev == 0 {
+			sl.ReportError(cc.StdDev, "std_dev", "std_dev", "required_with_type", "normal")
+		}
+	case "uniform":
+		if cc.MinEnergy == 0 || cc.MaxEnergy == 0 {
+			sl.ReportError(cc.MinEnergy, "min_energy", "min_energy", "required_with_type", "uniform")
+			sl.ReportError(cc.MaxEnergy, "max_energy", "max_energy", "required_with_type", "uniform")
+		}
+		if cc.MaxEnergy < cc.MinEnergy {
+			sl.ReportError(cc.MinEnergy, "min_energy", "MinEnergy", "lte", fmt.Sprintf("%0.2f", cc.MaxEnergy))
+		}
+	case "discrete":
+		if cc.DiscreteEnergy == 0 {
+			sl.ReportError(cc.DiscreteEnergy, "discrete_energy", "discrete_energy", "required_with_type", "discrete")
+		}
+	}
+}
+
+// PricingPolicyStructLevelValidation проверяет, что пороги динамической тарификации идут по возрастанию загрузки,
+// а множители ставок с загрузкой не уменьшаются.
+func PricingPolicyStructLevelValidation(sl validator.StructLevel) {
+	policy := sl.Current().Interface().(simulation.PricingPolicy)
+
+	for i := 1; i < len(policy.Thresholds); i++ {
+		prev, cur := policy.Thresholds[i-1], policy.Thresholds[i]
+		if cur.Occupancy <= prev.Occupancy || cur.Multiplier < prev.Multiplier {
+			sl.ReportError(policy.Thresholds, "thresholds", "Thresholds", "ascending", "")
+			return
+		}
+	}
+}
+
 // ValidateParkingCells проверяет клетки парковки на соответствие требованиям.
 // Возвращает список всех найденных ошибок
 func ValidateParkingCells(parking *models.Parking) []error {
@@ -135,9 +382,6 @@ func ValidateParkingCells(parking *models.Parking) []error {
 
 func validateExit(countExit *int, height int, i int, j int) []error {
 	var errors []error
-	if *countExit >= 1 {
-		errors = append(errors, xerrors.Errorf("в топологии парковки не может быть более одной точки выхода"))
-	}
 	*countExit++
 	if i != height-1 {
 		errors = append(errors, xerrors.Errorf("точка выхода должна быть в нижней строке парковки, а не на (%d,%d)", i, j))
@@ -147,9 +391,6 @@ func validateExit(countExit *int, height int, i int, j int) []error {
 
 func validateEnterance(countEnterance *int, i int, height int, j int) []error {
 	var errors []error
-	if *countEnterance >= 1 {
-		errors = append(errors, xerrors.Errorf("в топологии парковки не может быть более одной точки входа"))
-	}
 	*countEnterance++
 	if i != height-1 {
 		errors = append(errors, xerrors.Errorf("точка входа должна быть в нижней строке парковки, а не на (%d,%d)", i, j))
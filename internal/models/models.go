@@ -2,19 +2,63 @@ package models
 
 import (
 	"strings"
+	"time"
+	_ "time/tzdata" // база часовых поясов на случай, если ее нет в системе
+
+	"github.com/PIRSON21/parking/internal/lib/money"
 )
 
+// DefaultTimeZone - часовой пояс парковки, для которой он не указан.
+const DefaultTimeZone = "Europe/Moscow"
+
 // Parking - данные о парковке.
 type Parking struct {
-	ID          int             `json:"id,omitempty"`
-	Name        string          `json:"name" validate:"required,min=3,max=10"`
-	Address     string          `json:"address" validate:"required,min=10,max=30"`
-	Width       int             `json:"width" validate:"required,gte=4,lte=6"`
-	Height      int             `json:"height" validate:"required,gte=4,lte=6"`
-	DayTariff   *int            `json:"day_tariff" validate:"required,gte=0,lte=1000"`
-	NightTariff *int            `json:"night_tariff" validate:"required,gte=0,lte=1000"`
-	Cells       [][]ParkingCell `json:"cells,omitempty"`
-	Manager     *Manager        `json:"manager,omitempty"`
+	ID      int    `json:"id,omitempty"`
+	Name    string `json:"name" validate:"required,min=3,max=10"`
+	Address string `json:"address" validate:"required,min=10,max=30"`
+	Width   int    `json:"width" validate:"required,gte=4,lte=6"`
+	Height  int    `json:"height" validate:"required,gte=4,lte=6"`
+	// DayTariff, NightTariff - почасовые ставки (в копейках, не больше 1000 рублей).
+	// Валюта дневного тарифа - валюта парковки, остальные суммы должны быть в ней же.
+	DayTariff   *money.Money `json:"day_tariff" validate:"required,gte=0,lte=100000"`
+	NightTariff *money.Money `json:"night_tariff" validate:"required,gte=0,lte=100000"`
+	// EnergyTariff - стоимость 1 кВт·ч на зарядных станциях.
+	EnergyTariff *money.Money `json:"energy_tariff,omitempty" validate:"omitempty,gte=0,lte=100000"`
+	// TariffPeriods - тарифные периоды по типам дней. Без них действуют DayTariff с 06:00 и NightTariff с 22:00.
+	TariffPeriods []TariffPeriod `json:"tariff_periods,omitempty" validate:"omitempty,tariff_periods,dive"`
+	// TariffRules - правила тарификации: бесплатные минуты, блоки, максимум за сутки и минимальная плата.
+	TariffRules *TariffRules `json:"tariff_rules,omitempty"`
+	// Holidays - даты праздников (YYYY-MM-DD), в которые действуют периоды "holiday".
+	Holidays []string `json:"holidays,omitempty" validate:"omitempty,dive,datetime=2006-01-02"`
+	// TimeZone - часовой пояс IANA (например, Europe/Moscow), в котором действуют тарифы и профили прибытий.
+	TimeZone string          `json:"time_zone,omitempty" validate:"omitempty,timezone"`
+	Cells    [][]ParkingCell `json:"cells,omitempty"`
+	Manager  *Manager        `json:"manager,omitempty"`
+}
+
+// Currency возвращает валюту парковки - валюту дневного тарифа.
+func (p *Parking) Currency() string {
+	if p.DayTariff != nil && p.DayTariff.Currency != "" {
+		return p.DayTariff.Currency
+	}
+
+	return money.DefaultCurrency
+}
+
+// Location возвращает часовой пояс парковки. Для неизвестного или не указанного пояса - DefaultTimeZone.
+func (p *Parking) Location() *time.Location {
+	if p.TimeZone != "" {
+		if loc, err := time.LoadLocation(p.TimeZone); err == nil {
+			return loc
+		}
+	}
+
+	loc, err := time.LoadLocation(DefaultTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
 }
 
 type Manager struct {
@@ -25,20 +69,47 @@ type Manager struct {
 type ParkingCell string
 
 const (
-	Road       ParkingCell = "."
-	Park       ParkingCell = "P"
-	Entrance   ParkingCell = "I"
-	Exit       ParkingCell = "O"
-	Decoration ParkingCell = "D"
+	Road           ParkingCell = "."
+	Park           ParkingCell = "P"
+	MotorcyclePark ParkingCell = "M" // место для мотоциклов
+	VanPark        ParkingCell = "V" // увеличенное место для фургонов
+	TruckPark      ParkingCell = "T" // место для грузовиков
+	ChargerPark    ParkingCell = "E" // место с зарядной станцией для электромобилей
+	Entrance       ParkingCell = "I"
+	Exit           ParkingCell = "O"
+	Decoration     ParkingCell = "D"
 )
 
 // validCells - мапа для проверки клетки (так быстрее).
 var validCells = map[ParkingCell]struct{}{
-	Road:       {},
-	Park:       {},
-	Entrance:   {},
-	Exit:       {},
-	Decoration: {},
+	Road:           {},
+	Park:           {},
+	MotorcyclePark: {},
+	VanPark:        {},
+	TruckPark:      {},
+	ChargerPark:    {},
+	Entrance:       {},
+	Exit:           {},
+	Decoration:     {},
+}
+
+// VehicleClass - класс транспортного средства.
+type VehicleClass string
+
+const (
+	Car        VehicleClass = "car"
+	Motorcycle VehicleClass = "motorcycle"
+	Van        VehicleClass = "van"
+	Truck      VehicleClass = "truck"
+)
+
+// parkingClasses - классы транспорта, которые может принять парковочная клетка.
+var parkingClasses = map[ParkingCell][]VehicleClass{
+	Park:           {Car, Motorcycle},
+	MotorcyclePark: {Motorcycle},
+	VanPark:        {Van, Car},
+	TruckPark:      {Truck, Van},
+	ChargerPark:    {Car, Van},
 }
 
 // IsParkingCell проверяет, является ли текущая строка - правильной ParkingCell.
@@ -52,8 +123,26 @@ func (c *ParkingCell) IsRoad() bool {
 	return strings.EqualFold(string(*c), string(Road))
 }
 
+// IsParking проверяет, является ли текущая клетка парковочным местом (любого класса).
 func (c *ParkingCell) IsParking() bool {
-	return strings.EqualFold(string(*c), string(Park))
+	_, exists := parkingClasses[ParkingCell(strings.ToUpper(string(*c)))]
+	return exists
+}
+
+// Accepts проверяет, может ли транспорт класса class встать на текущую клетку.
+func (c *ParkingCell) Accepts(class VehicleClass) bool {
+	for _, accepted := range parkingClasses[ParkingCell(strings.ToUpper(string(*c)))] {
+		if accepted == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsCharger проверяет, является ли текущая клетка местом с зарядной станцией.
+func (c *ParkingCell) IsCharger() bool {
+	return strings.EqualFold(string(*c), string(ChargerPark))
 }
 
 func (c *ParkingCell) IsEntrance() bool {
@@ -64,6 +153,11 @@ func (c *ParkingCell) IsExit() bool {
 	return *c == Exit
 }
 
+// IsPassable проверяет, может ли машина проезжать через клетку (дорога, въезд или выезд).
+func (c *ParkingCell) IsPassable() bool {
+	return c.IsRoad() || c.IsEntrance() || c.IsExit()
+}
+
 // ParkingCellStruct используется для получения/сохранения данных о клетках парковки в БД
 type ParkingCellStruct struct {
 	X, Y     int
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SimulationRun - сохраненный прогон симуляции.
+type SimulationRun struct {
+	ID         int             `json:"id"`
+	ParkingID  *int            `json:"parking_id,omitempty"` // парковка менеджера, если клиент прислал ее id
+	ManagerID  int             `json:"manager_id"`           // менеджер, запустивший симуляцию
+	Seed       uint64          `json:"seed"`
+	Strategy   string          `json:"strategy"`
+	Params     json.RawMessage `json:"params,omitempty"`  // параметры симуляции (InitParams)
+	Summary    json.RawMessage `json:"summary,omitempty"` // итоговая сводка, если прогон завершен
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// SimulationEvent - сохраненное событие машины в прогоне симуляции.
+type SimulationEvent struct {
+	Seq       int             `json:"seq"`       // порядковый номер события в прогоне
+	Type      string          `json:"event"`     // "arrive", "park", "leave" и т.д.
+	TimeStamp int64           `json:"timestamp"` // модельное время события
+	CarID     string          `json:"car_id"`
+	Payload   json.RawMessage `json:"payload"` // событие целиком, как его получил клиент
+}
@@ -6,29 +6,31 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ivahaev/timer"
+	"github.com/PIRSON21/parking/internal/lib/money"
 )
 
 // ParkingSpot отражает парковочное место.
 type ParkingPoint struct {
-	cell   ParkingCell
-	X, Y   int
-	isFree bool
+	cell     ParkingCell
+	X, Y     int
+	isFree   bool
+	reserved bool // место закреплено за абонентом
+	uses     int  // сколько раз место занимали
 }
 
 // ParkingLot отражает топологию парковки.
 type ParkingLot struct {
-	mu          sync.Mutex
-	topology    [][]*ParkingPoint
-	EntryX      int
-	EntryY      int
-	DayTariff   float64
-	NightTariff float64
+	mu           sync.Mutex
+	topology     [][]*ParkingPoint
+	Entrances    []PathPoint // въезды в порядке обхода топологии по строкам
+	Exits        []PathPoint // выезды в порядке обхода топологии по строкам
+	EnergyTariff money.Money // стоимость 1 кВт·ч
+	strategy     SpotStrategy
 }
 
 // NewParkingLot создает модель парковки для сессии.
 func NewParkingLot(parking *Parking) *ParkingLot {
-	var entryX, entryY int
+	var entrances, exits []PathPoint
 
 	var topology [][]*ParkingPoint
 
@@ -42,21 +44,58 @@ func NewParkingLot(parking *Parking) *ParkingLot {
 				isFree: true,
 			})
 			if parking.Cells[width][height].IsEntrance() {
-				entryX = width
-				entryY = height
+				entrances = append(entrances, PathPoint{X: width, Y: height})
+			}
+			if parking.Cells[width][height].IsExit() {
+				exits = append(exits, PathPoint{X: width, Y: height})
 			}
 		}
 	}
 
+	// без въезда машины появляются в левом верхнем углу
+	if len(entrances) == 0 {
+		entrances = append(entrances, PathPoint{X: 0, Y: 0})
+	}
+
+	energyTariff := money.New(0, parking.Currency())
+	if parking.EnergyTariff != nil {
+		energyTariff = *parking.EnergyTariff
+	}
+
 	return &ParkingLot{
-		topology:    topology,
-		EntryX:      entryX,
-		EntryY:      entryY,
-		DayTariff:   float64(*parking.DayTariff),
-		NightTariff: float64(*parking.NightTariff),
+		strategy:     NearestToEntrance{},
+		topology:     topology,
+		Entrances:    entrances,
+		Exits:        exits,
+		EnergyTariff: energyTariff,
 	}
 }
 
+// SetStrategy задает стратегию выбора парковочного места.
+func (p *ParkingLot) SetStrategy(strategy SpotStrategy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.strategy = strategy
+}
+
+// Capacity возвращает количество парковочных мест.
+func (p *ParkingLot) Capacity() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	count := 0
+	for _, row := range p.topology {
+		for _, point := range row {
+			if point.cell.IsParking() {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
 // HasFreeSpot проверит парковку на свободные места.
 func (p *ParkingLot) HasFreeSpot() bool {
 	p.mu.Lock()
@@ -65,26 +104,106 @@ func (p *ParkingLot) HasFreeSpot() bool {
 	return false
 }
 
-// OccupySpot занимает парковочное место (если найдёт). Вернёт nil, false если места нет.
-func (p *ParkingLot) OccupySpot() (*ParkingPoint, bool) {
+// SpotRequest описывает машину, которой нужно парковочное место.
+type SpotRequest struct {
+	Entrance PathPoint    // въезд, через который заезжает машина
+	Class    VehicleClass // класс транспорта
+	Charger  bool         // нужно место с зарядной станцией
+}
+
+// OccupySpot занимает парковочное место, выбранное стратегией парковки.
+// Места с зарядной станцией достаются только электромобилям: если свободной станции нет,
+// электромобиль встает на обычное место. Вернёт nil, false если подходящего места нет.
+func (p *ParkingLot) OccupySpot(req SpotRequest) (*ParkingPoint, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	var nearestSpot *ParkingPoint
-	minDistance := math.MaxFloat64
-
-	nearestSpot = p.findNearestSpot(minDistance)
+	spot := p.strategy.Choose(p, req)
+	if spot == nil && req.Charger {
+		req.Charger = false
+		spot = p.strategy.Choose(p, req)
+	}
 
-	if nearestSpot != nil {
-		nearestSpot.isFree = false
-		return nearestSpot, true
+	if spot != nil {
+		spot.isFree = false
+		spot.uses++
+		return spot, true
 	}
 
 	return nil, false
 }
 
-// findNearestSpot находит ближайшее свободное парковочное место с помощью алгоритма Дейкстры.
-func (p *ParkingLot) findNearestSpot(minDistance float64) *ParkingPoint {
+// ReserveSpots закрепляет за абонентами count обычных мест для легковых машин в порядке обхода топологии
+// по строкам. Закрепленные места не достаются другим машинам. Вернет закрепленные места;
+// их может оказаться меньше count, если обычных мест не хватает.
+func (p *ParkingLot) ReserveSpots(count int) []*ParkingPoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var spots []*ParkingPoint
+	for _, row := range p.topology {
+		for _, point := range row {
+			if len(spots) == count {
+				return spots
+			}
+			if point.cell == Park && !point.reserved {
+				point.reserved = true
+				spots = append(spots, point)
+			}
+		}
+	}
+
+	return spots
+}
+
+// OccupyReserved занимает закрепленное место spot. Вернет false, если место занято.
+func (p *ParkingLot) OccupyReserved(spot *ParkingPoint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !spot.isFree {
+		return false
+	}
+
+	spot.isFree = false
+	spot.uses++
+
+	return true
+}
+
+// spotCandidate - свободное парковочное место, до которого можно доехать.
+type spotCandidate struct {
+	spot     *ParkingPoint
+	distance float64 // расстояние от въезда по маршруту
+}
+
+// freeSpots находит подходящие под запрос req свободные парковочные места, до которых можно доехать от въезда,
+// вместе с расстояниями до них. Места перечисляются в порядке обхода топологии по строкам.
+func (p *ParkingLot) freeSpots(req SpotRequest) []spotCandidate {
+	dist := p.distancesFrom([]PathPoint{req.Entrance})
+	if dist == nil {
+		return nil
+	}
+
+	var spots []spotCandidate
+	for x := range p.topology {
+		for y, point := range p.topology[x] {
+			if !point.isFree || point.reserved || math.IsInf(dist[x][y], 1) {
+				continue
+			}
+			if point.cell.Accepts(req.Class) && point.cell.IsCharger() == req.Charger {
+				spots = append(spots, spotCandidate{spot: point, distance: dist[x][y]})
+			}
+		}
+	}
+
+	return spots
+}
+
+// distancesFrom считает расстояния по маршруту от ближайшей из точек sources до всех клеток
+// с помощью алгоритма Дейкстры. Машина едет по дороге, въездам и выездам,
+// а парковочные места могут быть только концом маршрута.
+func (p *ParkingLot) distancesFrom(sources []PathPoint) [][]float64 {
 	height := len(p.topology)
 	if height == 0 {
 		return nil
@@ -104,41 +223,32 @@ func (p *ParkingLot) findNearestSpot(minDistance float64) *ParkingPoint {
 		}
 	}
 
-	// Матрица для отслеживания посещенных ячеек
-	visited := make([][]bool, height)
-	for i := range visited {
-		visited[i] = make([]bool, width)
-	}
-
 	// Очередь с приоритетом для алгоритма Дейкстры
 	queue := make(priorityQueue, 0)
 
-	// Начинаем с точки входа
-	dist[p.EntryX][p.EntryY] = 0
-	queue = append(queue, item{value: 0, x: p.EntryX, y: p.EntryY})
+	// Начинаем с исходных точек
+	for _, from := range sources {
+		if from.X < 0 || from.X >= height || from.Y < 0 || from.Y >= width {
+			continue
+		}
+		dist[from.X][from.Y] = 0
+		queue = append(queue, item{value: 0, x: from.X, y: from.Y})
+	}
 	heap.Init(&queue)
 
-	// Лучшее парковочное место
-	var bestParkingSpot *ParkingPoint
-	var bestDistance float64 = math.Inf(1)
-
 	// Алгоритм Дейкстры
 	for queue.Len() > 0 {
 		current := heap.Pop(&queue).(item)
 		x, y := current.x, current.y
 
-		// Если уже посетили ячейку или расстояние больше, чем лучшее найденное, пропускаем
-		if visited[x][y] || dist[x][y] > bestDistance {
+		// устаревшая запись в очереди
+		if current.value > dist[x][y] {
 			continue
 		}
-		visited[x][y] = true
 
-		// Если это парковочное место и оно свободно
-		if p.topology[x][y].cell.IsParking() && p.topology[x][y].isFree {
-			if dist[x][y] < bestDistance {
-				bestDistance = dist[x][y]
-				bestParkingSpot = p.topology[x][y]
-			}
+		// с парковочного места дальше не едем (кроме исходной точки)
+		if current.value > 0 && !p.topology[x][y].cell.IsPassable() {
+			continue
 		}
 
 		for i := 0; i < 4; i++ {
@@ -149,17 +259,13 @@ func (p *ParkingLot) findNearestSpot(minDistance float64) *ParkingPoint {
 				continue
 			}
 
-			// Если клетка не дорога, не выезд, и не парковочное место
 			cell := p.topology[nx][ny].cell
-			if (!cell.IsRoad() && !cell.IsExit() && !(cell.IsParking() && p.topology[nx][ny].isFree)) || visited[nx][ny] {
-				// fmt.Printf("cell %s (%d, %d) is not road, exit or parking\n", cell, nx, ny)
+			if !cell.IsPassable() && !cell.IsParking() {
 				continue
 			}
 
-			// Вычисляем новое расстояние
-			newDistance := dist[x][y] + 1
-
 			// Если новое расстояние меньше, обновляем
+			newDistance := dist[x][y] + 1
 			if newDistance < dist[nx][ny] {
 				dist[nx][ny] = newDistance
 				heap.Push(&queue, item{value: newDistance, x: nx, y: ny})
@@ -167,12 +273,12 @@ func (p *ParkingLot) findNearestSpot(minDistance float64) *ParkingPoint {
 		}
 	}
 
-	// Если нашли лучшее парковочное место, возвращаем его
-	if bestParkingSpot != nil {
-		return bestParkingSpot
-	}
+	return dist
+}
 
-	return nil
+// IsCharger проверяет, есть ли на месте зарядная станция.
+func (s *ParkingPoint) IsCharger() bool {
+	return s.cell.IsCharger()
 }
 
 // ReleaseSpot освобождает занятое парковочное место.
@@ -187,15 +293,31 @@ func (p *ParkingLot) ReleaseSpot(spot *ParkingPoint) {
 type SimulatedCar struct {
 	CarID     string
 	State     string
-	Timer     *timer.Timer
+	Class     VehicleClass
+	Electric  bool // электромобиль
 	Spot      *ParkingPoint
+	Entrance  PathPoint // въезд, через который машина заезжает
+	Exit      PathPoint // выезд, через который машина уезжает
 	EnterTime time.Time
-	Price     float64
+	Energy    float64     // энергия, которую электромобиль хочет получить на зарядной станции, кВт·ч
+	Price     money.Money // полная стоимость: стоянка и зарядка
+	// Multiplier - множитель ставок динамической тарификации на момент заезда.
+	Multiplier float64
+	// Pass - абонемент машины абонента; у разовых посетителей nil.
+	Pass *SeasonPass
+}
+
+// SeasonPass - абонемент на парковку. Абонент не платит за стоянку.
+type SeasonPass struct {
+	ID     int           // номер абонемента
+	Spot   *ParkingPoint // закрепленное место; nil, если место не закреплено
+	OnSite bool          // абонент на парковке или в очереди на въезд
 }
 
 // PathPoint представляет точку на пути
 type PathPoint struct {
-	X, Y int
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 // Path представляет путь от одной точки до другой
@@ -205,7 +327,10 @@ type Path struct {
 	IsValid  bool
 }
 
-// FindPath находит путь от точки A до точки B на парковке (мок функция)
+// FindPath находит кратчайший путь от точки A до точки B на парковке алгоритмом A*.
+//
+// Машина может ехать по дороге, въезду и выезду; начальная и конечная клетки
+// проходимы всегда (например, парковочное место, с которого машина выезжает).
 func (p *ParkingLot) FindPath(fromX, fromY, toX, toY int) *Path {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -222,83 +347,110 @@ func (p *ParkingLot) FindPath(fromX, fromY, toX, toY int) *Path {
 		return &Path{Points: nil, Distance: 0, IsValid: false}
 	}
 
-	// Мок реализация - простой прямой путь
-	path := &Path{
-		Points:   make([]PathPoint, 0),
-		Distance: 0,
-		IsValid:  true,
+	// Направления движения (вверх, вправо, вниз, влево)
+	dx := []int{0, 1, 0, -1}
+	dy := []int{-1, 0, 1, 0}
+
+	// Манхэттенское расстояние до цели - допустимая эвристика для движения по сетке
+	heuristic := func(x, y int) float64 {
+		return math.Abs(float64(x-toX)) + math.Abs(float64(y-toY))
+	}
+
+	dist := make([][]float64, height)
+	prev := make([][]*PathPoint, height)
+	for i := range dist {
+		dist[i] = make([]float64, width)
+		prev[i] = make([]*PathPoint, width)
+		for j := range dist[i] {
+			dist[i][j] = math.Inf(1)
+		}
 	}
 
-	// Добавляем начальную точку
-	path.Points = append(path.Points, PathPoint{X: fromX, Y: fromY})
+	queue := make(priorityQueue, 0)
+	dist[fromX][fromY] = 0
+	queue = append(queue, item{value: heuristic(fromX, fromY), x: fromX, y: fromY})
+	heap.Init(&queue)
 
-	// Простой алгоритм: сначала двигаемся по X, потом по Y
-	currentX, currentY := fromX, fromY
+	for queue.Len() > 0 {
+		current := heap.Pop(&queue).(item)
+		x, y := current.x, current.y
 
-	// Движение по оси X
-	for currentX != toX {
-		if currentX < toX {
-			currentX++
-		} else {
-			currentX--
+		if x == toX && y == toY {
+			return buildPath(prev, toX, toY, dist[toX][toY])
 		}
 
-		// Проверяем, что ячейка проходима
-		cell := p.topology[currentX][currentY].cell
-		if !cell.IsRoad() && !cell.IsExit() && !cell.IsParking() && !cell.IsEntrance() {
-			return &Path{Points: nil, Distance: 0, IsValid: false}
+		// устаревшая запись в очереди
+		if current.value > dist[x][y]+heuristic(x, y) {
+			continue
 		}
 
-		path.Points = append(path.Points, PathPoint{X: currentX, Y: currentY})
-		path.Distance++
-	}
+		for i := 0; i < 4; i++ {
+			nx, ny := x+dx[i], y+dy[i]
 
-	// Движение по оси Y
-	for currentY != toY {
-		if currentY < toY {
-			currentY++
-		} else {
-			currentY--
-		}
+			if nx < 0 || nx >= height || ny < 0 || ny >= width {
+				continue
+			}
 
-		// Проверяем, что ячейка проходима
-		cell := p.topology[currentX][currentY].cell
-		if !cell.IsRoad() && !cell.IsExit() && !cell.IsParking() && !cell.IsEntrance() {
-			return &Path{Points: nil, Distance: 0, IsValid: false}
+			if !(nx == toX && ny == toY) && !p.topology[nx][ny].cell.IsPassable() {
+				continue
+			}
+
+			newDistance := dist[x][y] + 1
+			if newDistance < dist[nx][ny] {
+				dist[nx][ny] = newDistance
+				prev[nx][ny] = &PathPoint{X: x, Y: y}
+				heap.Push(&queue, item{value: newDistance + heuristic(nx, ny), x: nx, y: ny})
+			}
 		}
+	}
+
+	return &Path{Points: nil, Distance: 0, IsValid: false}
+}
 
-		path.Points = append(path.Points, PathPoint{X: currentX, Y: currentY})
-		path.Distance++
+// buildPath восстанавливает путь до (toX, toY) по матрице предыдущих точек.
+func buildPath(prev [][]*PathPoint, toX, toY int, distance float64) *Path {
+	points := []PathPoint{{X: toX, Y: toY}}
+	for point := prev[toX][toY]; point != nil; point = prev[point.X][point.Y] {
+		points = append(points, *point)
 	}
 
-	return path
+	// путь собран от конца к началу
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+
+	return &Path{
+		Points:   points,
+		Distance: distance,
+		IsValid:  true,
+	}
 }
 
-// GetPathToSpot находит путь от входа до указанного парковочного места
-func (p *ParkingLot) GetPathToSpot(spot *ParkingPoint) *Path {
-	return p.FindPath(p.EntryX, p.EntryY, spot.X, spot.Y)
+// GetPathToSpot находит путь от въезда entrance до указанного парковочного места
+func (p *ParkingLot) GetPathToSpot(entrance PathPoint, spot *ParkingPoint) *Path {
+	return p.FindPath(entrance.X, entrance.Y, spot.X, spot.Y)
 }
 
-// GetPathFromSpot находит путь от парковочного места до выхода
+// GetPathFromSpot находит путь от парковочного места до ближайшего по маршруту выхода.
+// Последняя точка пути - выбранный выход.
 func (p *ParkingLot) GetPathFromSpot(spot *ParkingPoint) *Path {
-	// Находим выход
-	exitX, exitY := p.findExit()
-	if exitX == -1 || exitY == -1 {
-		// Если выхода нет, используем вход как выход
-		exitX, exitY = p.EntryX, p.EntryY
+	exits := p.Exits
+	if len(exits) == 0 {
+		// Если выхода нет, используем въезды как выход
+		exits = p.Entrances
 	}
 
-	return p.FindPath(spot.X, spot.Y, exitX, exitY)
-}
-
-// findExit находит координаты выхода
-func (p *ParkingLot) findExit() (int, int) {
-	for x := 0; x < len(p.topology); x++ {
-		for y := 0; y < len(p.topology[x]); y++ {
-			if p.topology[x][y].cell.IsExit() {
-				return x, y
-			}
+	var best *Path
+	for _, exit := range exits {
+		path := p.FindPath(spot.X, spot.Y, exit.X, exit.Y)
+		if path.IsValid && (best == nil || path.Distance < best.Distance) {
+			best = path
 		}
 	}
-	return -1, -1
+
+	if best == nil {
+		return &Path{Points: nil, Distance: 0, IsValid: false}
+	}
+
+	return best
 }
@@ -0,0 +1,109 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLot создает модель парковки с топологией cells.
+func newLot(cells [][]models.ParkingCell) *models.ParkingLot {
+	return models.NewParkingLot(&models.Parking{Cells: cells})
+}
+
+func TestFindPath(t *testing.T) {
+	// до места (2, 0) можно доехать только в объезд декораций
+	detour := [][]models.ParkingCell{
+		{"I", ".", ".", "."},
+		{"D", "D", "D", "."},
+		{"P", ".", ".", "O"},
+	}
+
+	cases := []struct {
+		Name           string
+		Cells          [][]models.ParkingCell
+		From, To       models.PathPoint
+		ExpectedPoints []models.PathPoint
+	}{
+		{
+			Name:  "Around decorations",
+			Cells: detour,
+			From:  models.PathPoint{X: 0, Y: 0},
+			To:    models.PathPoint{X: 2, Y: 0},
+			ExpectedPoints: []models.PathPoint{
+				{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 3},
+				{X: 1, Y: 3}, {X: 2, Y: 3}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 0},
+			},
+		},
+		{
+			Name:  "From parking spot",
+			Cells: detour,
+			From:  models.PathPoint{X: 2, Y: 0},
+			To:    models.PathPoint{X: 2, Y: 3},
+			ExpectedPoints: []models.PathPoint{
+				{X: 2, Y: 0}, {X: 2, Y: 1}, {X: 2, Y: 2}, {X: 2, Y: 3},
+			},
+		},
+		{
+			Name:  "Same cell",
+			Cells: detour,
+			From:  models.PathPoint{X: 2, Y: 0},
+			To:    models.PathPoint{X: 2, Y: 0},
+			ExpectedPoints: []models.PathPoint{
+				{X: 2, Y: 0},
+			},
+		},
+		{
+			Name:  "Blocked by decoration",
+			Cells: [][]models.ParkingCell{{"I", ".", "D", "P"}},
+			From:  models.PathPoint{X: 0, Y: 0},
+			To:    models.PathPoint{X: 0, Y: 3},
+		},
+		{
+			Name:  "Through other parking spot",
+			Cells: [][]models.ParkingCell{{"I", "P", "P"}},
+			From:  models.PathPoint{X: 0, Y: 0},
+			To:    models.PathPoint{X: 0, Y: 2},
+		},
+		{
+			Name:  "Target out of parking",
+			Cells: detour,
+			From:  models.PathPoint{X: 0, Y: 0},
+			To:    models.PathPoint{X: 3, Y: 0},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			path := newLot(tc.Cells).FindPath(tc.From.X, tc.From.Y, tc.To.X, tc.To.Y)
+
+			if tc.ExpectedPoints == nil {
+				assert.False(t, path.IsValid)
+				assert.Empty(t, path.Points)
+				return
+			}
+
+			assert.True(t, path.IsValid)
+			assert.Equal(t, tc.ExpectedPoints, path.Points)
+			assert.Equal(t, float64(len(tc.ExpectedPoints)-1), path.Distance)
+		})
+	}
+}
+
+func TestGetPathFromSpot(t *testing.T) {
+	// машина уезжает через ближайший по маршруту левый выезд
+	lot := newLot([][]models.ParkingCell{
+		{"O", ".", ".", "I", "O"},
+		{"D", "P", "D", "D", "."},
+	})
+
+	spot, ok := lot.OccupySpot(models.SpotRequest{Entrance: lot.Entrances[0], Class: models.Car})
+	require.True(t, ok)
+	path := lot.GetPathFromSpot(spot)
+
+	assert.True(t, path.IsValid)
+	assert.Equal(t, float64(2), path.Distance)
+	assert.Equal(t, models.PathPoint{X: 0, Y: 0}, path.Points[len(path.Points)-1])
+}
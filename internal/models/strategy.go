@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// Названия встроенных стратегий выбора парковочного места.
+const (
+	StrategyNearestEntrance = "nearest-entrance"
+	StrategyNearestExit     = "nearest-exit"
+	StrategyRandom          = "random"
+	StrategyFillFarFirst    = "fill-far-first"
+	StrategyEvenWear        = "even-wear"
+)
+
+// SpotStrategy выбирает свободное парковочное место, подходящее под запрос машины.
+//
+// Choose вызывается под блокировкой парковки и возвращает nil, если подходящего места нет.
+type SpotStrategy interface {
+	Name() string
+	Choose(p *ParkingLot, req SpotRequest) *ParkingPoint
+}
+
+// NewSpotStrategy создает встроенную стратегию по названию.
+// Пустое название означает стратегию по умолчанию (ближайшее к въезду место).
+// rnd используется стратегиями со случайным выбором.
+func NewSpotStrategy(name string, rnd *rand.Rand) (SpotStrategy, error) {
+	switch name {
+	case "", StrategyNearestEntrance:
+		return NearestToEntrance{}, nil
+	case StrategyNearestExit:
+		return NearestToExit{}, nil
+	case StrategyRandom:
+		return &RandomSpot{rnd: rnd}, nil
+	case StrategyFillFarFirst:
+		return FillFarFirst{}, nil
+	case StrategyEvenWear:
+		return EvenWear{}, nil
+	default:
+		return nil, fmt.Errorf("unknown spot strategy %q", name)
+	}
+}
+
+// NearestToEntrance выбирает ближайшее к въезду место.
+type NearestToEntrance struct{}
+
+func (NearestToEntrance) Name() string { return StrategyNearestEntrance }
+
+func (NearestToEntrance) Choose(p *ParkingLot, req SpotRequest) *ParkingPoint {
+	return pickBest(p.freeSpots(req), func(c spotCandidate) float64 { return c.distance })
+}
+
+// NearestToExit выбирает место, от которого ближе всего ехать до выезда.
+type NearestToExit struct{}
+
+func (NearestToExit) Name() string { return StrategyNearestExit }
+
+func (NearestToExit) Choose(p *ParkingLot, req SpotRequest) *ParkingPoint {
+	exits := p.Exits
+	if len(exits) == 0 {
+		exits = p.Entrances
+	}
+
+	toExit := p.distancesFrom(exits)
+
+	return pickBest(p.freeSpots(req), func(c spotCandidate) float64 {
+		return toExit[c.spot.X][c.spot.Y]
+	})
+}
+
+// RandomSpot выбирает случайное свободное место.
+type RandomSpot struct {
+	rnd *rand.Rand
+}
+
+func (*RandomSpot) Name() string { return StrategyRandom }
+
+func (s *RandomSpot) Choose(p *ParkingLot, req SpotRequest) *ParkingPoint {
+	spots := p.freeSpots(req)
+	if len(spots) == 0 {
+		return nil
+	}
+
+	return spots[s.rnd.IntN(len(spots))].spot
+}
+
+// FillFarFirst заполняет парковку начиная с самых дальних от въезда мест.
+type FillFarFirst struct{}
+
+func (FillFarFirst) Name() string { return StrategyFillFarFirst }
+
+func (FillFarFirst) Choose(p *ParkingLot, req SpotRequest) *ParkingPoint {
+	return pickBest(p.freeSpots(req), func(c spotCandidate) float64 { return -c.distance })
+}
+
+// EvenWear равномерно распределяет нагрузку: выбирает место, которое занимали реже всего,
+// а среди них - ближайшее к въезду.
+type EvenWear struct{}
+
+func (EvenWear) Name() string { return StrategyEvenWear }
+
+func (EvenWear) Choose(p *ParkingLot, req SpotRequest) *ParkingPoint {
+	return pickBest(p.freeSpots(req), func(c spotCandidate) float64 {
+		// расстояние по сетке всегда меньше количества клеток, поэтому не перебивает число занятий
+		return float64(c.spot.uses)*float64(p.cellCount()) + c.distance
+	})
+}
+
+// pickBest выбирает место с наименьшей оценкой score. При равенстве побеждает место,
+// раньше встретившееся при обходе топологии.
+func pickBest(spots []spotCandidate, score func(spotCandidate) float64) *ParkingPoint {
+	var best *ParkingPoint
+	bestScore := math.Inf(1)
+
+	for _, c := range spots {
+		if sc := score(c); sc < bestScore {
+			bestScore = sc
+			best = c.spot
+		}
+	}
+
+	return best
+}
+
+// cellCount возвращает количество клеток топологии.
+func (p *ParkingLot) cellCount() int {
+	count := 0
+	for _, row := range p.topology {
+		count += len(row)
+	}
+
+	return count
+}
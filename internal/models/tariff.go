@@ -0,0 +1,44 @@
+package models
+
+import "github.com/PIRSON21/parking/internal/lib/money"
+
+// Типы дней, для которых задаются тарифные периоды.
+const (
+	Weekday = "weekday" // будний день
+	Weekend = "weekend" // выходной (суббота и воскресенье)
+	Holiday = "holiday" // праздник из списка праздников парковки
+)
+
+// Формат времени начала тарифного периода и даты праздника.
+const (
+	TariffStartLayout = "15:04"
+	HolidayLayout     = "2006-01-02"
+)
+
+// TariffPeriod - тарифный период парковки: почасовая ставка Rate действует для дней типа DayType
+// с времени Start до начала следующего периода.
+type TariffPeriod struct {
+	Name    string       `json:"name,omitempty" validate:"omitempty,max=30"`
+	DayType string       `json:"day_type" validate:"required,oneof=weekday weekend holiday"`
+	Start   string       `json:"start" validate:"required,datetime=15:04"`
+	Rate    *money.Money `json:"rate" validate:"required,gte=0,lte=100000"`
+}
+
+// Единицы тарификации стоянки.
+const (
+	BillingExact   = "exact"   // пропорционально времени стоянки
+	BillingQuarter = "quarter" // за каждые начатые 15 минут
+	BillingHour    = "hour"    // за каждый начатый час
+)
+
+// TariffRules - правила тарификации стоянки поверх тарифных периодов.
+type TariffRules struct {
+	// GracePeriod - бесплатные минуты: стоянка не дольше них не оплачивается.
+	GracePeriod int `json:"grace_period,omitempty" validate:"gte=0,lte=1440"`
+	// BillingUnit - единица тарификации ("exact" по умолчанию, "quarter", "hour").
+	BillingUnit string `json:"billing_unit,omitempty" validate:"omitempty,oneof=exact quarter hour"`
+	// DailyCap - максимальная плата за календарные сутки (в копейках).
+	DailyCap *money.Money `json:"daily_cap,omitempty" validate:"omitempty,gte=0,lte=10000000"`
+	// MinCharge - минимальная плата за платную стоянку (в копейках).
+	MinCharge *money.Money `json:"min_charge,omitempty" validate:"omitempty,gte=0,lte=10000000"`
+}
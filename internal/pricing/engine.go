@@ -0,0 +1,145 @@
+package pricing
+
+import (
+	"math"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/lib/money"
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// Длительность блоков тарификации.
+const (
+	quarterBlock = 15 * time.Minute
+	hourBlock    = time.Hour
+)
+
+// Quote - расчет стоимости одной стоянки.
+type Quote struct {
+	Price     money.Money `json:"price"`                // итоговая стоимость
+	Minutes   int         `json:"minutes"`              // длительность стоянки в начатых минутах
+	Free      bool        `json:"free,omitempty"`       // стоянка уложилась в бесплатные минуты
+	Capped    bool        `json:"capped,omitempty"`     // плата хотя бы за одни сутки ограничена максимумом
+	MinCharge bool        `json:"min_charge,omitempty"` // начислена минимальная плата
+}
+
+// Engine рассчитывает стоимость стоянки по тарифным периодам и правилам тарификации парковки.
+type Engine struct {
+	schedule   *Schedule
+	rules      models.TariffRules
+	currency   string  // валюта парковки
+	multiplier float64 // множитель почасовых ставок (динамическая тарификация)
+}
+
+// NewEngine создает движок тарификации парковки parking.
+// Без правил тарификации стоянка оплачивается пропорционально времени.
+func NewEngine(parking *models.Parking) *Engine {
+	e := &Engine{schedule: NewSchedule(parking), currency: parking.Currency(), multiplier: 1}
+	if parking.TariffRules != nil {
+		e.rules = *parking.TariffRules
+	}
+
+	return e
+}
+
+// WithMultiplier возвращает движок, в котором все почасовые ставки умножены на multiplier.
+// Максимум за сутки и минимальная плата не меняются.
+func (e *Engine) WithMultiplier(multiplier float64) *Engine {
+	scaled := *e
+	scaled.multiplier = multiplier
+
+	return &scaled
+}
+
+// Rate возвращает почасовую ставку, действующую в момент t.
+func (e *Engine) Rate(t time.Time) money.Money {
+	return money.New(money.Round(e.schedule.RateAt(t)*e.multiplier), e.currency)
+}
+
+// Cost возвращает стоимость стоянки с entered по left.
+func (e *Engine) Cost(entered, left time.Time) money.Money {
+	return e.Quote(entered, left).Price
+}
+
+// Quote рассчитывает стоимость стоянки с entered по left.
+//
+// Стоянка не дольше бесплатных минут не оплачивается, иначе оплачивается целиком.
+// При тарификации блоками каждый начатый блок оплачивается по ставке, действующей в момент его начала.
+// Плата за календарные сутки парковки (по началу участка или блока) ограничивается максимумом,
+// а итог поднимается до минимальной платы. Стоимость считается в дробных копейках
+// и округляется до копейки один раз, в конце расчета.
+func (e *Engine) Quote(entered, left time.Time) Quote {
+	quote := Quote{Price: money.New(0, e.currency)}
+	if !left.After(entered) {
+		quote.Free = true
+		return quote
+	}
+
+	stay := left.Sub(entered)
+	quote.Minutes = int(math.Ceil(stay.Minutes()))
+
+	if stay <= time.Duration(e.rules.GracePeriod)*time.Minute {
+		quote.Free = true
+		return quote
+	}
+
+	var day string
+	var dayCost, total float64
+
+	// closeDay добавляет к итогу плату за прошедшие сутки с учетом максимума
+	closeDay := func() {
+		if e.rules.DailyCap != nil && dayCost > float64(e.rules.DailyCap.Amount) {
+			dayCost = float64(e.rules.DailyCap.Amount)
+			quote.Capped = true
+		}
+		total += dayCost
+		dayCost = 0
+	}
+
+	e.charges(entered, left, func(start time.Time, cost float64) {
+		if date := start.In(e.schedule.Location()).Format(models.HolidayLayout); date != day {
+			closeDay()
+			day = date
+		}
+		dayCost += cost
+	})
+	closeDay()
+
+	if e.rules.MinCharge != nil && total < float64(e.rules.MinCharge.Amount) {
+		total = float64(e.rules.MinCharge.Amount)
+		quote.MinCharge = true
+	}
+	quote.Price.Amount = money.Round(total)
+
+	return quote
+}
+
+// charges разбивает стоянку с entered по left на оплачиваемые участки и передает
+// в charge начало и стоимость каждого из них по порядку.
+func (e *Engine) charges(entered, left time.Time, charge func(start time.Time, cost float64)) {
+	var block time.Duration
+	switch e.rules.BillingUnit {
+	case models.BillingQuarter:
+		block = quarterBlock
+	case models.BillingHour:
+		block = hourBlock
+	}
+
+	if block > 0 {
+		for start := entered; start.Before(left); start = start.Add(block) {
+			charge(start, block.Hours()*e.schedule.RateAt(start)*e.multiplier)
+		}
+		return
+	}
+
+	for start := entered; start.Before(left); {
+		// участки не пересекают смену тарифа и полночь
+		end := e.schedule.NextChange(start)
+		if left.Before(end) {
+			end = left
+		}
+		charge(start, end.Sub(start).Hours()*e.schedule.RateAt(start)*e.multiplier)
+
+		start = end
+	}
+}
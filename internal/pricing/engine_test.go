@@ -0,0 +1,263 @@
+package pricing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/lib/money"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/PIRSON21/parking/internal/pricing"
+	"github.com/stretchr/testify/assert"
+)
+
+// at возвращает момент января 2025 года. 8 января - среда, 11 января - суббота.
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, time.January, day, hour, minute, 0, 0, time.UTC)
+}
+
+// rub возвращает сумму amount копеек в рублях.
+func rub(amount int64) money.Money {
+	return money.New(amount, money.DefaultCurrency)
+}
+
+func TestEngineQuote(t *testing.T) {
+	weekendPeriods := []models.TariffPeriod{
+		{Name: "Выходной", DayType: models.Weekend, Start: "08:00", Rate: test.NewMoney(3000)},
+	}
+
+	cases := []struct {
+		Name string
+		// Rules - правила тарификации парковки. Может быть nil
+		Rules *models.TariffRules
+		// Periods, Holidays - тарифные периоды и праздники. Без периодов днем (06:00-22:00) ставка 100 руб., ночью 20 руб.
+		Periods  []models.TariffPeriod
+		Holidays []string
+		// TimeZone - часовой пояс парковки. По умолчанию UTC
+		TimeZone string
+		Entered  time.Time
+		Left     time.Time
+		Expected pricing.Quote
+	}{
+		{
+			Name:     "Exact within day",
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 11, 30),
+			Expected: pricing.Quote{Price: rub(15000), Minutes: 90},
+		},
+		{
+			Name:     "Exact day/night crossing",
+			Entered:  at(8, 21, 0),
+			Left:     at(8, 23, 0),
+			Expected: pricing.Quote{Price: rub(12000), Minutes: 120},
+		},
+		{
+			Name:     "Exact midnight crossing",
+			Entered:  at(8, 23, 0),
+			Left:     at(9, 1, 0),
+			Expected: pricing.Quote{Price: rub(4000), Minutes: 120},
+		},
+		{
+			Name:     "Exact overnight stay",
+			Entered:  at(8, 20, 0),
+			Left:     at(9, 8, 0),
+			Expected: pricing.Quote{Price: rub(56000), Minutes: 720},
+		},
+		{
+			Name:     "Empty stay",
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 10, 0),
+			Expected: pricing.Quote{Free: true},
+		},
+		{
+			Name:     "Grace period is free",
+			Rules:    &models.TariffRules{GracePeriod: 15},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 10, 15),
+			Expected: pricing.Quote{Minutes: 15, Free: true},
+		},
+		{
+			Name:     "Grace period exceeded bills whole stay",
+			Rules:    &models.TariffRules{GracePeriod: 15, BillingUnit: models.BillingHour},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 10, 16),
+			Expected: pricing.Quote{Price: rub(10000), Minutes: 16},
+		},
+		{
+			Name:     "Per started hour",
+			Rules:    &models.TariffRules{BillingUnit: models.BillingHour},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 11, 1),
+			Expected: pricing.Quote{Price: rub(20000), Minutes: 61},
+		},
+		{
+			Name:     "Started hour before night is billed by day rate",
+			Rules:    &models.TariffRules{BillingUnit: models.BillingHour},
+			Entered:  at(8, 21, 30),
+			Left:     at(8, 22, 10),
+			Expected: pricing.Quote{Price: rub(10000), Minutes: 40},
+		},
+		{
+			Name:     "Per started hour day/night crossing",
+			Rules:    &models.TariffRules{BillingUnit: models.BillingHour},
+			Entered:  at(8, 21, 30),
+			Left:     at(8, 22, 40),
+			Expected: pricing.Quote{Price: rub(12000), Minutes: 70},
+		},
+		{
+			Name:     "Per started quarter",
+			Rules:    &models.TariffRules{BillingUnit: models.BillingQuarter},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 10, 20),
+			Expected: pricing.Quote{Price: rub(5000), Minutes: 20},
+		},
+		{
+			Name:     "Per started quarter midnight crossing",
+			Rules:    &models.TariffRules{BillingUnit: models.BillingQuarter},
+			Entered:  at(8, 23, 50),
+			Left:     at(9, 0, 10),
+			Expected: pricing.Quote{Price: rub(1000), Minutes: 20},
+		},
+		{
+			Name:     "Daily cap for each calendar day",
+			Rules:    &models.TariffRules{DailyCap: test.NewMoney(50000)},
+			Entered:  at(8, 8, 0),
+			Left:     at(9, 8, 0),
+			Expected: pricing.Quote{Price: rub(82000), Minutes: 1440, Capped: true},
+		},
+		{
+			Name:     "Daily cap midnight crossing",
+			Rules:    &models.TariffRules{DailyCap: test.NewMoney(5000)},
+			Entered:  at(8, 21, 0),
+			Left:     at(9, 1, 0),
+			Expected: pricing.Quote{Price: rub(7000), Minutes: 240, Capped: true},
+		},
+		{
+			Name:     "Daily cap not reached",
+			Rules:    &models.TariffRules{DailyCap: test.NewMoney(50000), BillingUnit: models.BillingHour},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 12, 0),
+			Expected: pricing.Quote{Price: rub(20000), Minutes: 120},
+		},
+		{
+			Name:     "Minimum charge",
+			Rules:    &models.TariffRules{MinCharge: test.NewMoney(5000)},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 10, 6),
+			Expected: pricing.Quote{Price: rub(5000), Minutes: 6, MinCharge: true},
+		},
+		{
+			Name:     "Price is rounded to kopecks once",
+			Periods:  []models.TariffPeriod{{DayType: models.Weekday, Start: "00:00", Rate: test.NewMoney(1250)}},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 10, 7),
+			Expected: pricing.Quote{Price: rub(146), Minutes: 7},
+		},
+		{
+			Name:     "Parking time zone",
+			TimeZone: "Asia/Vladivostok",
+			Entered:  at(8, 11, 0),
+			Left:     at(8, 13, 0),
+			Expected: pricing.Quote{Price: rub(12000), Minutes: 120},
+		},
+		{
+			Name:     "Daily cap by parking calendar day",
+			Rules:    &models.TariffRules{DailyCap: test.NewMoney(5000)},
+			TimeZone: "Asia/Vladivostok",
+			Entered:  at(8, 11, 0),
+			Left:     at(8, 15, 0),
+			Expected: pricing.Quote{Price: rub(7000), Minutes: 240, Capped: true},
+		},
+		{
+			Name:     "Spring forward",
+			TimeZone: "Europe/Berlin",
+			Entered:  time.Date(2025, time.March, 30, 0, 0, 0, 0, time.UTC),
+			Left:     time.Date(2025, time.March, 30, 5, 0, 0, 0, time.UTC),
+			Expected: pricing.Quote{Price: rub(18000), Minutes: 300},
+		},
+		{
+			Name: "Period start skipped by spring forward",
+			Periods: []models.TariffPeriod{
+				{DayType: models.Weekday, Start: "00:00", Rate: test.NewMoney(2000)},
+				{DayType: models.Weekday, Start: "02:30", Rate: test.NewMoney(10000)},
+			},
+			TimeZone: "Europe/Berlin",
+			Entered:  time.Date(2025, time.March, 30, 0, 0, 0, 0, time.UTC),
+			Left:     time.Date(2025, time.March, 30, 2, 0, 0, 0, time.UTC),
+			Expected: pricing.Quote{Price: rub(12000), Minutes: 120},
+		},
+		{
+			Name:     "Fall back",
+			TimeZone: "Europe/Berlin",
+			Entered:  time.Date(2025, time.October, 26, 0, 0, 0, 0, time.UTC),
+			Left:     time.Date(2025, time.October, 26, 5, 0, 0, 0, time.UTC),
+			Expected: pricing.Quote{Price: rub(10000), Minutes: 300},
+		},
+		{
+			Name:     "Minimum charge is not applied to grace period",
+			Rules:    &models.TariffRules{GracePeriod: 10, MinCharge: test.NewMoney(5000)},
+			Entered:  at(8, 10, 0),
+			Left:     at(8, 10, 5),
+			Expected: pricing.Quote{Minutes: 5, Free: true},
+		},
+		{
+			Name:     "Weekend period",
+			Periods:  weekendPeriods,
+			Entered:  at(11, 10, 0),
+			Left:     at(11, 12, 0),
+			Expected: pricing.Quote{Price: rub(6000), Minutes: 120},
+		},
+		{
+			Name:     "Weekday night lasts until first weekend period",
+			Periods:  weekendPeriods,
+			Entered:  at(10, 21, 0),
+			Left:     at(11, 9, 0),
+			Expected: pricing.Quote{Price: rub(33000), Minutes: 720},
+		},
+		{
+			Name:     "Holiday without periods uses weekend periods",
+			Periods:  weekendPeriods,
+			Holidays: []string{"2025-01-07"},
+			Entered:  at(7, 10, 0),
+			Left:     at(7, 12, 0),
+			Expected: pricing.Quote{Price: rub(6000), Minutes: 120},
+		},
+		{
+			Name: "Holiday periods",
+			Periods: append([]models.TariffPeriod{
+				{Name: "Праздник", DayType: models.Holiday, Start: "00:00", Rate: test.NewMoney(0)},
+			}, weekendPeriods...),
+			Holidays: []string{"2025-01-07"},
+			Entered:  at(6, 23, 0),
+			Left:     at(7, 12, 0),
+			Expected: pricing.Quote{Price: rub(2000), Minutes: 780},
+		},
+	}
+
+	t.Parallel()
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			parking := &models.Parking{
+				DayTariff:     test.NewMoney(10000),
+				NightTariff:   test.NewMoney(2000),
+				TariffPeriods: tc.Periods,
+				TariffRules:   tc.Rules,
+				Holidays:      tc.Holidays,
+				TimeZone:      tc.TimeZone,
+			}
+			if parking.TimeZone == "" {
+				parking.TimeZone = "UTC"
+			}
+
+			quote := pricing.NewEngine(parking).Quote(tc.Entered, tc.Left)
+
+			assert.Equal(t, tc.Expected.Price.Amount, quote.Price.Amount)
+			assert.Equal(t, money.DefaultCurrency, quote.Price.Currency)
+			assert.Equal(t, tc.Expected.Minutes, quote.Minutes)
+			assert.Equal(t, tc.Expected.Free, quote.Free)
+			assert.Equal(t, tc.Expected.Capped, quote.Capped)
+			assert.Equal(t, tc.Expected.MinCharge, quote.MinCharge)
+		})
+	}
+}
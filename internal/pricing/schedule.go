@@ -0,0 +1,170 @@
+package pricing
+
+import (
+	"slices"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// Границы дневного и ночного тарифов парковки без настроенных периодов.
+const (
+	defaultDayStart   = 6 * 60  // 06:00
+	defaultNightStart = 22 * 60 // 22:00
+)
+
+// periodStart - начало тарифного периода в минутах от начала суток.
+type periodStart struct {
+	minute int
+	rate   float64 // ставка в копейках за час
+}
+
+// Schedule - расписание почасовых ставок парковки по типам дней.
+//
+// Время начала периодов, типы дней и праздники определяются в часовом поясе парковки.
+// Если для праздника нет своих периодов, действуют периоды выходного, а для выходного - будние.
+// До начала первого периода дня действует последний период предыдущего дня.
+type Schedule struct {
+	periods  map[string][]periodStart // периоды по типам дней в порядке начала
+	holidays map[string]struct{}      // даты праздников в формате models.HolidayLayout
+	loc      *time.Location           // часовой пояс парковки
+}
+
+// NewSchedule создает расписание ставок парковки.
+//
+// Если у парковки нет будних тарифных периодов, будни делятся на дневной тариф с 06:00 и ночной с 22:00.
+func NewSchedule(parking *models.Parking) *Schedule {
+	s := &Schedule{
+		periods:  make(map[string][]periodStart),
+		holidays: make(map[string]struct{}, len(parking.Holidays)),
+		loc:      parking.Location(),
+	}
+
+	for _, period := range parking.TariffPeriods {
+		start, err := time.Parse(models.TariffStartLayout, period.Start)
+		if err != nil || period.Rate == nil {
+			continue
+		}
+
+		s.periods[period.DayType] = append(s.periods[period.DayType], periodStart{
+			minute: start.Hour()*60 + start.Minute(),
+			rate:   float64(period.Rate.Amount),
+		})
+	}
+
+	if len(s.periods[models.Weekday]) == 0 {
+		var day, night float64
+		if parking.DayTariff != nil {
+			day = float64(parking.DayTariff.Amount)
+		}
+		if parking.NightTariff != nil {
+			night = float64(parking.NightTariff.Amount)
+		}
+
+		s.periods[models.Weekday] = []periodStart{
+			{minute: defaultDayStart, rate: day},
+			{minute: defaultNightStart, rate: night},
+		}
+	}
+
+	for _, starts := range s.periods {
+		slices.SortFunc(starts, func(a, b periodStart) int { return a.minute - b.minute })
+	}
+
+	for _, date := range parking.Holidays {
+		s.holidays[date] = struct{}{}
+	}
+
+	return s
+}
+
+// Location возвращает часовой пояс парковки.
+func (s *Schedule) Location() *time.Location {
+	return s.loc
+}
+
+// DayType возвращает тип дня, в который попадает момент t.
+func (s *Schedule) DayType(t time.Time) string {
+	t = t.In(s.loc)
+	if _, ok := s.holidays[t.Format(models.HolidayLayout)]; ok {
+		return models.Holiday
+	}
+
+	if weekday := t.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return models.Weekend
+	}
+
+	return models.Weekday
+}
+
+// RateAt возвращает почасовую ставку в копейках, действующую в момент t.
+func (s *Schedule) RateAt(t time.Time) float64 {
+	t = t.In(s.loc)
+	minute := t.Hour()*60 + t.Minute()
+
+	starts := s.dayPeriods(t)
+	for i := len(starts) - 1; i >= 0; i-- {
+		if starts[i].minute <= minute {
+			return starts[i].rate
+		}
+	}
+
+	// до первого периода дня действует последний период предыдущего дня
+	prev := s.dayPeriods(t.AddDate(0, 0, -1))
+	return prev[len(prev)-1].rate
+}
+
+// NextChange возвращает ближайший после t момент, в который ставка может смениться:
+// начало следующего периода или начало следующих суток (у них может быть другой тип дня).
+//
+// Если начало периода пропущено при переводе часов вперед, ставка меняется в момент перевода.
+func (s *Schedule) NextChange(t time.Time) time.Time {
+	t = t.In(s.loc)
+	minute := t.Hour()*60 + t.Minute()
+
+	for _, start := range s.dayPeriods(t) {
+		if start.minute <= minute {
+			continue
+		}
+		// при переводе часов момент начала может оказаться не позже t
+		if next := s.localTime(t.Year(), t.Month(), t.Day(), start.minute); next.After(t) {
+			return next
+		}
+	}
+
+	return s.localTime(t.Year(), t.Month(), t.Day()+1, 0)
+}
+
+// localTime возвращает момент, когда в часовом поясе парковки наступает minute-я минута суток.
+// Если такого времени нет из-за перевода часов вперед, возвращается момент перевода.
+func (s *Schedule) localTime(year int, month time.Month, day, minute int) time.Time {
+	t := time.Date(year, month, day, minute/60, minute%60, 0, 0, s.loc)
+	if got := t.Hour()*60 + t.Minute(); got != minute {
+		start, end := t.ZoneBounds()
+		if got > minute {
+			return start
+		}
+		return end
+	}
+
+	return t
+}
+
+// dayPeriods возвращает периоды дня, в который попадает момент t, с учетом замены
+// праздника на выходной и выходного на будни.
+func (s *Schedule) dayPeriods(t time.Time) []periodStart {
+	t = t.In(s.loc)
+	switch s.DayType(t) {
+	case models.Holiday:
+		if starts := s.periods[models.Holiday]; len(starts) > 0 {
+			return starts
+		}
+		fallthrough
+	case models.Weekend:
+		if starts := s.periods[models.Weekend]; len(starts) > 0 {
+			return starts
+		}
+	}
+
+	return s.periods[models.Weekday]
+}
@@ -0,0 +1,61 @@
+package simulation
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunBatch прогоняет симуляцию без клиента так быстро, как позволяет процессор,
+// и возвращает итоговую статистику.
+//
+// Машины заезжают на парковку сразу по прибытии. Прогон прерывается при отмене ctx.
+func RunBatch(ctx context.Context, params *BatchParams, log *slog.Logger) (*Report, error) {
+	return runBatch(ctx, params, nil, log)
+}
+
+// StreamBatch прогоняет симуляцию так же, как RunBatch, и по ходу прогона пишет
+// события машин и замеры загрузки в exporter.
+func StreamBatch(ctx context.Context, params *BatchParams, exporter *Exporter, log *slog.Logger) error {
+	if _, err := runBatch(ctx, params, exporter, log); err != nil {
+		return err
+	}
+
+	return exporter.Flush()
+}
+
+// runBatch прогоняет симуляцию без клиента. Если задан exporter, события и замеры выгружаются в него.
+func runBatch(ctx context.Context, params *BatchParams, exporter *Exporter, log *slog.Logger) (*Report, error) {
+	ss := NewSession(nil, &params.InitParams, InstantPacer{}, log)
+	ss.exporter = exporter
+	ss.autoPark = true
+	ss.statsInterval = 0
+	ss.horizon = ss.clock.StartTime().Add(time.Duration(params.Duration) * time.Minute)
+
+	ss.sampleInterval = time.Duration(params.SampleInterval) * time.Minute
+	if ss.sampleInterval == 0 {
+		ss.sampleInterval = defaultSampleInterval * time.Minute
+	}
+
+	stop := context.AfterFunc(ctx, ss.Stop)
+	defer stop()
+
+	ss.mu.Lock()
+	ss.begin()
+	ss.mu.Unlock()
+
+	ss.run()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	report := ss.stats.report(ss.clock.StartTime().Unix(), ss.horizon.Unix(), ss.parking.Capacity())
+	report.Seed = ss.seed
+	report.Strategy = ss.strategy
+
+	return report, nil
+}
@@ -0,0 +1,288 @@
+package simulation
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+)
+
+// subscriberBuffer - сколько сообщений может ждать отправки одному получателю.
+const subscriberBuffer = 256
+
+// subscriber - получатель событий сессии со своей очередью отправки.
+//
+// Каждому получателю события отправляет отдельная горутина, поэтому медленное
+// или оборванное соединение не задерживает остальных: если его очередь заполнена, сообщения для него теряются.
+// Исключение - управляющий клиент и Recorder: их ждет цикл отправки, а с ним и симуляция.
+type subscriber struct {
+	sender   EventSender
+	queue    chan []byte
+	done     chan struct{} // закрывается, когда получатель отключается от сессии
+	lossless bool          // сообщения не теряются: цикл отправки ждет места в очереди
+}
+
+// joinEvent - подключение получателя к идущей сессии. Проходит через цикл отправки вместе с событиями,
+// чтобы получатель получил снимок состояния и только следующие за ним события.
+type joinEvent struct {
+	sub      *subscriber
+	snapshot *Snapshot
+}
+
+// newSubscriber создает получателя событий. Сообщения управляющему клиенту controller не теряются:
+// иначе после перемотки его карта разойдется с сессией.
+func newSubscriber(sender EventSender, controller bool) *subscriber {
+	// Recorder не обращается к сети при получении события, поэтому ждать его можно
+	_, recorder := sender.(*Recorder)
+
+	return &subscriber{
+		sender:   sender,
+		queue:    make(chan []byte, subscriberBuffer),
+		done:     make(chan struct{}),
+		lossless: recorder || controller,
+	}
+}
+
+// deliver ставит сообщение в очередь получателя. Вернет false, если очередь заполнена.
+func (s *subscriber) deliver(data []byte) bool {
+	if s.lossless {
+		select {
+		case s.queue <- data:
+			return true
+		case <-s.done:
+			return false
+		}
+	}
+
+	select {
+	case s.queue <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// closed проверяет, отключился ли получатель.
+func (s *subscriber) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeLoop отправляет получателю сообщения из его очереди, пока он не отключится.
+// Когда цикл отправки сессии завершится (закроется sent), отправляет оставшиеся сообщения и выходит.
+func (s *subscriber) writeLoop(sent <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case data := <-s.queue:
+			s.sender.Send(data)
+		case <-s.done:
+			return
+		case <-sent:
+			for {
+				select {
+				case data := <-s.queue:
+					s.sender.Send(data)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Attach подключает управляющего клиента к сессии: клиент получает снимок состояния сессии, а затем ее события.
+// Если симуляцию приостановили из-за отключения клиента, она продолжается.
+func (ss *Session) Attach(client EventSender) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.pausedByDetach && ss.state == statePaused {
+		ss.state = stateRunning
+		ss.resumeClock()
+		ss.notify()
+	}
+	ss.pausedByDetach = false
+
+	if ss.controller != nil {
+		ss.unsubscribe(ss.controller)
+	}
+	ss.controller = client
+	ss.join(client)
+
+	ss.log.Info("client attached", slog.String("state", ss.state), slog.Time("sim_time", ss.clock.Now()))
+}
+
+// Detach отключает управляющего клиента от сессии. Идущая симуляция приостанавливается до его возвращения,
+// наблюдатели продолжают получать события.
+func (ss *Session) Detach() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.controller != nil {
+		ss.unsubscribe(ss.controller)
+		ss.controller = nil
+	}
+
+	if ss.state == stateRunning {
+		ss.state = statePaused
+		ss.pausedByDetach = true
+		ss.clock.Freeze()
+		ss.notify()
+	}
+
+	ss.log.Info("client detached", slog.String("state", ss.state), slog.Time("sim_time", ss.clock.Now()))
+}
+
+// AddObserver подключает к сессии наблюдателя: он получает снимок состояния сессии, а затем ее события,
+// но не управляет симуляцией.
+func (ss *Session) AddObserver(observer EventSender) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.join(observer)
+
+	ss.log.Info("observer joined", slog.Int("subscribers", len(ss.subscribers)))
+}
+
+// RemoveObserver отключает наблюдателя от сессии.
+func (ss *Session) RemoveObserver(observer EventSender) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.unsubscribe(observer)
+
+	ss.log.Info("observer left", slog.Int("subscribers", len(ss.subscribers)))
+}
+
+// join подписывает получателя на события и отправляет ему снимок состояния сессии. Вызывается под ss.mu.
+func (ss *Session) join(sender EventSender) {
+	sub := ss.subscribe(sender)
+	snap := ss.snapshot()
+
+	if ss.started && ss.ctx.Err() == nil {
+		// подключение идет через цикл отправки, чтобы снимок не обогнал уже отправленные события
+		select {
+		case ss.eventChan <- joinEvent{sub: sub, snapshot: snap}:
+		case <-ss.ctx.Done():
+		}
+		return
+	}
+
+	if data, err := json.Marshal(snap); err == nil {
+		sub.deliver(data)
+	}
+}
+
+// subscribe добавляет получателя событий. До запуска сессии он сразу попадает в рассылку,
+// после запуска - через joinEvent. Вызывается под ss.mu.
+func (ss *Session) subscribe(sender EventSender) *subscriber {
+	ss.unsubscribe(sender)
+
+	sub := newSubscriber(sender, sender == ss.controller)
+	ss.subscribers[sender] = sub
+
+	// остановленной сессии отправлять нечего
+	if ss.ctx.Err() == nil {
+		ss.loops.Add(1)
+		go sub.writeLoop(ss.sent, &ss.loops)
+	}
+
+	return sub
+}
+
+// unsubscribe отключает получателя событий. Вызывается под ss.mu.
+func (ss *Session) unsubscribe(sender EventSender) {
+	sub, ok := ss.subscribers[sender]
+	if !ok {
+		return
+	}
+
+	delete(ss.subscribers, sender)
+	close(sub.done)
+}
+
+// targets возвращает получателей событий для запуска цикла отправки. Вызывается под ss.mu.
+func (ss *Session) targets() []*subscriber {
+	targets := make([]*subscriber, 0, len(ss.subscribers))
+	for _, sub := range ss.subscribers {
+		targets = append(targets, sub)
+	}
+
+	return targets
+}
+
+// send передает событие в цикл отправки, сохраняя порядок событий. Вызывается под ss.mu.
+func (ss *Session) send(event interface{}) {
+	if len(ss.subscribers) == 0 {
+		return
+	}
+
+	select {
+	case ss.eventChan <- event:
+	case <-ss.ctx.Done():
+	}
+}
+
+// eventLoop рассылает события сессии всем получателям targets и подключившимся позже.
+// После остановки сессии рассылает события, поставленные в очередь до нее (например, итоговую сводку).
+func (ss *Session) eventLoop(targets []*subscriber) {
+	defer ss.closeSent()
+
+	for {
+		select {
+		case event, ok := <-ss.eventChan:
+			if !ok {
+				ss.log.Debug("event channel closed, stopping event loop")
+				return
+			}
+
+			targets = ss.dispatch(targets, event)
+		case <-ss.ctx.Done():
+			for {
+				select {
+				case event := <-ss.eventChan:
+					targets = ss.dispatch(targets, event)
+				default:
+					ss.log.Debug("session stopped, stopping event loop")
+					return
+				}
+			}
+		}
+	}
+}
+
+// dispatch рассылает событие получателям targets и возвращает обновленный список получателей.
+func (ss *Session) dispatch(targets []*subscriber, event interface{}) []*subscriber {
+	if join, ok := event.(joinEvent); ok {
+		if data, err := json.Marshal(join.snapshot); err == nil {
+			join.sub.deliver(data)
+		}
+		return append(targets, join.sub)
+	}
+
+	data, err := json.Marshal(&event)
+	if err != nil {
+		ss.log.Error("error while marshaling event", slog.String("err", err.Error()))
+		return targets
+	}
+
+	ss.log.Debug("sending event", "event", event)
+
+	active := targets[:0]
+	for _, sub := range targets {
+		if sub.closed() {
+			continue
+		}
+		if !sub.deliver(data) {
+			ss.log.Warn("subscriber is too slow, event dropped")
+		}
+		active = append(active, sub)
+	}
+
+	return active
+}
@@ -0,0 +1,59 @@
+package simulation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBroadcastSlowSubscriber проверяет, что зависший наблюдатель не задерживает остальных получателей:
+// они получают все события, даже когда его очередь переполнена, а подключившийся к идущей сессии
+// наблюдатель сначала получает снимок и затем только следующие за ним события.
+func TestBroadcastSlowSubscriber(t *testing.T) {
+	early := &recordingSender{}
+	session := NewSession(early, newTestParams(), NewRealTimePacer(DefaultSpeed*300), slogdiscard.NewDiscardLogger())
+
+	blocked := &blockingSender{release: make(chan struct{})}
+	session.AddObserver(blocked)
+	require.NoError(t, session.Start())
+
+	// машины приезжают каждую минуту и ждут команды "park", поэтому все приехавшие есть в снимке
+	require.Eventually(t, func() bool { return early.count(eventArrive) >= 10 }, waitTimeout, 5*time.Millisecond)
+	late := &recordingSender{}
+	session.AddObserver(late)
+
+	// событий больше, чем вмещает очередь зависшего наблюдателя
+	require.Eventually(t, func() bool { return late.count(eventArrive) > subscriberBuffer }, waitTimeout, 5*time.Millisecond)
+
+	session.Stop()
+	close(blocked.release)
+	<-session.Flushed()
+
+	var summary StatsEvent
+	early.waitFor(t, isEvent(eventSummary))
+	require.NoError(t, json.Unmarshal(early.message(len(early.events())-1), &summary))
+	require.Equal(t, eventSummary, summary.Event)
+	assert.Equal(t, summary.Arrivals, early.count(eventArrive))
+
+	events := late.events()
+	require.Equal(t, eventSnapshot, events[0].Event)
+	assert.Equal(t, eventSummary, events[len(events)-1].Event)
+
+	var snapshot Snapshot
+	require.NoError(t, json.Unmarshal(late.message(0), &snapshot))
+	assert.Equal(t, summary.Arrivals-len(snapshot.Cars), late.count(eventArrive))
+
+	inSnapshot := make(map[string]bool, len(snapshot.Cars))
+	for _, car := range snapshot.Cars {
+		inSnapshot[car.CarID] = true
+	}
+	for _, event := range events[1:] {
+		if event.Event == eventArrive {
+			assert.False(t, inSnapshot[event.CarID], "car %s arrived before snapshot", event.CarID)
+		}
+	}
+}
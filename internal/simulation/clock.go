@@ -0,0 +1,137 @@
+package simulation
+
+import (
+	"time"
+)
+
+// DefaultSpeed - скорость симуляции по умолчанию: одна модельная минута за одну реальную секунду.
+const DefaultSpeed = 60
+
+// Границы множителя скорости относительно DefaultSpeed.
+const (
+	MinSpeedMultiplier = 0.1
+	MaxSpeedMultiplier = 1000
+)
+
+// Pacer определяет, как модельное время симуляции соотносится с реальным.
+type Pacer interface {
+	// RealDelay переводит модельный интервал в реальный, который нужно выждать.
+	RealDelay(virtual time.Duration) time.Duration
+	// Virtual переводит прошедший реальный интервал в модельный.
+	Virtual(real time.Duration) time.Duration
+}
+
+// RealTimePacer продвигает модельное время пропорционально реальному.
+type RealTimePacer struct {
+	// Speed - количество модельных секунд за одну реальную.
+	Speed float64
+}
+
+// NewRealTimePacer создает Pacer с заданной скоростью.
+func NewRealTimePacer(speed float64) *RealTimePacer {
+	return &RealTimePacer{Speed: speed}
+}
+
+// RealDelay нужна для имплементации интерфейса Pacer.
+func (p *RealTimePacer) RealDelay(virtual time.Duration) time.Duration {
+	return time.Duration(float64(virtual) / p.Speed)
+}
+
+// Virtual нужна для имплементации интерфейса Pacer.
+func (p *RealTimePacer) Virtual(real time.Duration) time.Duration {
+	return time.Duration(float64(real) * p.Speed)
+}
+
+// InstantPacer не ждет реального времени: модельное время перескакивает сразу к следующему событию.
+type InstantPacer struct{}
+
+// RealDelay нужна для имплементации интерфейса Pacer.
+func (InstantPacer) RealDelay(time.Duration) time.Duration {
+	return 0
+}
+
+// Virtual нужна для имплементации интерфейса Pacer.
+func (InstantPacer) Virtual(time.Duration) time.Duration {
+	return 0
+}
+
+// Clock - модельные часы сессии.
+//
+// Модельное время не зависит от реального: оно равно base, сдвинутому на
+// прошедшее с anchor реальное время, пересчитанное через Pacer.
+// Когда часы остановлены, время не идет.
+type Clock struct {
+	startTime time.Time
+	base      time.Time
+	anchor    time.Time
+	running   bool
+	pacer     Pacer
+}
+
+// NewClock создает остановленные часы, показывающие startTime.
+func NewClock(startTime time.Time, pacer Pacer) *Clock {
+	return &Clock{
+		startTime: startTime,
+		base:      startTime,
+		pacer:     pacer,
+	}
+}
+
+// Now возвращает текущее модельное время.
+func (c *Clock) Now() time.Time {
+	if !c.running {
+		return c.base
+	}
+
+	return c.base.Add(c.pacer.Virtual(time.Since(c.anchor)))
+}
+
+// StartTime возвращает модельное время начала симуляции.
+func (c *Clock) StartTime() time.Time {
+	return c.startTime
+}
+
+// Run запускает ход модельного времени.
+func (c *Clock) Run() {
+	if c.running {
+		return
+	}
+	c.anchor = time.Now()
+	c.running = true
+}
+
+// Freeze останавливает ход модельного времени.
+func (c *Clock) Freeze() {
+	if !c.running {
+		return
+	}
+	c.base = c.Now()
+	c.running = false
+}
+
+// SetPacer меняет соотношение модельного и реального времени.
+// Уже прошедшее модельное время сохраняется, новый темп действует с текущего момента.
+func (c *Clock) SetPacer(pacer Pacer) {
+	if c.running {
+		c.base = c.Now()
+		c.anchor = time.Now()
+	}
+	c.pacer = pacer
+}
+
+// AdvanceTo выставляет модельное время в t. Используется при обработке события,
+// чтобы оно произошло ровно в запланированный момент. Часы остаются в часовом поясе startTime.
+func (c *Clock) AdvanceTo(t time.Time) {
+	c.base = t.In(c.startTime.Location())
+	c.anchor = time.Now()
+}
+
+// RealDelay возвращает реальное время, которое нужно выждать до модельного момента t.
+func (c *Clock) RealDelay(t time.Time) time.Duration {
+	d := t.Sub(c.Now())
+	if d <= 0 {
+		return 0
+	}
+
+	return c.pacer.RealDelay(d)
+}
@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Pacer   Pacer
+		Ticking bool          // модельное время идет вместе с реальным
+		Delay   time.Duration // реальное ожидание модельного часа
+	}{
+		{Name: "Real time", Pacer: NewRealTimePacer(3600), Ticking: true, Delay: time.Second},
+		{Name: "Instant", Pacer: InstantPacer{}, Ticking: false, Delay: 0},
+	}
+
+	const pause = 10 * time.Millisecond
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			start := testStart.In(time.FixedZone("MSK", 3*60*60))
+			clock := NewClock(start, tc.Pacer)
+
+			// новые часы стоят
+			clock.Freeze()
+			time.Sleep(pause)
+			assert.WithinDuration(t, start, clock.Now(), 0)
+			assert.WithinDuration(t, start, clock.StartTime(), 0)
+
+			clock.Run()
+			time.Sleep(pause)
+			running := clock.Now()
+			if tc.Ticking {
+				assert.False(t, running.Before(start.Add(tc.Pacer.Virtual(pause))), "clock is not running")
+			} else {
+				assert.WithinDuration(t, start, running, 0)
+			}
+
+			clock.Freeze()
+			frozen := clock.Now()
+			assert.False(t, frozen.Before(running))
+			time.Sleep(pause)
+			assert.WithinDuration(t, frozen, clock.Now(), 0)
+
+			assert.Equal(t, tc.Delay, clock.RealDelay(frozen.Add(time.Hour)))
+			assert.Zero(t, clock.RealDelay(frozen.Add(-time.Hour)))
+
+			// AdvanceTo переводит часы в любое время, оставляя их в часовом поясе начала
+			target := testStart.Add(2 * time.Hour)
+			clock.AdvanceTo(target)
+			time.Sleep(pause)
+			assert.WithinDuration(t, target, clock.Now(), 0)
+			assert.Equal(t, start.Location(), clock.Now().Location())
+
+			clock.Run()
+			clock.AdvanceTo(testStart)
+			assert.False(t, clock.Now().Before(testStart))
+			assert.LessOrEqual(t, clock.Now().Sub(testStart), tc.Pacer.Virtual(time.Second))
+		})
+	}
+}
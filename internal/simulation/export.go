@@ -0,0 +1,234 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/money"
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// Форматы выгрузки событий.
+const (
+	ExportCSV    = "csv"    // таблица с заголовком
+	ExportNDJSON = "ndjson" // JSON Lines: одна запись в строке
+)
+
+// Виды записей выгрузки.
+const (
+	recordEvent     = "event"     // событие машины
+	recordOccupancy = "occupancy" // замер загрузки парковки
+	recordRate      = "rate"      // изменение ставок динамической тарификации
+)
+
+// exportColumns - колонки CSV в порядке полей ExportRecord. Стоимость в CSV записывается
+// в единицах валюты с двумя знаками после точки, а ее валюта - отдельной колонкой.
+var exportColumns = []string{"record", "event", "car_id", "timestamp", "park_x", "park_y", "price", "currency", "occupied", "queued", "multiplier"}
+
+// ExportRecord - строка выгрузки: событие машины или замер загрузки парковки.
+type ExportRecord struct {
+	Record    string       `json:"record"`             // "event", "occupancy"
+	Event     string       `json:"event,omitempty"`    // тип события машины
+	CarID     string       `json:"car_id,omitempty"`   // id машины
+	TimeStamp int64        `json:"timestamp"`          // модельное время
+	ParkX     *int         `json:"park_x,omitempty"`   // х координата парковочного места
+	ParkY     *int         `json:"park_y,omitempty"`   // y координата парковочного места
+	Price     *money.Money `json:"price,omitempty"`    // полная стоимость (для "leave") или почасовая ставка (для изменения ставок)
+	Occupied  *int         `json:"occupied,omitempty"` // занято мест (для замера и изменения ставок)
+	Queued    *int         `json:"queued,omitempty"`   // длина очереди на въезд (для замера)
+	// Multiplier - множитель ставок динамической тарификации (для изменения ставок и "leave").
+	Multiplier *float64 `json:"multiplier,omitempty"`
+}
+
+// Exporter пишет события машин и замеры загрузки в выбранном формате.
+//
+// После первой ошибки записи остальные записи пропускаются, а ошибку вернет Flush.
+type Exporter struct {
+	format string
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool // заголовок CSV уже записан
+	err    error
+}
+
+// NewExporter создает Exporter, который пишет в w в формате format ("csv" или "ndjson").
+func NewExporter(w io.Writer, format string) (*Exporter, error) {
+	const op = "simulation.export.NewExporter"
+
+	e := &Exporter{format: format}
+
+	switch format {
+	case ExportCSV:
+		e.csv = csv.NewWriter(w)
+	case ExportNDJSON:
+		e.json = json.NewEncoder(w)
+	default:
+		return nil, xerrors.Errorf("%s: format %q: %w", op, format, custErr.ErrInvalidExportFormat)
+	}
+
+	return e, nil
+}
+
+// ContentType возвращает MIME-тип выгрузки.
+func (e *Exporter) ContentType() string {
+	if e.format == ExportNDJSON {
+		return "application/x-ndjson"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Write записывает строку выгрузки.
+func (e *Exporter) Write(record ExportRecord) {
+	if e.err != nil {
+		return
+	}
+
+	if e.json != nil {
+		e.err = e.json.Encode(&record)
+		return
+	}
+
+	if !e.header {
+		e.header = true
+		if e.err = e.csv.Write(exportColumns); e.err != nil {
+			return
+		}
+	}
+
+	e.err = e.csv.Write([]string{
+		record.Record,
+		record.Event,
+		record.CarID,
+		strconv.FormatInt(record.TimeStamp, 10),
+		formatInt(record.ParkX),
+		formatInt(record.ParkY),
+		formatPrice(record.Price),
+		formatCurrency(record.Price),
+		formatInt(record.Occupied),
+		formatInt(record.Queued),
+		formatFloat(record.Multiplier),
+	})
+}
+
+// WriteEvent записывает событие машины.
+func (e *Exporter) WriteEvent(event CarEvent) {
+	e.Write(ExportRecord{
+		Record:     recordEvent,
+		Event:      event.Event,
+		CarID:      event.CarID,
+		TimeStamp:  event.TimeStamp,
+		ParkX:      event.ParkX,
+		ParkY:      event.ParkY,
+		Price:      event.Price,
+		Multiplier: event.Multiplier,
+	})
+}
+
+// WriteRate записывает изменение ставок динамической тарификации.
+func (e *Exporter) WriteRate(event RateEvent) {
+	e.Write(ExportRecord{
+		Record:     recordRate,
+		Event:      event.Event,
+		TimeStamp:  event.TimeStamp,
+		Price:      &event.Rate,
+		Occupied:   &event.Occupied,
+		Multiplier: &event.Multiplier,
+	})
+}
+
+// WriteSample записывает замер загрузки парковки.
+func (e *Exporter) WriteSample(sample OccupancySample) {
+	e.Write(ExportRecord{
+		Record:    recordOccupancy,
+		TimeStamp: sample.TimeStamp,
+		Occupied:  &sample.Occupied,
+		Queued:    &sample.Queued,
+	})
+}
+
+// Flush дописывает буферизованные строки и возвращает первую ошибку записи.
+func (e *Exporter) Flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if e.err == nil {
+			e.err = e.csv.Error()
+		}
+	}
+
+	return e.err
+}
+
+// ExportRun выгружает сохраненный прогон run: события машин по порядку и замеры загрузки
+// с шагом sampleInterval (по умолчанию 15 минут), восстановленные по событиям.
+func ExportRun(e *Exporter, run *models.SimulationRun, events []models.SimulationEvent, sampleInterval time.Duration) error {
+	if sampleInterval <= 0 {
+		sampleInterval = defaultSampleInterval * time.Minute
+	}
+
+	events = sortedEvents(events)
+	next, end := runBounds(run, events)
+	stats := newCollector(next.Unix(), "")
+
+	for _, stored := range events {
+		var event CarEvent
+		if err := json.Unmarshal(stored.Payload, &event); err != nil {
+			continue
+		}
+
+		for ; next.Unix() < event.TimeStamp && !next.After(end); next = next.Add(sampleInterval) {
+			e.WriteSample(stats.sample(next.Unix()))
+		}
+
+		stats.observe(event)
+		e.WriteEvent(event)
+	}
+
+	for ; !next.After(end); next = next.Add(sampleInterval) {
+		e.WriteSample(stats.sample(next.Unix()))
+	}
+
+	return e.Flush()
+}
+
+// formatInt возвращает число для CSV или пустую строку, если его нет.
+func formatInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*v)
+}
+
+// formatFloat возвращает дробное число для CSV или пустую строку, если его нет.
+func formatFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// formatPrice возвращает стоимость для CSV или пустую строку, если ее нет.
+func formatPrice(v *money.Money) string {
+	if v == nil {
+		return ""
+	}
+
+	return v.String()
+}
+
+// formatCurrency возвращает валюту стоимости для CSV или пустую строку, если стоимости нет.
+func formatCurrency(v *money.Money) string {
+	if v == nil {
+		return ""
+	}
+
+	return v.Currency
+}
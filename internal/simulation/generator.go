@@ -1,118 +1,249 @@
 package simulation
 
 import (
-	"fmt"
 	"math"
 	"math/rand/v2"
+	"slices"
 	"time"
-)
 
-const (
-	dayStartHour   = 6
-	nightStartHour = 22
+	"github.com/PIRSON21/parking/internal/models"
 )
 
-// generateArrivalDelay вычисляет задержку появления автомобиля в зависимости от типа потока.
-func (ss *Session) generateArrivalDelay() time.Duration {
-	switch ss.arrivalCfg.Type {
+// delayUnit - единица измерения временных параметров конфигураций (модельная минута).
+const delayUnit = time.Minute
+
+// neverDelay - задержка события, которое на практике не наступит (100 лет модельного времени).
+const neverDelay = 100 * 365 * 24 * time.Hour
+
+// generateArrivalDelay вычисляет задержку появления автомобиля в зависимости от типа потока cfg.
+func (ss *Session) generateArrivalDelay(cfg *ArrivalConfig) time.Duration {
+	switch cfg.Type {
 	case "exponential":
-		return generateExponentialDelay(ss.arrivalCfg.Lambda)
+		return generateExponentialDelay(ss.rnd, cfg.Lambda)
 	case "normal":
-		return generateNormalDelay(ss.arrivalCfg.Mean, ss.arrivalCfg.StdDev)
+		return generateNormalDelay(ss.rnd, cfg.Mean, cfg.StdDev)
 	case "uniform":
-		return generateUniformDelay(ss.arrivalCfg.MinDelay, ss.arrivalCfg.MaxDelay)
+		return generateUniformDelay(ss.rnd, cfg.MinDelay, cfg.MaxDelay)
 	case "discrete":
-		return generateDiscreteDelay(ss.arrivalCfg.DiscreteTime)
+		return generateDiscreteDelay(cfg.DiscreteTime)
+	case "profile":
+		return generateProfileDelay(ss.rnd, cfg.Profile, ss.clock.Now())
 	default:
-		return generateDiscreteDelay(ss.arrivalCfg.DiscreteTime)
+		return generateDiscreteDelay(cfg.DiscreteTime)
+	}
+}
+
+// arrivalConfig возвращает поток, с которым приехала машина car: поток абонентов или общий.
+func (ss *Session) arrivalConfig(car *models.SimulatedCar) *ArrivalConfig {
+	if car.Pass != nil {
+		return ss.subscriberCfg.ArrivalConfig
+	}
+
+	return ss.arrivalCfg
+}
+
+// generateProfileDelay вычисляет задержку до следующего прибытия для нестационарного потока
+// с интенсивностью по профилю, начиная с модельного момента from.
+//
+// Используется метод прореживания (thinning): кандидаты генерируются пуассоновским потоком
+// с максимальной интенсивностью профиля и принимаются с вероятностью rate(t) / maxRate.
+// Так прибытия корректно распределяются и на границах периодов профиля.
+func generateProfileDelay(rnd *rand.Rand, profile []IntensityPeriod, from time.Time) time.Duration {
+	maxRate := 0.0
+	for _, period := range profile {
+		if period.FromHour < period.ToHour {
+			maxRate = math.Max(maxRate, period.Rate)
+		}
+	}
+	if maxRate == 0 {
+		// по профилю машины не приезжают никогда
+		return neverDelay
+	}
+
+	t := from
+	for {
+		t = t.Add(generateExponentialDelay(rnd, maxRate))
+		if rnd.Float64()*maxRate < profileRate(profile, t) {
+			return t.Sub(from)
+		}
+	}
+}
+
+// profileRate возвращает интенсивность потока по профилю в модельный момент t.
+func profileRate(profile []IntensityPeriod, t time.Time) float64 {
+	hour, weekday := t.Hour(), int(t.Weekday())
+
+	for _, period := range profile {
+		if hour < period.FromHour || hour >= period.ToHour {
+			continue
+		}
+		if len(period.Weekdays) > 0 && !slices.Contains(period.Weekdays, weekday) {
+			continue
+		}
+		return period.Rate
 	}
+
+	return 0
 }
 
 // generateExponentialDelay вычисляет задержку экспоненциального распределения.
-func generateExponentialDelay(lambda float64) time.Duration {
-	r := rand.Float64()
+func generateExponentialDelay(rnd *rand.Rand, lambda float64) time.Duration {
+	r := rnd.Float64()
 	delay := -math.Log(1.0-r) / lambda
-	return time.Duration(delay * float64(time.Second))
+	return time.Duration(delay * float64(delayUnit))
 }
 
 // generateNormalDelay вычисляет задержку нормального распределения.
-func generateNormalDelay(mean float64, dev float64) time.Duration {
-	delay := rand.NormFloat64()*dev + mean
-	return time.Duration(math.Abs(delay) * float64(time.Second))
+func generateNormalDelay(rnd *rand.Rand, mean float64, dev float64) time.Duration {
+	delay := rnd.NormFloat64()*dev + mean
+	return time.Duration(math.Abs(delay) * float64(delayUnit))
 }
 
 // generateUniformDelay вычисляет задержку равномерного распределения.
-func generateUniformDelay(minDelay float64, maxDelay float64) time.Duration {
-	delay := minDelay + (maxDelay-minDelay)*rand.Float64()
-	return time.Duration(delay * float64(time.Second))
+func generateUniformDelay(rnd *rand.Rand, minDelay float64, maxDelay float64) time.Duration {
+	delay := minDelay + (maxDelay-minDelay)*rnd.Float64()
+	return time.Duration(delay * float64(delayUnit))
 }
 
 // generateDiscreteDelay вычисляет задержку дискретного потока.
 func generateDiscreteDelay(discrete float64) time.Duration {
-	return time.Duration(discrete * float64(time.Second))
+	return time.Duration(discrete * float64(delayUnit))
 }
 
-func (ss *Session) evaluateEntrance() bool {
-	return rand.Float64() < ss.arrivalCfg.ParkingProb
+// evaluateEntrance определяет, захочет ли водитель машины car заехать на парковку.
+// При динамической тарификации вероятность заезда разовых посетителей зависит от текущих ставок.
+func (ss *Session) evaluateEntrance(car *models.SimulatedCar) bool {
+	prob := ss.arrivalConfig(car).ParkingProb
+	if ss.surge != nil && car.Pass == nil {
+		prob *= ss.surge.demandFactor(ss.multiplier)
+	}
+
+	return ss.rnd.Float64() < prob
 }
 
-func (ss *Session) generateLeaveDelay() time.Duration {
-	switch ss.parkingCfg.Type {
-	case "exponential":
-		return generateExponentialDelay(ss.parkingCfg.Lambda)
-	case "normal":
-		return generateNormalDelay(ss.parkingCfg.Mean, ss.parkingCfg.StdDev)
-	case "uniform":
-		return generateUniformDelay(ss.parkingCfg.MinDuration, ss.parkingCfg.MaxDuration)
-	case "discrete":
-		return generateDiscreteDelay(ss.parkingCfg.DiscreteTime)
-	default:
-		return generateDiscreteDelay(ss.parkingCfg.DiscreteTime)
+// chooseEntrance выбирает въезд для появившейся машины с учетом весов из конфигурации потока cfg.
+func (ss *Session) chooseEntrance(cfg *ArrivalConfig) models.PathPoint {
+	entrances := ss.parking.Entrances
+	weights := cfg.EntranceWeights
+
+	if len(weights) != len(entrances) {
+		return entrances[ss.rnd.IntN(len(entrances))]
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		total += w
 	}
+	if total <= 0 {
+		return entrances[ss.rnd.IntN(len(entrances))]
+	}
+
+	r := ss.rnd.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return entrances[i]
+		}
+		r -= w
+	}
+
+	return entrances[len(entrances)-1]
 }
 
-// calculateParkingCost вычисляет стоимость стоянки.
-func (ss *Session) calculateParkingCost(now time.Time, entered time.Time) float64 {
-	totalCost := 0.0
-	fmt.Println(entered, now)
+// chooseClass выбирает класс появившейся машины по долям из конфигурации.
+func (ss *Session) chooseClass() models.VehicleClass {
+	if len(ss.classes) == 0 {
+		return models.Car
+	}
 
-	for entered.Before(now) {
-		nextBoundary := getNextBoundary(entered)
+	total := 0.0
+	for _, c := range ss.classes {
+		total += c.Share
+	}
 
-		// если машина уезжает до следующей смены тарифа
-		if now.Before(nextBoundary) {
-			duration := now.Sub(entered)
-			totalCost += calculateSegmentCost(duration, entered, ss.parking.DayTariff, ss.parking.NightTariff)
-			break
+	r := ss.rnd.Float64() * total
+	for _, c := range ss.classes {
+		if r < c.Share {
+			return c.Class
 		}
-		// если уезжает позже смены
-		duration := nextBoundary.Sub(entered)
-		totalCost += calculateSegmentCost(duration, entered, ss.parking.DayTariff, ss.parking.NightTariff)
+		r -= c.Share
+	}
 
-		entered = nextBoundary
+	return ss.classes[len(ss.classes)-1].Class
+}
+
+// chooseElectric определяет, является ли появившаяся из потока cfg машина электромобилем.
+func (ss *Session) chooseElectric(cfg *ArrivalConfig) bool {
+	if cfg.EVShare <= 0 {
+		return false
 	}
 
-	return totalCost
+	return ss.rnd.Float64() < cfg.EVShare
 }
 
-// getNextBoundary определяет следующую границу тарифа.
-func getNextBoundary(t time.Time) time.Time {
-	hour := t.Hour()
+// generateEnergy вычисляет объем энергии (кВт·ч), который электромобиль хочет получить.
+func (ss *Session) generateEnergy() float64 {
+	cfg := ss.chargingCfg
+	if cfg == nil {
+		return 0
+	}
 
-	if hour >= nightStartHour || hour < dayStartHour {
-		return time.Date(t.Year(), t.Month(), t.Day()+1, dayStartHour, 0, 0, 0, t.Location())
+	switch cfg.Type {
+	case "normal":
+		return math.Abs(ss.rnd.NormFloat64()*cfg.StdDev + cfg.Mean)
+	case "uniform":
+		return cfg.MinEnergy + (cfg.MaxEnergy-cfg.MinEnergy)*ss.rnd.Float64()
+	default:
+		return cfg.DiscreteEnergy
 	}
+}
 
-	return time.Date(t.Year(), t.Month(), t.Day(), nightStartHour, 0, 0, 0, t.Location())
+// deliveredEnergy вычисляет, сколько энергии электромобиль успел получить за время стоянки.
+func (ss *Session) deliveredEnergy(requested float64, dwell time.Duration) float64 {
+	if ss.chargingCfg == nil || ss.chargingCfg.Power == 0 {
+		return requested
+	}
+
+	return math.Min(requested, ss.chargingCfg.Power*dwell.Hours())
 }
 
-// calculateSegmentCost вычисляет стоимость парковки для длительности d.
-func calculateSegmentCost(d time.Duration, t time.Time, rateDay, rateNight float64) float64 {
-	hour := t.Hour()
+// parkingTimeConfig возвращает распределение времени стоянки для класса class.
+func (ss *Session) parkingTimeConfig(class models.VehicleClass) *ParkingTimeConfig {
+	for _, c := range ss.classes {
+		if c.Class == class && c.ParkingTimeConfig != nil {
+			return c.ParkingTimeConfig
+		}
+	}
+
+	return ss.parkingCfg
+}
 
-	if hour >= nightStartHour || hour < dayStartHour {
-		return d.Hours() * rateNight
+// generateLeaveDelay вычисляет время стоянки машины car.
+// Для абонентов используется их распределение времени стоянки, если оно задано.
+func (ss *Session) generateLeaveDelay(car *models.SimulatedCar) time.Duration {
+	if car.Pass != nil && ss.subscriberCfg.ParkingTimeConfig != nil {
+		return ss.generateDuration(ss.subscriberCfg.ParkingTimeConfig)
 	}
 
-	return d.Hours() * rateDay
+	return ss.generateDuration(ss.parkingTimeConfig(car.Class))
+}
+
+// generatePatience вычисляет, сколько машина готова ждать в очереди на въезд.
+func (ss *Session) generatePatience() time.Duration {
+	return ss.generateDuration(ss.queueCfg.Patience)
+}
+
+// generateDuration вычисляет длительность по распределению cfg.
+func (ss *Session) generateDuration(cfg *ParkingTimeConfig) time.Duration {
+	switch cfg.Type {
+	case "exponential":
+		return generateExponentialDelay(ss.rnd, cfg.Lambda)
+	case "normal":
+		return generateNormalDelay(ss.rnd, cfg.Mean, cfg.StdDev)
+	case "uniform":
+		return generateUniformDelay(ss.rnd, cfg.MinDuration, cfg.MaxDuration)
+	case "discrete":
+		return generateDiscreteDelay(cfg.DiscreteTime)
+	default:
+		return generateDiscreteDelay(cfg.DiscreteTime)
+	}
 }
@@ -0,0 +1,155 @@
+package simulation
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+)
+
+// replicationSem ограничивает количество одновременно работающих прогонов на весь сервер,
+// чтобы большое количество реплик не заняло все ресурсы.
+var replicationSem = semaphore.NewWeighted(int64(runtime.NumCPU()))
+
+// ReplicationParams - параметры серии независимых прогонов (метод Монте-Карло).
+type ReplicationParams struct {
+	BatchParams
+	Replications int `json:"replications" validate:"required,gte=2,lte=1000"` // количество прогонов
+}
+
+// Estimate - выборочная оценка показателя по серии прогонов.
+type Estimate struct {
+	Mean   float64 `json:"mean"`    // выборочное среднее
+	StdDev float64 `json:"std_dev"` // выборочное стандартное отклонение
+	CILow  float64 `json:"ci_low"`  // нижняя граница 95% доверительного интервала
+	CIHigh float64 `json:"ci_high"` // верхняя граница 95% доверительного интервала
+}
+
+// ReplicationReport - итог серии прогонов.
+type ReplicationReport struct {
+	Replications  int      `json:"replications"`   // количество прогонов
+	Seed          uint64   `json:"seed"`           // базовый seed, из которого получены seed прогонов
+	Seeds         []uint64 `json:"seeds"`          // seed каждого прогона
+	Revenue       Estimate `json:"revenue"`        // выручка в единицах валюты (рублях)
+	RejectionRate Estimate `json:"rejection_rate"` // доля машин, проехавших мимо или не дождавшихся места
+	Utilisation   Estimate `json:"utilisation"`    // средняя загрузка парковки
+	MeanDwell     Estimate `json:"mean_dwell"`     // среднее время стоянки в минутах
+}
+
+// RunReplications выполняет params.Replications независимых прогонов параллельно
+// и оценивает показатели с 95% доверительными интервалами.
+//
+// Seed каждого прогона выводится из базового seed, поэтому вся серия воспроизводима.
+func RunReplications(ctx context.Context, params *ReplicationParams, log *slog.Logger) (*ReplicationReport, error) {
+	baseSeed := newSeed()
+	if params.Seed != nil {
+		baseSeed = *params.Seed
+	}
+
+	seeds := replicationSeeds(baseSeed, params.Replications)
+	reports := make([]*Report, len(seeds))
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, seed := range seeds {
+		if err := replicationSem.Acquire(gctx, 1); err != nil {
+			break
+		}
+
+		g.Go(func() error {
+			defer replicationSem.Release(1)
+
+			replica := params.BatchParams
+			replica.Seed = &seed
+
+			report, err := RunBatch(gctx, &replica, log)
+			if err != nil {
+				return err
+			}
+			reports[i] = report
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &ReplicationReport{
+		Replications:  len(reports),
+		Seed:          baseSeed,
+		Seeds:         seeds,
+		Revenue:       estimate(reports, func(r *Report) float64 { return r.Revenue.Major() }),
+		RejectionRate: estimate(reports, func(r *Report) float64 { return r.RejectionRate }),
+		Utilisation:   estimate(reports, func(r *Report) float64 { return r.Utilisation }),
+		MeanDwell:     estimate(reports, func(r *Report) float64 { return r.MeanDwell }),
+	}, nil
+}
+
+// replicationSeeds выводит n seed прогонов из базового seed.
+func replicationSeeds(base uint64, n int) []uint64 {
+	rnd := newRand(base)
+	seeds := make([]uint64, n)
+	for i := range seeds {
+		seeds[i] = rnd.Uint64() & maxSeed
+	}
+
+	return seeds
+}
+
+// estimate считает среднее, стандартное отклонение и 95% доверительный интервал показателя.
+func estimate(reports []*Report, value func(*Report) float64) Estimate {
+	n := float64(len(reports))
+
+	var sum float64
+	for _, r := range reports {
+		sum += value(r)
+	}
+	mean := sum / n
+
+	var sq float64
+	for _, r := range reports {
+		d := value(r) - mean
+		sq += d * d
+	}
+	stdDev := math.Sqrt(sq / (n - 1))
+
+	half := studentT975(len(reports)-1) * stdDev / math.Sqrt(n)
+
+	return Estimate{
+		Mean:   mean,
+		StdDev: stdDev,
+		CILow:  mean - half,
+		CIHigh: mean + half,
+	}
+}
+
+// tTable - квантили распределения Стьюдента уровня 0.975 для числа степеней свободы 1..30.
+var tTable = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// studentT975 возвращает квантиль распределения Стьюдента уровня 0.975 для df степеней свободы.
+// Для больших df берется табличное значение ближайшего меньшего df, так интервал не занижается.
+func studentT975(df int) float64 {
+	switch {
+	case df <= len(tTable):
+		return tTable[df-1]
+	case df < 40:
+		return tTable[len(tTable)-1]
+	case df < 60:
+		return 2.021
+	case df < 120:
+		return 2.000
+	default:
+		return 1.980
+	}
+}
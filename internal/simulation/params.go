@@ -0,0 +1,36 @@
+package simulation
+
+import (
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// InitParams - параметры запуска симуляции, которые присылает клиент.
+type InitParams struct {
+	Parking           *models.Parking    `json:"parking" validate:"required"`
+	ArrivalConfig     *ArrivalConfig     `json:"arrival_config" validate:"required"`
+	ParkingTimeConfig *ParkingTimeConfig `json:"parking_time_config" validate:"required"`
+	StartTime         int64              `json:"start_time" validate:"required"`
+	Seed              *uint64            `json:"seed,omitempty" validate:"omitempty,lte=9007199254740991"` // seed генератора случайных чисел; если не указан, выбирается случайно
+	// Стратегия выбора парковочного места; по умолчанию - ближайшее к въезду место.
+	Strategy string `json:"strategy,omitempty" validate:"omitempty,oneof=nearest-entrance nearest-exit random fill-far-first even-wear"`
+	// Классы транспорта с долями в потоке и временем стоянки. Если не заданы, все машины - легковые.
+	VehicleClasses []VehicleClassConfig `json:"vehicle_classes,omitempty" validate:"omitempty,dive"`
+	// Распределение энергии для электромобилей; обязательно, если задана доля электромобилей.
+	ChargingConfig *ChargingConfig `json:"charging_config,omitempty"`
+	// Очередь на въезд. Если не задана, машина, не нашедшая места, сразу уезжает.
+	QueueConfig *QueueConfig `json:"queue_config,omitempty"`
+	// Абоненты, которые не платят за стоянку. Если не заданы, все машины - разовые посетители.
+	Subscribers *SubscriberConfig `json:"subscribers,omitempty"`
+	// Динамическая тарификация по загрузке. Если не задана, действуют ставки парковки.
+	PricingPolicy *PricingPolicy `json:"pricing_policy,omitempty"`
+	// Шаг событий "stats" с текущими показателями в минутах модельного времени. Если не задан, клиент
+	// получает показатели только в итоговой сводке "summary": старые клиенты не знают событий "stats".
+	StatsInterval int `json:"stats_interval,omitempty" validate:"omitempty,gte=1,lte=1440"`
+}
+
+// BatchParams - параметры пакетного (без клиента) прогона симуляции.
+type BatchParams struct {
+	InitParams
+	Duration       int `json:"duration" validate:"required,gte=1,lte=43200"`                  // модельная длительность прогона в минутах
+	SampleInterval int `json:"sample_interval,omitempty" validate:"omitempty,gte=1,lte=1440"` // шаг замеров загрузки в минутах
+}
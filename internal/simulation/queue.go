@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Виды запланированных событий.
+const (
+	kindArrival    = "arrival"    // появление новой машины
+	kindSubscriber = "subscriber" // появление машины абонента
+	kindPark       = "park"       // попытка заезда на парковку
+	kindLeave      = "leave"      // выезд с парковки
+	kindRenege     = "renege"     // у машины в очереди на въезд кончилось терпение
+	kindSample     = "sample"     // замер загрузки парковки
+	kindStats      = "stats"      // сводка показателей для клиента
+)
+
+// scheduledEvent - событие, запланированное на модельный момент времени.
+type scheduledEvent struct {
+	at    time.Time // модельное время события
+	seq   uint64    // порядковый номер для стабильной сортировки одновременных событий
+	kind  string    // вид события
+	carID string    // id машины (если событие относится к машине)
+	index int       // индекс в куче
+}
+
+// eventQueue - очередь с приоритетом будущих событий, упорядоченная по модельному времени.
+type eventQueue []*scheduledEvent
+
+// Len возвращает длину очереди.
+func (q eventQueue) Len() int { return len(q) }
+
+// Less сравнивает два события по времени, а при равенстве - по порядку добавления.
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+// Swap меняет местами два события в очереди.
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+// Push добавляет событие в очередь.
+func (q *eventQueue) Push(x interface{}) {
+	ev := x.(*scheduledEvent)
+	ev.index = len(*q)
+	*q = append(*q, ev)
+}
+
+// Pop удаляет последнее событие из очереди.
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	ev := old[n-1]
+	old[n-1] = nil
+	ev.index = -1 // для безопасности
+	*q = old[0 : n-1]
+	return ev
+}
+
+// peek возвращает ближайшее событие, не удаляя его.
+func (q eventQueue) peek() *scheduledEvent {
+	if len(q) == 0 {
+		return nil
+	}
+	return q[0]
+}
+
+// schedule планирует событие kind на модельное время at.
+func (ss *Session) schedule(at time.Time, kind string, carID string) *scheduledEvent {
+	ss.seq++
+	ev := &scheduledEvent{
+		at:    at,
+		seq:   ss.seq,
+		kind:  kind,
+		carID: carID,
+	}
+	heap.Push(&ss.queue, ev)
+
+	return ev
+}
@@ -0,0 +1,57 @@
+package simulation
+
+import (
+	"container/heap"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventQueue(t *testing.T) {
+	type event struct {
+		Minute int    // модельная минута события
+		Label  string // метка, по которой проверяется порядок
+	}
+
+	cases := []struct {
+		Name     string
+		Events   []event
+		Expected []string
+	}{
+		{
+			Name:     "Ordered by time",
+			Events:   []event{{3, "c"}, {1, "a"}, {2, "b"}},
+			Expected: []string{"a", "b", "c"},
+		},
+		{
+			Name:     "Equal times keep schedule order",
+			Events:   []event{{5, "a"}, {5, "b"}, {5, "c"}, {5, "d"}, {5, "e"}},
+			Expected: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			Name:     "Mixed",
+			Events:   []event{{2, "d"}, {1, "a"}, {2, "e"}, {1, "b"}, {0, "start"}, {2, "f"}, {1, "c"}},
+			Expected: []string{"start", "a", "b", "c", "d", "e", "f"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ss := &Session{}
+			for _, ev := range tc.Events {
+				ss.schedule(testStart.Add(time.Duration(ev.Minute)*time.Minute), kindPark, ev.Label)
+			}
+
+			got := make([]string, 0, len(tc.Expected))
+			for ss.queue.Len() > 0 {
+				next := ss.queue.peek()
+				assert.Same(t, next, heap.Pop(&ss.queue))
+				got = append(got, next.carID)
+			}
+
+			assert.Equal(t, tc.Expected, got)
+			assert.Nil(t, ss.queue.peek())
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package simulation
+
+import (
+	"math/rand/v2"
+
+	"github.com/google/uuid"
+)
+
+// maxSeed - максимальное значение seed, которое без потерь передается через JSON в JavaScript (2^53 - 1).
+const maxSeed = 1<<53 - 1
+
+// newSeed создает случайный seed для сессии, в которой он не указан.
+func newSeed() uint64 {
+	return rand.Uint64() & maxSeed
+}
+
+// newRand создает источник случайных чисел сессии по seed.
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// randReader читает случайные байты из источника сессии.
+// Нужен, чтобы id машин тоже повторялись при одинаковом seed.
+type randReader struct {
+	rnd *rand.Rand
+}
+
+// Read нужна для имплементации интерфейса io.Reader.
+func (r randReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r.rnd.Uint32())
+	}
+
+	return len(p), nil
+}
+
+// generateCarID создает уникальный id машины из источника случайных чисел сессии.
+func (ss *Session) generateCarID() string {
+	id, err := uuid.NewRandomFromReader(randReader{ss.rnd})
+	if err != nil {
+		return uuid.New().String()
+	}
+
+	return id.String()
+}
@@ -0,0 +1,182 @@
+package simulation
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// RunSaver сохраняет прогоны симуляции.
+type RunSaver interface {
+	CreateSimulationRun(run *models.SimulationRun) error
+	AddSimulationEvents(runID int, events []models.SimulationEvent) error
+	FinishSimulationRun(runID int, summary json.RawMessage) error
+}
+
+const (
+	// recordBatch - сколько событий Recorder сохраняет за один раз.
+	recordBatch = 500
+	// recordBuffer - сколько событий может ждать сохранения. Когда буфер заполнен, Recorder
+	// не принимает новые события, пока не сохранит накопленные.
+	recordBuffer = 4 * recordBatch
+)
+
+// Recorder сохраняет события машин и итоговую сводку прогона симуляции.
+//
+// Recorder подключается к сессии наблюдателем. События копятся в памяти и сохраняются
+// отдельной горутиной пачками по recordBatch, поэтому медленная БД не задерживает рассылку событий клиентам,
+// пока в буфере есть место. Если БД не успевает, сессия ждет ее, а буфер не растет больше recordBuffer.
+type Recorder struct {
+	log     *slog.Logger
+	saver   RunSaver
+	runID   int
+	mu      sync.Mutex
+	space   *sync.Cond // сигналит, когда из буфера забрали события на сохранение
+	seq     int
+	pending []models.SimulationEvent
+	summary json.RawMessage
+	wake    chan struct{}
+}
+
+// recordedEvent - поля события, по которым Recorder решает, что с ним делать.
+type recordedEvent struct {
+	Event     string `json:"event"`
+	TimeStamp int64  `json:"timestamp"`
+	CarID     string `json:"car_id"`
+}
+
+// StartRecording сохраняет начало прогона сессии session с параметрами params, запущенного менеджером managerID,
+// и подключает к сессии Recorder. Прогон завершается, когда сессия остановлена и все ее события сохранены.
+//
+// parkingID - id парковки прогона, проверенный по БД (id из params клиента не используется); 0 - прогон
+// не привязан к парковке.
+func StartRecording(session *Session, params *InitParams, parkingID int, managerID int, saver RunSaver, log *slog.Logger) (*models.SimulationRun, error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.SimulationRun{
+		ManagerID: managerID,
+		Seed:      session.Seed(),
+		Strategy:  session.Strategy(),
+		Params:    rawParams,
+	}
+	if parkingID != 0 {
+		run.ParkingID = &parkingID
+	}
+
+	if err = saver.CreateSimulationRun(run); err != nil {
+		return nil, err
+	}
+
+	recorder := newRecorder(run.ID, saver, log)
+	session.AddObserver(recorder)
+
+	go recorder.saveLoop(session.Flushed())
+
+	return run, nil
+}
+
+// newRecorder создает Recorder прогона runID.
+func newRecorder(runID int, saver RunSaver, log *slog.Logger) *Recorder {
+	r := &Recorder{
+		log:   log.With(slog.Int("run_id", runID)),
+		saver: saver,
+		runID: runID,
+		wake:  make(chan struct{}, 1),
+	}
+	r.space = sync.NewCond(&r.mu)
+
+	return r
+}
+
+// Send нужна для имплементации интерфейса EventSender.
+func (r *Recorder) Send(data []byte) {
+	var event recordedEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		r.log.Error("error while decoding event", slog.String("err", err.Error()))
+		return
+	}
+
+	r.mu.Lock()
+	switch {
+	case event.Event == eventSummary:
+		r.summary = append(json.RawMessage{}, data...)
+	case event.CarID != "":
+		for len(r.pending) >= recordBuffer {
+			r.space.Wait()
+		}
+		r.seq++
+		r.pending = append(r.pending, models.SimulationEvent{
+			Seq:       r.seq,
+			Type:      event.Event,
+			TimeStamp: event.TimeStamp,
+			CarID:     event.CarID,
+			Payload:   append(json.RawMessage{}, data...),
+		})
+	default:
+		// снимки состояния, изменения скорости и промежуточные показатели не сохраняются
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// saveLoop сохраняет накопленные события, пока сессия не остановится (не закроется flushed),
+// а затем сохраняет оставшиеся события и итоговую сводку.
+func (r *Recorder) saveLoop(flushed <-chan struct{}) {
+	for {
+		select {
+		case <-r.wake:
+			r.save()
+		case <-flushed:
+			r.save()
+
+			r.mu.Lock()
+			summary := r.summary
+			r.mu.Unlock()
+
+			if err := r.saver.FinishSimulationRun(r.runID, summary); err != nil {
+				r.log.Error("error while finishing simulation run", slog.String("err", err.Error()))
+				return
+			}
+			r.log.Debug("simulation run saved")
+			return
+		}
+	}
+}
+
+// save сохраняет накопленные события пачками по recordBatch, пока буфер не опустеет.
+func (r *Recorder) save() {
+	for {
+		r.mu.Lock()
+		count := len(r.pending)
+		if count > recordBatch {
+			count = recordBatch
+		}
+		events := r.pending[:count:count]
+		r.pending = r.pending[count:]
+		if len(r.pending) == 0 {
+			r.pending = nil
+		}
+		r.space.Broadcast()
+		r.mu.Unlock()
+
+		if len(events) == 0 {
+			return
+		}
+
+		// при ошибке пачка теряется: повторять сохранение в упавшую БД бессмысленно, а буфер должен освобождаться
+		if err := r.saver.AddSimulationEvents(r.runID, events); err != nil {
+			r.log.Error("error while saving simulation events", slog.String("err", err.Error()), slog.Int("count", len(events)))
+		}
+	}
+}
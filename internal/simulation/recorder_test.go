@@ -0,0 +1,98 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowSaver сохраняет события прогона с задержкой delay на каждую пачку и проверяет буфер Recorder.
+type slowSaver struct {
+	mu       sync.Mutex
+	delay    time.Duration
+	recorder *Recorder
+	events   []models.SimulationEvent
+	batches  []int // размеры сохраненных пачек
+	pending  int   // максимальный размер буфера Recorder во время сохранения
+	summary  json.RawMessage
+	finished chan struct{}
+}
+
+// CreateSimulationRun нужна для имплементации интерфейса RunSaver.
+func (s *slowSaver) CreateSimulationRun(*models.SimulationRun) error {
+	return nil
+}
+
+// AddSimulationEvents нужна для имплементации интерфейса RunSaver.
+func (s *slowSaver) AddSimulationEvents(_ int, events []models.SimulationEvent) error {
+	time.Sleep(s.delay)
+
+	s.recorder.mu.Lock()
+	pending := len(s.recorder.pending)
+	s.recorder.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+	s.batches = append(s.batches, len(events))
+	if pending > s.pending {
+		s.pending = pending
+	}
+
+	return nil
+}
+
+// FinishSimulationRun нужна для имплементации интерфейса RunSaver.
+func (s *slowSaver) FinishSimulationRun(_ int, summary json.RawMessage) error {
+	s.summary = summary
+	close(s.finished)
+
+	return nil
+}
+
+// TestRecorderSlowSaver проверяет, что Recorder сохраняет события пачками не больше recordBatch,
+// а буфер несохраненных событий не растет больше recordBuffer, даже если БД не успевает.
+func TestRecorderSlowSaver(t *testing.T) {
+	saver := &slowSaver{delay: time.Millisecond, finished: make(chan struct{})}
+	recorder := newRecorder(1, saver, slogdiscard.NewDiscardLogger())
+	saver.recorder = recorder
+
+	flushed := make(chan struct{})
+	go recorder.saveLoop(flushed)
+
+	const total = 3 * recordBuffer
+	for i := 1; i <= total; i++ {
+		recorder.Send([]byte(fmt.Sprintf(`{"event":"arrive","timestamp":%d,"car_id":"car-%d"}`, i, i)))
+	}
+	recorder.Send([]byte(`{"event":"summary","arrivals":6000}`))
+	close(flushed)
+
+	select {
+	case <-saver.finished:
+	case <-time.After(waitTimeout):
+		t.Fatal("run is not finished")
+	}
+
+	saver.mu.Lock()
+	defer saver.mu.Unlock()
+
+	assert.LessOrEqual(t, saver.pending, recordBuffer)
+	for _, batch := range saver.batches {
+		assert.LessOrEqual(t, batch, recordBatch)
+	}
+
+	require.Len(t, saver.events, total)
+	for i, event := range saver.events {
+		assert.Equal(t, i+1, event.Seq)
+		assert.Equal(t, fmt.Sprintf("car-%d", i+1), event.CarID)
+	}
+	assert.JSONEq(t, `{"event":"summary","arrivals":6000}`, string(saver.summary))
+}
@@ -0,0 +1,171 @@
+package simulation
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+)
+
+// DefaultGracePeriod - сколько по умолчанию сессия ждет переподключения клиента.
+const DefaultGracePeriod = 5 * time.Minute
+
+// Registry хранит сессии симуляции по токенам, чтобы клиент мог переподключиться к своей сессии.
+//
+// Когда клиент отключается, сессия ждет его grace-период, а затем останавливается и удаляется.
+type Registry struct {
+	log      *slog.Logger
+	mu       sync.Mutex
+	grace    time.Duration
+	sessions map[string]*registryEntry
+}
+
+// registryEntry - сессия в реестре.
+type registryEntry struct {
+	session  *Session
+	ownerID  int         // id менеджера, создавшего сессию
+	attached bool        // клиент подключен
+	timer    *time.Timer // таймер удаления отключенной сессии
+}
+
+// NewRegistry создает реестр сессий. Если grace не задан, используется DefaultGracePeriod.
+func NewRegistry(grace time.Duration, log *slog.Logger) *Registry {
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+
+	return &Registry{
+		log:      log,
+		grace:    grace,
+		sessions: make(map[string]*registryEntry),
+	}
+}
+
+// Add регистрирует сессию с подключенным клиентом менеджера ownerID и возвращает ее токен.
+func (r *Registry) Add(session *Session, ownerID int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token := uuid.NewString()
+	r.sessions[token] = &registryEntry{
+		session:  session,
+		ownerID:  ownerID,
+		attached: true,
+	}
+
+	return token
+}
+
+// Attach переподключает клиента client менеджера ownerID к сессии по токену.
+// Клиент сразу получает снимок состояния сессии.
+func (r *Registry) Attach(token string, ownerID int, client EventSender) (*Session, error) {
+	const op = "simulation.registry.Attach"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.sessions[token]
+	if !ok || entry.session.Stopped() {
+		return nil, xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotFound)
+	}
+	if entry.ownerID != ownerID {
+		return nil, xerrors.Errorf("%s: %w", op, custErr.ErrSimulationAccessDenied)
+	}
+	if entry.attached {
+		return nil, xerrors.Errorf("%s: %w", op, custErr.ErrSimulationInUse)
+	}
+
+	if entry.timer != nil {
+		entry.timer.Stop()
+		entry.timer = nil
+	}
+	entry.attached = true
+	entry.session.Attach(client)
+
+	return entry.session, nil
+}
+
+// Observe подключает к сессии по токену наблюдателя observer. Наблюдатель получает снимок
+// состояния сессии и ее события, но не управляет ей; отключается он через Session.RemoveObserver.
+//
+// Владелец сессии и роль наблюдателя не проверяются: токен - случайный UUID, и менеджер, передавший его
+// (например, слушателям тренинга), тем самым дает им право смотреть симуляцию. Управлять ей по токену
+// может только владелец (Attach).
+func (r *Registry) Observe(token string, observer EventSender) (*Session, error) {
+	const op = "simulation.registry.Observe"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.sessions[token]
+	if !ok || entry.session.Stopped() {
+		return nil, xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotFound)
+	}
+
+	entry.session.AddObserver(observer)
+
+	return entry.session, nil
+}
+
+// Detach отключает клиента от сессии. Если клиент не переподключится за grace-период,
+// сессия будет остановлена и удалена. Остановленная сессия удаляется сразу.
+func (r *Registry) Detach(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.sessions[token]
+	if !ok || !entry.attached {
+		return
+	}
+
+	entry.attached = false
+	entry.session.Detach()
+
+	if entry.session.Stopped() {
+		delete(r.sessions, token)
+		return
+	}
+
+	entry.timer = time.AfterFunc(r.grace, func() {
+		r.expire(token, entry)
+	})
+
+	r.log.Info("waiting for client to reconnect", slog.String("token", token), slog.Duration("grace", r.grace))
+}
+
+// expire останавливает и удаляет сессию, к которой клиент не переподключился.
+func (r *Registry) expire(token string, entry *registryEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// клиент успел переподключиться или сессию уже удалили
+	if r.sessions[token] != entry || entry.attached {
+		return
+	}
+
+	delete(r.sessions, token)
+	entry.session.Stop()
+
+	r.log.Info("session expired", slog.String("token", token))
+}
+
+// Remove останавливает сессию и удаляет ее из реестра.
+func (r *Registry) Remove(token string) {
+	r.mu.Lock()
+	entry, ok := r.sessions[token]
+	delete(r.sessions, token)
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	entry.session.Stop()
+}
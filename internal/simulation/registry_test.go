@@ -0,0 +1,103 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSession создает сессию в реальном времени с получателем client, запускает ее
+// и регистрирует в реестре registry от менеджера с id 1.
+func newTestSession(t *testing.T, registry *Registry, client EventSender) (*Session, string) {
+	t.Helper()
+
+	session := NewSession(client, newTestParams(), NewRealTimePacer(DefaultSpeed*100), slogdiscard.NewDiscardLogger())
+	token := registry.Add(session, 1)
+	require.NoError(t, session.Start())
+	t.Cleanup(session.Stop)
+
+	return session, token
+}
+
+func TestRegistryAttach(t *testing.T) {
+	registry := NewRegistry(time.Hour, slogdiscard.NewDiscardLogger())
+	_, token := newTestSession(t, registry, &recordingSender{})
+
+	cases := []struct {
+		Name        string
+		Token       string
+		OwnerID     int
+		ExpectedErr error
+	}{
+		{
+			Name:        "Unknown token",
+			Token:       "unknown",
+			OwnerID:     1,
+			ExpectedErr: custErr.ErrSimulationNotFound,
+		},
+		{
+			Name:        "Other manager",
+			Token:       token,
+			OwnerID:     2,
+			ExpectedErr: custErr.ErrSimulationAccessDenied,
+		},
+		{
+			Name:        "Client still attached",
+			Token:       token,
+			OwnerID:     1,
+			ExpectedErr: custErr.ErrSimulationInUse,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			session, err := registry.Attach(tc.Token, tc.OwnerID, &recordingSender{})
+			require.ErrorIs(t, err, tc.ExpectedErr)
+			assert.Nil(t, session)
+		})
+	}
+}
+
+func TestRegistryReconnect(t *testing.T) {
+	registry := NewRegistry(time.Hour, slogdiscard.NewDiscardLogger())
+	first := &recordingSender{}
+	session, token := newTestSession(t, registry, first)
+	first.waitFor(t, isEvent(eventArrive))
+
+	registry.Detach(token)
+	assert.False(t, session.Stopped())
+
+	second := &recordingSender{}
+	attached, err := registry.Attach(token, 1, second)
+	require.NoError(t, err)
+	assert.Same(t, session, attached)
+
+	// после переподключения клиент сначала получает снимок, а затем новые события
+	assert.Equal(t, 0, second.waitFor(t, isEvent(eventSnapshot)))
+	second.waitFor(t, isEvent(eventArrive))
+
+	_, err = registry.Attach(token, 1, &recordingSender{})
+	assert.ErrorIs(t, err, custErr.ErrSimulationInUse)
+}
+
+func TestRegistryGracePeriodExpired(t *testing.T) {
+	registry := NewRegistry(20*time.Millisecond, slogdiscard.NewDiscardLogger())
+	session, token := newTestSession(t, registry, &recordingSender{})
+
+	registry.Detach(token)
+
+	require.Eventually(t, session.Stopped, waitTimeout, time.Millisecond)
+	require.Eventually(t, func() bool {
+		registry.mu.Lock()
+		defer registry.mu.Unlock()
+		_, ok := registry.sessions[token]
+		return !ok
+	}, waitTimeout, time.Millisecond)
+
+	_, err := registry.Attach(token, 1, &recordingSender{})
+	assert.ErrorIs(t, err, custErr.ErrSimulationNotFound)
+}
@@ -0,0 +1,444 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// eventReplay - описание воспроизводимого прогона, которое клиент получает при подключении.
+const eventReplay = "replay"
+
+// RunLoader загружает сохраненные прогоны симуляции.
+type RunLoader interface {
+	GetSimulationRun(runID int, managerID int) (*models.SimulationRun, error)
+	GetSimulationEvents(runID int) ([]models.SimulationEvent, error)
+}
+
+// ReplayInfo - описание воспроизводимого прогона.
+type ReplayInfo struct {
+	Event     string          `json:"event"` // "replay"
+	RunID     int             `json:"run_id"`
+	Seed      uint64          `json:"seed"`
+	Strategy  string          `json:"strategy"`
+	StartTime int64           `json:"start_time"` // модельное время начала прогона
+	EndTime   int64           `json:"end_time"`   // модельное время окончания прогона
+	Events    int             `json:"events"`     // количество событий машин в прогоне
+	Params    json.RawMessage `json:"params"`     // параметры симуляции (InitParams): парковка и конфигурации
+}
+
+// Replay воспроизводит сохраненный прогон симуляции.
+//
+// Клиент получает сохраненные события машин в том же виде, что и в живой сессии, в их модельное время.
+// Ход модельного времени задается так же, как в сессии: его можно приостановить, ускорить
+// и перемотать в любую точку прогона - тогда клиент получит снимок состояния на этот момент.
+// Когда события заканчиваются, клиент получает итоговую сводку прогона и воспроизведение встает на паузу.
+type Replay struct {
+	log     *slog.Logger
+	mu      sync.Mutex
+	client  EventSender
+	run     *models.SimulationRun
+	events  []models.SimulationEvent
+	next    int // индекс следующего события
+	clock   *Clock
+	endTime time.Time
+	state   string // "running", "paused", "stopped"
+	started bool
+	speed   float64 // множитель скорости относительно DefaultSpeed
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wake    chan struct{}
+}
+
+// replayParams - поля параметров прогона, нужные для воспроизведения.
+type replayParams struct {
+	StartTime int64 `json:"start_time"`
+}
+
+// NewReplay создает остановленное воспроизведение прогона run с событиями events.
+func NewReplay(client EventSender, run *models.SimulationRun, events []models.SimulationEvent, log *slog.Logger) *Replay {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events = sortedEvents(events)
+	startTime, endTime := runBounds(run, events)
+
+	return &Replay{
+		log:     log.With(slog.Int("run_id", run.ID)),
+		client:  client,
+		run:     run,
+		events:  events,
+		clock:   NewClock(startTime, NewRealTimePacer(DefaultSpeed)),
+		endTime: endTime,
+		state:   stateStopped,
+		speed:   1,
+		ctx:     ctx,
+		cancel:  cancel,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// sortedEvents возвращает копию событий прогона в порядке их номеров.
+func sortedEvents(events []models.SimulationEvent) []models.SimulationEvent {
+	events = append([]models.SimulationEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+
+	return events
+}
+
+// runBounds возвращает модельное время начала и окончания прогона run с упорядоченными событиями events.
+func runBounds(run *models.SimulationRun, events []models.SimulationEvent) (time.Time, time.Time) {
+	// без времени начала в параметрах прогон начинается с момента запуска
+	start := run.StartedAt
+	var params replayParams
+	if err := json.Unmarshal(run.Params, &params); err == nil && params.StartTime != 0 {
+		start = time.Unix(params.StartTime, 0)
+	}
+
+	end := start
+	if len(events) > 0 && events[len(events)-1].TimeStamp > end.Unix() {
+		end = time.Unix(events[len(events)-1].TimeStamp, 0)
+	}
+	var summary recordedEvent
+	if err := json.Unmarshal(run.Summary, &summary); err == nil && summary.TimeStamp > end.Unix() {
+		end = time.Unix(summary.TimeStamp, 0)
+	}
+
+	return start, end
+}
+
+// Info возвращает описание воспроизводимого прогона.
+func (rp *Replay) Info() ReplayInfo {
+	return ReplayInfo{
+		Event:     eventReplay,
+		RunID:     rp.run.ID,
+		Seed:      rp.run.Seed,
+		Strategy:  rp.run.Strategy,
+		StartTime: rp.clock.StartTime().Unix(),
+		EndTime:   rp.endTime.Unix(),
+		Events:    len(rp.events),
+		Params:    rp.run.Params,
+	}
+}
+
+// Start запускает воспроизведение.
+func (rp *Replay) Start() error {
+	const op = "simulation.replay.Start"
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if rp.started {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationAlreadyStarted)
+	}
+	if rp.ctx.Err() != nil {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationStopped)
+	}
+
+	rp.started = true
+	rp.state = stateRunning
+	rp.clock.Run()
+	go rp.loop()
+
+	rp.log.Info("replay started", slog.Time("sim_time", rp.clock.Now()))
+
+	return nil
+}
+
+// Pause приостанавливает воспроизведение.
+func (rp *Replay) Pause() error {
+	const op = "simulation.replay.Pause"
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if rp.state != stateRunning {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotRunning)
+	}
+
+	rp.state = statePaused
+	rp.clock.Freeze()
+	rp.notify()
+
+	rp.log.Info("replay paused", slog.Time("sim_time", rp.clock.Now()))
+
+	return nil
+}
+
+// Resume продолжает приостановленное воспроизведение.
+func (rp *Replay) Resume() error {
+	const op = "simulation.replay.Resume"
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if rp.state != statePaused {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotPaused)
+	}
+
+	rp.state = stateRunning
+	rp.clock.Run()
+	rp.notify()
+
+	rp.log.Info("replay resumed", slog.Time("sim_time", rp.clock.Now()))
+
+	return nil
+}
+
+// Stop останавливает воспроизведение.
+func (rp *Replay) Stop() {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	rp.state = stateStopped
+	rp.clock.Freeze()
+	rp.cancel()
+
+	rp.log.Info("replay stopped", slog.Time("sim_time", rp.clock.Now()))
+}
+
+// SetSpeed меняет скорость воспроизведения: multiplier модельных минут за реальную секунду.
+func (rp *Replay) SetSpeed(multiplier float64) error {
+	const op = "simulation.replay.SetSpeed"
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if multiplier < MinSpeedMultiplier || multiplier > MaxSpeedMultiplier {
+		return xerrors.Errorf("%s: multiplier %v out of range: %w", op, multiplier, custErr.ErrInvalidSpeed)
+	}
+
+	rp.speed = multiplier
+	rp.clock.SetPacer(NewRealTimePacer(DefaultSpeed * multiplier))
+	rp.notify()
+
+	rp.log.Info("replay speed changed", slog.Float64("speed", multiplier), slog.Time("sim_time", rp.clock.Now()))
+
+	rp.sendJSON(SessionEvent{
+		Event:     eventSpeedChanged,
+		TimeStamp: rp.clock.Now().Unix(),
+		Speed:     rp.speed,
+	})
+
+	return nil
+}
+
+// Seek перематывает воспроизведение в модельное время t (вперед или назад) в пределах прогона.
+// Клиент получает снимок состояния на момент t и "speed-changed" с временем перемотки,
+// после чего воспроизведение продолжается с событий после t.
+func (rp *Replay) Seek(t time.Time) error {
+	const op = "simulation.replay.Seek"
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if rp.ctx.Err() != nil {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationStopped)
+	}
+	if t.Before(rp.clock.StartTime()) || t.After(rp.endTime) {
+		return xerrors.Errorf("%s: %v is out of [%v, %v]: %w", op, t, rp.clock.StartTime(), rp.endTime, custErr.ErrInvalidSeekTime)
+	}
+
+	rp.next = sort.Search(len(rp.events), func(i int) bool {
+		return rp.events[i].TimeStamp > t.Unix()
+	})
+	rp.clock.AdvanceTo(t)
+	rp.notify()
+
+	rp.log.Info("replay seeked", slog.Time("sim_time", t), slog.Int("next", rp.next))
+
+	rp.sendJSON(rp.snapshot())
+	skipped := t.Unix()
+	rp.sendJSON(SessionEvent{
+		Event:     eventSpeedChanged,
+		TimeStamp: skipped,
+		Speed:     rp.speed,
+		SkippedTo: &skipped,
+	})
+
+	return nil
+}
+
+// loop отправляет клиенту события прогона в их модельное время, выжидая реальное время, которое требует Pacer.
+func (rp *Replay) loop() {
+	for {
+		rp.mu.Lock()
+
+		if rp.ctx.Err() != nil {
+			rp.mu.Unlock()
+			return
+		}
+
+		if rp.state != stateRunning {
+			rp.mu.Unlock()
+			if !rp.sleep(0) {
+				return
+			}
+			continue
+		}
+
+		at := rp.endTime
+		if rp.next < len(rp.events) {
+			at = time.Unix(rp.events[rp.next].TimeStamp, 0)
+		}
+
+		if delay := rp.clock.RealDelay(at); delay > time.Millisecond {
+			rp.mu.Unlock()
+			if !rp.sleep(delay) {
+				return
+			}
+			continue
+		}
+
+		rp.clock.AdvanceTo(at)
+		if rp.next < len(rp.events) {
+			rp.client.Send(rp.events[rp.next].Payload)
+			rp.next++
+		} else {
+			rp.finish()
+		}
+
+		rp.mu.Unlock()
+	}
+}
+
+// finish отправляет итоговую сводку прогона и ставит воспроизведение на паузу. Вызывается под rp.mu.
+func (rp *Replay) finish() {
+	if len(rp.run.Summary) > 0 {
+		rp.client.Send(rp.run.Summary)
+	}
+
+	rp.state = statePaused
+	rp.clock.Freeze()
+
+	rp.log.Info("replay finished", slog.Time("sim_time", rp.clock.Now()))
+}
+
+// sleep ждет реальное время d (или без ограничения, если d == 0), пока воспроизведение не разбудят.
+// Вернет false, если воспроизведение остановлено.
+func (rp *Replay) sleep(d time.Duration) bool {
+	var timeout <-chan time.Time
+	if d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case <-timeout:
+	case <-rp.wake:
+	case <-rp.ctx.Done():
+		return false
+	}
+
+	return true
+}
+
+// notify будит цикл воспроизведения после изменения состояния.
+func (rp *Replay) notify() {
+	select {
+	case rp.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sendJSON отправляет клиенту событие воспроизведения. Вызывается под rp.mu.
+func (rp *Replay) sendJSON(event interface{}) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		rp.log.Error("error while marshaling event", slog.String("err", err.Error()))
+		return
+	}
+
+	rp.client.Send(data)
+}
+
+// snapshot восстанавливает по уже отправленным событиям состояние прогона на текущий модельный момент.
+// Вызывается под rp.mu.
+func (rp *Replay) snapshot() *Snapshot {
+	snap := &Snapshot{
+		Event:     eventSnapshot,
+		TimeStamp: rp.clock.Now().Unix(),
+		StartTime: rp.clock.StartTime().Unix(),
+		State:     rp.state,
+		Started:   rp.started,
+		Speed:     rp.speed,
+		Seed:      rp.run.Seed,
+		Strategy:  rp.run.Strategy,
+		Cars:      []CarSnapshot{},
+		Queue:     []string{},
+	}
+
+	cars := make(map[string]*CarSnapshot)
+	for _, stored := range rp.events[:rp.next] {
+		var event CarEvent
+		if err := json.Unmarshal(stored.Payload, &event); err != nil {
+			continue
+		}
+
+		switch event.Event {
+		case eventArrive:
+			car := &CarSnapshot{
+				CarID:    event.CarID,
+				State:    eventArrive,
+				Class:    event.Class,
+				Electric: event.Electric,
+			}
+			if event.Entrance != nil {
+				car.Entrance = *event.Entrance
+			}
+			cars[event.CarID] = car
+		case eventQueued:
+			if car, ok := cars[event.CarID]; ok {
+				car.State = eventQueued
+			}
+			snap.Queue = append(snap.Queue, event.CarID)
+		case eventPark:
+			if car, ok := cars[event.CarID]; ok {
+				enter := event.TimeStamp
+				car.State = eventPark
+				car.ParkX = event.ParkX
+				car.ParkY = event.ParkY
+				car.Charger = event.Charger
+				car.EnterTime = &enter
+			}
+			snap.Queue = without(snap.Queue, event.CarID)
+		case eventDroveAway, eventReneged, eventLeave:
+			delete(cars, event.CarID)
+			snap.Queue = without(snap.Queue, event.CarID)
+		}
+	}
+
+	for _, car := range cars {
+		if car.State == eventPark {
+			snap.Occupied++
+		}
+		snap.Cars = append(snap.Cars, *car)
+	}
+
+	sort.Slice(snap.Cars, func(i, j int) bool {
+		return snap.Cars[i].CarID < snap.Cars[j].CarID
+	})
+
+	return snap
+}
+
+// without возвращает ids без id.
+func without(ids []string, id string) []string {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+
+	return ids
+}
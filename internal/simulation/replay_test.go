@@ -0,0 +1,168 @@
+package simulation
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRun создает сохраненный прогон длиной в час: машина a стоит с 10:01 до 10:15,
+// машина b - с 10:05 до 10:25, машина c в 10:20 проезжает мимо. События перемешаны,
+// воспроизведение должно упорядочить их по номерам.
+func newTestRun(t *testing.T) (*models.SimulationRun, []models.SimulationEvent) {
+	t.Helper()
+
+	x, y := 1, 0
+	entrance := models.PathPoint{X: 3, Y: 1}
+	carEvents := []CarEvent{
+		{Event: eventArrive, CarID: "a", Class: models.Car, Entrance: &entrance},
+		{Event: eventPark, CarID: "a", Class: models.Car, ParkX: &x, ParkY: &y},
+		{Event: eventArrive, CarID: "b", Class: models.Car, Entrance: &entrance},
+		{Event: eventPark, CarID: "b", Class: models.Car, ParkX: &y, ParkY: &x},
+		{Event: eventLeave, CarID: "a", Class: models.Car},
+		{Event: eventArrive, CarID: "c", Class: models.Car, Entrance: &entrance},
+		{Event: eventDroveAway, CarID: "c", Class: models.Car},
+		{Event: eventLeave, CarID: "b", Class: models.Car},
+	}
+	minutes := []int{1, 1, 5, 5, 15, 20, 20, 25}
+
+	events := make([]models.SimulationEvent, len(carEvents))
+	for i, event := range carEvents {
+		event.TimeStamp = testStart.Add(time.Duration(minutes[i]) * time.Minute).Unix()
+		payload, err := json.Marshal(event)
+		require.NoError(t, err)
+
+		// события лежат в обратном порядке
+		events[len(events)-1-i] = models.SimulationEvent{
+			Seq:       i + 1,
+			Type:      event.Event,
+			TimeStamp: event.TimeStamp,
+			CarID:     event.CarID,
+			Payload:   payload,
+		}
+	}
+
+	run := &models.SimulationRun{
+		ID:        7,
+		Seed:      42,
+		Strategy:  "nearest-entrance",
+		Params:    json.RawMessage(`{"start_time":` + strconv.FormatInt(testStart.Unix(), 10) + `}`),
+		Summary:   json.RawMessage(`{"event":"summary","timestamp":` + strconv.FormatInt(testStart.Add(time.Hour).Unix(), 10) + `}`),
+		StartedAt: time.Now(),
+	}
+
+	return run, events
+}
+
+func TestReplayInfo(t *testing.T) {
+	run, events := newTestRun(t)
+	replay := NewReplay(&recordingSender{}, run, events, slogdiscard.NewDiscardLogger())
+
+	info := replay.Info()
+	assert.Equal(t, testStart.Unix(), info.StartTime)
+	assert.Equal(t, testStart.Add(time.Hour).Unix(), info.EndTime)
+	assert.Equal(t, len(events), info.Events)
+	assert.Equal(t, uint64(42), info.Seed)
+}
+
+func TestReplaySeek(t *testing.T) {
+	run, events := newTestRun(t)
+	client := &recordingSender{}
+	replay := NewReplay(client, run, events, slogdiscard.NewDiscardLogger())
+	t.Cleanup(replay.Stop)
+
+	// на 10:10 обе машины стоят на местах
+	require.NoError(t, replay.Seek(testStart.Add(10*time.Minute)))
+
+	var snapshot Snapshot
+	require.NoError(t, json.Unmarshal(client.message(0), &snapshot))
+	assert.Equal(t, eventSnapshot, snapshot.Event)
+	assert.Equal(t, testStart.Add(10*time.Minute).Unix(), snapshot.TimeStamp)
+	assert.Equal(t, 2, snapshot.Occupied)
+	require.Len(t, snapshot.Cars, 2)
+	assert.Equal(t, "a", snapshot.Cars[0].CarID)
+	assert.Equal(t, eventPark, snapshot.Cars[0].State)
+	assert.Equal(t, testStart.Add(time.Minute).Unix(), *snapshot.Cars[0].EnterTime)
+	assert.Equal(t, "b", snapshot.Cars[1].CarID)
+
+	var skipped SessionEvent
+	require.NoError(t, json.Unmarshal(client.message(1), &skipped))
+	assert.Equal(t, eventSpeedChanged, skipped.Event)
+	require.NotNil(t, skipped.SkippedTo)
+	assert.Equal(t, testStart.Add(10*time.Minute).Unix(), *skipped.SkippedTo)
+
+	// после перемотки воспроизведение продолжается со следующего события: машина a уезжает в 10:15
+	require.NoError(t, replay.SetSpeed(MaxSpeedMultiplier))
+	require.NoError(t, replay.Start())
+	next := client.waitFor(t, func(event recordedEvent) bool { return event.CarID != "" })
+
+	var event CarEvent
+	require.NoError(t, json.Unmarshal(client.message(next), &event))
+	assert.Equal(t, eventLeave, event.Event)
+	assert.Equal(t, "a", event.CarID)
+	assert.Equal(t, testStart.Add(15*time.Minute).Unix(), event.TimeStamp)
+
+	// перемотка назад восстанавливает состояние до отъезда машины a
+	client.waitFor(t, isEvent(eventSummary))
+	require.NoError(t, replay.Seek(testStart.Add(2*time.Minute)))
+	snapshotAt := client.waitFor(t, func(event recordedEvent) bool {
+		return event.Event == eventSnapshot && event.TimeStamp == testStart.Add(2*time.Minute).Unix()
+	})
+	require.NoError(t, json.Unmarshal(client.message(snapshotAt), &snapshot))
+	assert.Equal(t, 1, snapshot.Occupied)
+	require.Len(t, snapshot.Cars, 1)
+	assert.Equal(t, "a", snapshot.Cars[0].CarID)
+}
+
+func TestReplaySeekOutOfRun(t *testing.T) {
+	run, events := newTestRun(t)
+	replay := NewReplay(&recordingSender{}, run, events, slogdiscard.NewDiscardLogger())
+	t.Cleanup(replay.Stop)
+
+	assert.Error(t, replay.Seek(testStart.Add(-time.Minute)))
+	assert.Error(t, replay.Seek(testStart.Add(2*time.Hour)))
+}
+
+func TestReplayOrder(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Speed float64
+	}{
+		{Name: "Fast", Speed: MaxSpeedMultiplier},
+		{Name: "Slower", Speed: 200},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			run, events := newTestRun(t)
+			client := &recordingSender{}
+			replay := NewReplay(client, run, events, slogdiscard.NewDiscardLogger())
+			t.Cleanup(replay.Stop)
+
+			require.NoError(t, replay.SetSpeed(tc.Speed))
+			require.NoError(t, replay.Start())
+			client.waitFor(t, isEvent(eventSummary))
+
+			received := client.events()
+			require.Len(t, received, len(events)+2)
+			assert.Equal(t, eventSpeedChanged, received[0].Event)
+
+			// события приходят в порядке номеров и в свое модельное время, затем - итоговая сводка
+			for i, event := range sortedEvents(events) {
+				assert.Equal(t, event.Type, received[i+1].Event, "event %d", event.Seq)
+				assert.Equal(t, event.CarID, received[i+1].CarID, "event %d", event.Seq)
+				assert.Equal(t, event.TimeStamp, received[i+1].TimeStamp, "event %d", event.Seq)
+			}
+			assert.Equal(t, eventSummary, received[len(received)-1].Event)
+
+			// в конце прогона воспроизведение встает на паузу
+			assert.NoError(t, replay.Resume())
+		})
+	}
+}
@@ -1,259 +1,385 @@
 package simulation
 
 import (
-	"encoding/json"
-	"log"
-
+	"github.com/PIRSON21/parking/internal/lib/money"
 	"github.com/PIRSON21/parking/internal/models"
-	"github.com/google/uuid"
-	customTimer "github.com/ivahaev/timer"
 )
 
 // CarEvent - тело события машины (прибытие, парковка, отъезд)
 type CarEvent struct {
-	Event     string   `json:"event"`             // "arrive", "drove-away", "leave"
-	CarID     string   `json:"car_id"`            // id машины
-	TimeStamp int64    `json:"timestamp"`         // время события
-	ParkID    *int     `json:"park_id,omitempty"` // id парковочного места
-	ParkX     *int     `json:"park_x,omitempty"`  // х координата парковочного места
-	ParkY     *int     `json:"park_y,omitempty"`  // y координата парковочного места
-	Price     *float64 `json:"price,omitempty"`   // стоимость парковки
+	Event         string              `json:"event"`                    // "arrive", "park", "drove-away", "leave"
+	CarID         string              `json:"car_id"`                   // id машины
+	Class         models.VehicleClass `json:"class"`                    // класс транспорта
+	Electric      bool                `json:"electric,omitempty"`       // электромобиль
+	Subscriber    bool                `json:"subscriber,omitempty"`     // машина абонента
+	TimeStamp     int64               `json:"timestamp"`                // модельное время события
+	ParkID        *int                `json:"park_id,omitempty"`        // id парковочного места
+	ParkX         *int                `json:"park_x,omitempty"`         // х координата парковочного места
+	ParkY         *int                `json:"park_y,omitempty"`         // y координата парковочного места
+	Charger       bool                `json:"charger,omitempty"`        // место с зарядной станцией (для "park" и "leave")
+	Price         *money.Money        `json:"price,omitempty"`          // полная стоимость: стоянка и зарядка (для "leave")
+	ParkingPrice  *money.Money        `json:"parking_price,omitempty"`  // стоимость стоянки (для "leave")
+	ChargingPrice *money.Money        `json:"charging_price,omitempty"` // стоимость зарядки (для "leave", если машина заряжалась)
+	Energy        *float64            `json:"energy,omitempty"`         // полученная энергия в кВт·ч (для "leave", если машина заряжалась)
+	Path          []models.PathPoint  `json:"path,omitempty"`           // маршрут машины по парковке (для "park" и "leave")
+	Entrance      *models.PathPoint   `json:"entrance,omitempty"`       // въезд машины (для "arrive" и "park")
+	Exit          *models.PathPoint   `json:"exit,omitempty"`           // выезд машины (для "leave")
+	QueueLength   *int                `json:"queue_length,omitempty"`   // длина очереди на въезд после события (для "queued", "reneged" и "park" из очереди)
+	Multiplier    *float64            `json:"multiplier,omitempty"`     // множитель ставок на момент заезда (для "leave" при динамической тарификации)
+	Surcharge     *money.Money        `json:"surcharge,omitempty"`      // надбавка к стоимости стоянки, при скидке - отрицательная (для "leave" при динамической тарификации)
 }
 
-const (
-	eventArrive = "arrive" // eventArrive - машина появляется на дороге
-)
-
-// generateCarID создает уникальный id машины.
-func generateCarID() string {
-	return uuid.New().String()
+// RateEvent - изменение множителя ставок динамической тарификации.
+type RateEvent struct {
+	Event      string      `json:"event"`      // "rate-changed"
+	TimeStamp  int64       `json:"timestamp"`  // модельное время события
+	Multiplier float64     `json:"multiplier"` // новый множитель ставок
+	Previous   float64     `json:"previous"`   // прежний множитель ставок
+	Occupied   int         `json:"occupied"`   // занято мест
+	Capacity   int         `json:"capacity"`   // количество парковочных мест
+	Rate       money.Money `json:"rate"`       // почасовая ставка с учетом множителя в момент изменения
 }
 
-// scheduleCar создает новое событие появления автомобиля
-func (ss *Session) scheduleCar() {
-	delay := ss.generateArrivalDelay()
-	t := customTimer.NewTimer(delay)
-	t.Start()
-
-	for {
-		select {
-		case <-t.C:
-			ss.mu.Lock()
-			if ss.isRunning() {
-				ss.mu.Unlock()
-				go ss.sendCarEvent()
-				delay = ss.generateArrivalDelay()
-				t = customTimer.NewTimer(delay)
-				t.Start()
-			} else {
-				ss.mu.Unlock()
-				return
-			}
-		case <-ss.ctx.Done():
-			ss.log.Debug("session stopped, stop scheduling cars")
-			return
-		}
-	}
+// SessionEvent - событие состояния сессии (изменение скорости, перемотка).
+type SessionEvent struct {
+	Event     string  `json:"event"`                // "speed-changed"
+	TimeStamp int64   `json:"timestamp"`            // модельное время события
+	Speed     float64 `json:"speed"`                // множитель скорости относительно DefaultSpeed
+	SkippedTo *int64  `json:"skipped_to,omitempty"` // модельное время, до которого перемотали симуляцию
 }
 
-// sendCarEvent создает событие о появлении автомобиля.
-func (ss *Session) sendCarEvent() {
-	err := ss.sem.Acquire(ss.ctx, 1)
-	defer ss.sem.Release(1)
-	if err != nil {
-		return
-	}
-	carID := generateCarID()
+const (
+	eventArrive    = "arrive"     // eventArrive - машина появляется на дороге
+	eventPark      = "park"       // eventPark - машина заняла парковочное место
+	eventDroveAway = "drove-away" // eventDroveAway - машина проехала мимо парковки
+	eventLeave     = "leave"      // eventLeave - машина уехала с парковки
+	eventQueued    = "queued"     // eventQueued - машина встала в очередь на въезд
+	eventReneged   = "reneged"    // eventReneged - машина не дождалась места и уехала из очереди
+
+	eventSpeedChanged = "speed-changed" // eventSpeedChanged - изменилась скорость или закончилась перемотка
+	eventRateChanged  = "rate-changed"  // eventRateChanged - изменился множитель ставок динамической тарификации
+	eventStats        = "stats"         // eventStats - текущие показатели сессии
+	eventSummary      = "summary"       // eventSummary - итоговые показатели остановленной сессии
+)
+
+// arriveCar создает машину и событие о ее появлении. Возвращает id машины.
+// Если задан абонемент pass, машина принадлежит абоненту.
+func (ss *Session) arriveCar(pass *models.SeasonPass) string {
+	carID := ss.generateCarID()
 
 	car := &models.SimulatedCar{
 		CarID: carID,
 		State: eventArrive,
+		Class: models.Car,
+		Pass:  pass,
 	}
-
-	ss.mu.Lock()
+	if pass != nil {
+		pass.OnSite = true
+	} else {
+		car.Class = ss.chooseClass()
+	}
+	cfg := ss.arrivalConfig(car)
+	car.Electric = ss.chooseElectric(cfg)
+	car.Entrance = ss.chooseEntrance(cfg)
 	ss.car[carID] = car
-	ss.mu.Unlock()
 
-	event := CarEvent{
-		Event:     eventArrive,
-		CarID:     carID,
-		TimeStamp: ss.timer.elapsedTime.Unix(),
-	}
+	ss.log.Debug("car arrived", "car_id", carID, "time", ss.clock.Now(), "class", car.Class, "entrance", car.Entrance)
 
-	data, err := json.Marshal(event)
-	if err != nil {
-		log.Println("error while marshaling: ", err)
-		return
-	}
+	ss.emit(CarEvent{
+		Event:      eventArrive,
+		CarID:      carID,
+		Class:      car.Class,
+		Electric:   car.Electric,
+		Subscriber: pass != nil,
+		TimeStamp:  ss.clock.Now().Unix(),
+		Entrance:   &car.Entrance,
+	})
 
-	ss.log.Debug("car arrived", "car_id", carID, "time", ss.timer.elapsedTime)
-	ss.client.Send(data)
+	return carID
 }
 
 // tryToPark определяет, заедет машина на парковку или нет.
+// Если свободного места нет, машина встает в очередь на въезд (когда она задана и не заполнена) или уезжает.
 func (ss *Session) tryToPark(carID string) {
-	err := ss.sem.Acquire(ss.ctx, 1)
-	defer ss.sem.Release(1)
-	if err != nil {
-		return
-	}
-
-	ss.mu.Lock()
-	if !ss.isRunning() {
+	car, ok := ss.car[carID]
+	if !ok || car.State != eventArrive {
 		return
 	}
 
 	ss.log.Debug("trying to park car", "car_id", carID)
 
-	car, ok := ss.car[carID]
-	if !ok {
-		ss.mu.Unlock()
+	if canEnter := ss.evaluateEntrance(car); !canEnter {
+		ss.droveAwayCar(carID)
 		return
 	}
-	ss.mu.Unlock()
 
-	if canEnter := ss.evaluateEntrance(); !canEnter {
-		ss.droveAwayCar(carID)
+	if ss.parkCar(car) {
 		return
 	}
 
-	spot, ok := ss.parking.OccupySpot()
-	if !ok {
-		ss.droveAwayCar(carID)
+	if ss.queueCfg != nil && len(ss.waiting) < ss.queueCfg.Capacity {
+		ss.queueCar(car)
 		return
 	}
 
-	ss.mu.Lock()
-	car.State = "park"
-	car.Spot = spot
-	ss.mu.Unlock()
-	ss.sendParkEvent(carID)
+	ss.droveAwayCar(carID)
 }
 
-func (ss *Session) sendParkEvent(carID string) {
-	ss.mu.Lock()
+// parkCar пытается поставить машину на свободное место. Вернет false, если подходящего места нет.
+// Абонент с закрепленным местом встает только на него.
+func (ss *Session) parkCar(car *models.SimulatedCar) bool {
+	spot, ok := ss.occupySpot(car)
+	if !ok {
+		return false
+	}
+
+	fromQueue := car.State == eventQueued
+	if fromQueue {
+		ss.dequeue(car.CarID)
+	}
 
-	car, ok := ss.car[carID]
-	if !ok || car == nil {
-		ss.log.Debug("car not found in session", "car_id", carID)
-		ss.mu.Unlock()
-		return
+	car.State = eventPark
+	car.Spot = spot
+	car.EnterTime = ss.clock.Now()
+	car.Multiplier = 1
+	if car.Pass == nil {
+		car.Multiplier = ss.multiplier
+	}
+	if spot.IsCharger() {
+		car.Energy = ss.generateEnergy()
 	}
 
-	car.EnterTime = ss.timer.elapsedTime
-	ss.mu.Unlock()
+	ss.log.Debug("car parked", "car_id", car.CarID, "time", car.EnterTime, "spot", car.Spot)
 
 	event := CarEvent{
-		Event:     "park",
-		CarID:     carID,
-		ParkX:     &car.Spot.X,
-		ParkY:     &car.Spot.Y,
-		TimeStamp: ss.timer.elapsedTime.Unix(),
+		Event:      eventPark,
+		CarID:      car.CarID,
+		Class:      car.Class,
+		Electric:   car.Electric,
+		Subscriber: car.Pass != nil,
+		Charger:    spot.IsCharger(),
+		ParkX:      &car.Spot.X,
+		ParkY:      &car.Spot.Y,
+		TimeStamp:  car.EnterTime.Unix(),
+		Path:       pathPoints(ss.parking.GetPathToSpot(car.Entrance, spot)),
+		Entrance:   &car.Entrance,
+	}
+	if fromQueue {
+		event.QueueLength = ss.queueLength()
 	}
+	ss.emit(event)
+	ss.updateRate()
 
-	ss.log.Debug("car parked", "car_id", carID, "time", ss.timer.elapsedTime, "spot", car.Spot)
+	ss.schedule(car.EnterTime.Add(ss.generateLeaveDelay(car)), kindLeave, car.CarID)
+
+	return true
+}
 
-	ss.eventChan <- event
+// queueCar ставит машину в очередь на въезд и планирует ее уход, когда кончится терпение.
+func (ss *Session) queueCar(car *models.SimulatedCar) {
+	car.State = eventQueued
+	ss.waiting = append(ss.waiting, car.CarID)
+
+	ss.log.Debug("car queued", "car_id", car.CarID, "time", ss.clock.Now(), "queue", len(ss.waiting))
+
+	ss.emit(CarEvent{
+		Event:       eventQueued,
+		CarID:       car.CarID,
+		Class:       car.Class,
+		Electric:    car.Electric,
+		Subscriber:  car.Pass != nil,
+		TimeStamp:   ss.clock.Now().Unix(),
+		Entrance:    &car.Entrance,
+		QueueLength: ss.queueLength(),
+	})
 
-	ss.scheduleLeave(carID, car.Spot)
+	ss.schedule(ss.clock.Now().Add(ss.generatePatience()), kindRenege, car.CarID)
 }
 
-// droveAwayCar создает событие, когда автомобиль не заезжает на парковку.
-func (ss *Session) droveAwayCar(carID string) {
-	err := ss.sem.Acquire(ss.ctx, 1)
-	if err != nil {
+// renegeCar убирает из очереди машину, которая не дождалась места.
+// Если машина уже заехала на парковку, ничего не делает.
+func (ss *Session) renegeCar(carID string) {
+	car, ok := ss.car[carID]
+	if !ok || car.State != eventQueued {
 		return
 	}
-	defer ss.sem.Release(1)
 
-	event := CarEvent{
-		Event:     "drove-away",
-		CarID:     carID,
-		TimeStamp: ss.timer.elapsedTime.Unix(),
-	}
+	ss.dequeue(carID)
+	ss.removeCar(car)
 
-	delete(ss.car, carID)
+	ss.log.Debug("car reneged", "car_id", carID, "time", ss.clock.Now())
 
-	ss.log.Debug("car drove away", "car_id", carID, "time", ss.timer.elapsedTime)
+	ss.emit(CarEvent{
+		Event:       eventReneged,
+		CarID:       carID,
+		Class:       car.Class,
+		Electric:    car.Electric,
+		Subscriber:  car.Pass != nil,
+		TimeStamp:   ss.clock.Now().Unix(),
+		QueueLength: ss.queueLength(),
+	})
+}
 
-	ss.eventChan <- event
+// serveQueue ставит на освободившиеся места машины из очереди в порядке прибытия.
+// Машина, для которой нет подходящего места, пропускает следующих.
+func (ss *Session) serveQueue() {
+	for _, carID := range append([]string(nil), ss.waiting...) {
+		ss.parkCar(ss.car[carID])
+	}
 }
 
-// scheduleLeave планирует выезд автомобиля.
-func (ss *Session) scheduleLeave(carID string, spot *models.ParkingPoint) {
-	if !ss.isRunning() {
-		return
+// dequeue убирает машину из очереди на въезд.
+func (ss *Session) dequeue(carID string) {
+	for i, id := range ss.waiting {
+		if id == carID {
+			ss.waiting = append(ss.waiting[:i], ss.waiting[i+1:]...)
+			return
+		}
 	}
+}
 
-	delay := ss.generateLeaveDelay()
+// queueLength возвращает текущую длину очереди на въезд.
+func (ss *Session) queueLength() *int {
+	length := len(ss.waiting)
+	return &length
+}
 
-	ss.mu.Lock()
+// droveAwayCar создает событие, когда автомобиль не заезжает на парковку. Машина должна быть в ss.car.
+func (ss *Session) droveAwayCar(carID string) {
 	car := ss.car[carID]
+	ss.removeCar(car)
 
-	time := customTimer.AfterFunc(delay, func() {
-		ss.mu.Lock()
+	ss.log.Debug("car drove away", "car_id", carID, "time", ss.clock.Now())
 
-		if !ss.isRunning() || ss.ctx.Err() != nil {
-			ss.log.Debug("session stopped or context cancelled, stop leaving car", "car_id", carID)
-			ss.mu.Unlock()
-			return
-		}
+	ss.emit(CarEvent{
+		Event:      eventDroveAway,
+		CarID:      carID,
+		Class:      car.Class,
+		Electric:   car.Electric,
+		Subscriber: car.Pass != nil,
+		TimeStamp:  ss.clock.Now().Unix(),
+	})
+}
 
-		car.Price = ss.calculateParkingCost(ss.timer.elapsedTime, car.EnterTime)
+// leaveCar освобождает место и отправляет событие о выезде автомобиля с парковки.
+func (ss *Session) leaveCar(carID string) {
+	car, ok := ss.car[carID]
+	if !ok {
+		return
+	}
 
-		ss.parking.ReleaseSpot(spot)
+	now := ss.clock.Now()
+	parkingPrice := ss.pricing.WithMultiplier(car.Multiplier).Cost(car.EnterTime, now)
+	if car.Pass != nil {
+		// стоянка оплачена абонементом
+		parkingPrice = money.New(0, parkingPrice.Currency)
+	}
+	car.Price = parkingPrice
+
+	// при динамической тарификации отдельно учитывается разница с обычной стоимостью
+	var multiplier *float64
+	var surcharge *money.Money
+	if ss.surge != nil && car.Pass == nil {
+		base := ss.pricing.Cost(car.EnterTime, now)
+		diff := parkingPrice.Sub(base)
+		multiplier, surcharge = &car.Multiplier, &diff
+	}
+
+	// зарядка оплачивается отдельно по тарифу за кВт·ч
+	var energy *float64
+	var chargingPrice *money.Money
+	if car.Spot.IsCharger() {
+		delivered := ss.deliveredEnergy(car.Energy, now.Sub(car.EnterTime))
+		price := ss.parking.EnergyTariff.Mul(delivered)
+		energy, chargingPrice = &delivered, &price
+		car.Price = car.Price.Add(price)
+	}
 
-		ss.sendLeaveParkEvent(carID)
-		ss.mu.Unlock()
+	path := ss.parking.GetPathFromSpot(car.Spot)
+	car.Exit = ss.exitOf(path)
+
+	ss.parking.ReleaseSpot(car.Spot)
+	ss.removeCar(car)
+
+	ss.log.Debug("car left parking", "car_id", carID, "time", now, "price", car.Price.String(), "spot", car.Spot)
+
+	ss.emit(CarEvent{
+		Event:         eventLeave,
+		CarID:         carID,
+		Class:         car.Class,
+		Electric:      car.Electric,
+		Subscriber:    car.Pass != nil,
+		Charger:       car.Spot.IsCharger(),
+		ParkX:         &car.Spot.X,
+		ParkY:         &car.Spot.Y,
+		TimeStamp:     now.Unix(),
+		Price:         &car.Price,
+		ParkingPrice:  &parkingPrice,
+		ChargingPrice: chargingPrice,
+		Energy:        energy,
+		Path:          pathPoints(path),
+		Exit:          &car.Exit,
+		Multiplier:    multiplier,
+		Surcharge:     surcharge,
 	})
+	ss.updateRate()
 
-	car.Timer = time
-	ss.mu.Unlock()
-	time.Start()
+	ss.serveQueue()
 }
 
-// sendLeaveParkEvent отправляет событие о выезде автомобиля с парковки.
-func (ss *Session) sendLeaveParkEvent(carID string) {
-	car := ss.car[carID]
+// exitOf возвращает выезд, которым заканчивается маршрут.
+// Если маршрут не найден, машина уезжает через первый выезд (или въезд, если выездов нет).
+func (ss *Session) exitOf(path *models.Path) models.PathPoint {
+	if path.IsValid && len(path.Points) > 0 {
+		return path.Points[len(path.Points)-1]
+	}
 
-	event := CarEvent{
-		Event:     "leave",
-		CarID:     carID,
-		ParkX:     &car.Spot.X,
-		ParkY:     &car.Spot.Y,
-		TimeStamp: ss.timer.elapsedTime.Unix(),
-		Price:     &car.Price,
+	if len(ss.parking.Exits) > 0 {
+		return ss.parking.Exits[0]
 	}
 
-	delete(ss.car, carID)
+	return ss.parking.Entrances[0]
+}
 
-	ss.log.Debug("car left parking", "car_id", carID, "time", ss.timer.elapsedTime, "price", car.Price, "spot", car.Spot)
+// pathPoints возвращает точки маршрута или nil, если маршрут не найден.
+func pathPoints(path *models.Path) []models.PathPoint {
+	if path == nil || !path.IsValid {
+		return nil
+	}
 
-	ss.eventChan <- event
+	return path.Points
 }
 
-func (ss *Session) eventLoop() {
-	for {
-		select {
-
-		case event, ok := <-ss.eventChan:
-			if !ok {
-				ss.log.Debug("event channel closed, stopping event loop")
-				return
-			}
+// emit учитывает событие в статистике и передает его в цикл отправки. Вызывается под ss.mu.
+func (ss *Session) emit(event CarEvent) {
+	ss.stats.observe(event)
+	if ss.exporter != nil {
+		ss.exporter.WriteEvent(event)
+	}
+	ss.send(event)
+}
 
-			data, err := json.Marshal(&event)
-			if err != nil {
-				log.Println("error while marshaling event: ", err)
-				continue
-			}
+// emitRate учитывает изменение ставок в статистике и передает его в цикл отправки. Вызывается под ss.mu.
+func (ss *Session) emitRate(event RateEvent) {
+	ss.stats.observeRate(event)
+	if ss.exporter != nil {
+		ss.exporter.WriteRate(event)
+	}
+	ss.send(event)
+}
 
-			ss.log.Debug("sending event", "event", event.Event, "car_id", event.CarID, "time", event.TimeStamp)
+// emitSession передает событие состояния сессии в цикл отправки. Вызывается под ss.mu.
+func (ss *Session) emitSession(event SessionEvent) {
+	ss.send(event)
+}
 
-			ss.client.Send(data)
-		case <-ss.ctx.Done():
-			ss.log.Debug("session stopped, stopping event loop")
-			return
-		}
+// emitStats передает текущие показатели сессии в цикл отправки. Вызывается под ss.mu.
+func (ss *Session) emitStats(event string) {
+	if len(ss.subscribers) == 0 {
+		return
 	}
+
+	stats := ss.stats.live(event, ss.clock.StartTime().Unix(), ss.clock.Now().Unix(), ss.parking.Capacity())
+	stats.Strategy = ss.strategy
+	ss.send(stats)
 }
@@ -0,0 +1,141 @@
+package simulation
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+// waitTimeout - сколько тест ждет событий сессии.
+const waitTimeout = 5 * time.Second
+
+// testStart - модельное время начала тестовых симуляций.
+var testStart = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// newTestParams создает параметры симуляции: машина приезжает каждую модельную минуту и стоит 12 минут
+// на парковке с тремя местами.
+func newTestParams() *InitParams {
+	seed := uint64(42)
+
+	return &InitParams{
+		Parking: &models.Parking{
+			Name:        "1: Центр",
+			Address:     "ул. Пушкина, д. Колотушкина",
+			Width:       4,
+			Height:      4,
+			DayTariff:   test.NewMoney(10000),
+			NightTariff: test.NewMoney(10000),
+			TimeZone:    "UTC",
+			Cells: [][]models.ParkingCell{
+				{".", "P", "P", "P"},
+				{".", ".", ".", "."},
+				{"D", "D", ".", "."},
+				{".", "I", "O", "."},
+			},
+		},
+		ArrivalConfig:     &ArrivalConfig{Type: "discrete", DiscreteTime: 1, ParkingProb: 1},
+		ParkingTimeConfig: &ParkingTimeConfig{Type: "discrete", DiscreteTime: 12},
+		StartTime:         testStart.Unix(),
+		Seed:              &seed,
+	}
+}
+
+// recordingSender запоминает все полученные сообщения.
+type recordingSender struct {
+	mu       sync.Mutex
+	messages [][]byte
+	parsed   []recordedEvent // сообщения, разобранные как события
+}
+
+// Send нужна для имплементации интерфейса EventSender.
+func (s *recordingSender) Send(data []byte) {
+	var event recordedEvent
+	json.Unmarshal(data, &event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, append([]byte(nil), data...))
+	s.parsed = append(s.parsed, event)
+}
+
+// events возвращает полученные сообщения, разобранные как события.
+func (s *recordingSender) events() []recordedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]recordedEvent(nil), s.parsed...)
+}
+
+// message возвращает i-е полученное сообщение.
+func (s *recordingSender) message(i int) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.messages[i]
+}
+
+// count возвращает, сколько получено событий типа name.
+func (s *recordingSender) count(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, event := range s.parsed {
+		if event.Event == name {
+			count++
+		}
+	}
+
+	return count
+}
+
+// waitFor ждет, пока получатель не получит событие, подходящее под match, и возвращает его номер.
+func (s *recordingSender) waitFor(t *testing.T, match func(event recordedEvent) bool) int {
+	t.Helper()
+
+	index := -1
+	require.Eventually(t, func() bool {
+		for i, event := range s.events() {
+			if match(event) {
+				index = i
+				return true
+			}
+		}
+		return false
+	}, waitTimeout, 5*time.Millisecond)
+
+	return index
+}
+
+// slowSender запоминает сообщения, как recordingSender, но тратит на каждое delay: так ведет себя медленное соединение.
+type slowSender struct {
+	recordingSender
+	delay time.Duration
+}
+
+// Send нужна для имплементации интерфейса EventSender.
+func (s *slowSender) Send(data []byte) {
+	time.Sleep(s.delay)
+	s.recordingSender.Send(data)
+}
+
+// blockingSender не возвращается из Send, пока тест не закроет release: так ведет себя зависшее соединение.
+type blockingSender struct {
+	release chan struct{}
+}
+
+// Send нужна для имплементации интерфейса EventSender.
+func (s *blockingSender) Send([]byte) {
+	<-s.release
+}
+
+// isEvent возвращает условие для waitFor: событие типа name.
+func isEvent(name string) func(event recordedEvent) bool {
+	return func(event recordedEvent) bool { return event.Event == name }
+}
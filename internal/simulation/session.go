@@ -1,16 +1,20 @@
 package simulation
 
 import (
+	"container/heap"
 	"context"
 	"log/slog"
+	"math/rand/v2"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/sync/semaphore"
+	"golang.org/x/xerrors"
 
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
 	"github.com/PIRSON21/parking/internal/models"
+	"github.com/PIRSON21/parking/internal/pricing"
 )
 
 type EventSender interface {
@@ -18,50 +22,157 @@ type EventSender interface {
 }
 
 // Session описывает сессию пользователя.
+//
+// Симуляция построена на дискретных событиях: все прибытия, заезды и выезды
+// лежат в очереди с приоритетом и происходят ровно в запланированное модельное время.
+// Модельные часы не зависят от реального времени, их ход задает Pacer.
 type Session struct {
-	log        *slog.Logger
-	mu         sync.Mutex
-	state      string // "running", "paused", "stopped"
-	ctx        context.Context
-	cancel     context.CancelFunc
-	client     EventSender
-	parking    *models.ParkingLot
-	car        map[string]*models.SimulatedCar
-	timer      *Timer
-	sem        *semaphore.Weighted
-	arrivalCfg *ArrivalConfig
-	parkingCfg *ParkingTimeConfig
-	eventChan  chan CarEvent
-	pauseCh    chan struct{}
-}
-
-// Timer имеет данные о времени симуляции.
-type Timer struct {
-	startTime   time.Time
-	elapsedTime time.Time
-	ticker      *time.Ticker
+	log            *slog.Logger
+	mu             sync.Mutex
+	state          string // "running", "paused", "stopped"
+	started        bool
+	ctx            context.Context
+	cancel         context.CancelFunc
+	controller     EventSender                 // клиент, управляющий симуляцией; nil, пока он отключен
+	subscribers    map[EventSender]*subscriber // получатели событий: управляющий клиент и наблюдатели
+	parking        *models.ParkingLot
+	pricing        *pricing.Engine // тарификация стоянки по тарифам парковки
+	car            map[string]*models.SimulatedCar
+	clock          *Clock
+	queue          eventQueue
+	seq            uint64
+	stats          *collector
+	seed           uint64
+	rnd            *rand.Rand    // собственный источник случайных чисел сессии
+	strategy       string        // название стратегии выбора парковочного места
+	autoPark       bool          // машины заезжают сразу, не дожидаясь команды клиента
+	horizon        time.Time     // модельное время окончания симуляции (если задано)
+	sampleInterval time.Duration // шаг замеров загрузки (если задан)
+	statsInterval  time.Duration // шаг событий "stats" (если задан)
+	arrivalCfg     *ArrivalConfig
+	parkingCfg     *ParkingTimeConfig
+	chargingCfg    *ChargingConfig
+	queueCfg       *QueueConfig         // очередь на въезд; если не задана, машины без места сразу уезжают
+	surge          *PricingPolicy       // динамическая тарификация; если не задана, ставки не меняются
+	subscriberCfg  *SubscriberConfig    // абоненты; если не заданы, все машины - разовые посетители
+	passes         []*models.SeasonPass // абонементы абонентов
+	multiplier     float64              // текущий множитель ставок динамической тарификации
+	waiting        []string             // id машин в очереди на въезд в порядке прибытия
+	classes        []VehicleClassConfig // классы транспорта; если не заданы, все машины - легковые
+	speed          float64              // множитель скорости относительно DefaultSpeed
+	skipTo         time.Time            // модельное время, до которого идет перемотка (если задано)
+	pausedByDetach bool                 // симуляцию приостановили из-за отключения клиента
+	eventChan      chan interface{}
+	looping        bool          // запущен цикл отправки
+	sent           chan struct{} // закрывается, когда цикл отправки завершился
+	sentOnce       sync.Once
+	loops          sync.WaitGroup // горутины отправки получателям
+	flushed        chan struct{}  // закрывается, когда все события остановленной сессии переданы получателям
+	flushOnce      sync.Once
+	wake           chan struct{}
+	exporter       *Exporter // выгрузка событий и замеров пакетного прогона (если задана)
 }
 
 // ArrivalConfig описывает данные моделирования.
+//
+// Все временные параметры задаются в минутах модельного времени.
 type ArrivalConfig struct {
-	Type         string  `json:"type" validate:"oneof=exponential normal uniform discrete"` // "exponential", "normal", "uniform", "discrete"
-	Lambda       float64 `json:"lambda,omitempty" validate:"omitempty,lte=1,gte=0.1"`       // Для экспоненциального распределения (интенсивность)
-	Mean         float64 `json:"mean,omitempty" validate:"omitempty,lte=15,gte=2"`          // Среднее значение для нормального распределения
-	StdDev       float64 `json:"std_dev,omitempty" validate:"omitempty,lte=15,gte=0.1"`     // Стандартное отклонение для нормального распределения
-	MinDelay     float64 `json:"min_delay,omitempty" validate:"omitempty,lte=15,gte=2"`     // Минимальная задержка для равномерного распределения
-	MaxDelay     float64 `json:"max_delay,omitempty" validate:"omitempty,lte=15,gte=2"`     // Максимальная задержка для равномерного распределения
-	DiscreteTime float64 `json:"discrete_time,omitempty" validate:"omitempty"`              // Время появления для дискретного типа
-	ParkingProb  float64 `json:"parking_prob" validate:"required,lte=1,gte=0"`              // Вероятность заезда автомобиля на парковку
+	Type         string  `json:"type" validate:"oneof=exponential normal uniform discrete profile"` // "exponential", "normal", "uniform", "discrete", "profile"
+	Lambda       float64 `json:"lambda,omitempty" validate:"omitempty,lte=1,gte=0.1"`               // Для экспоненциального распределения (интенсивность)
+	Mean         float64 `json:"mean,omitempty" validate:"omitempty,lte=15,gte=2"`                  // Среднее значение для нормального распределения
+	StdDev       float64 `json:"std_dev,omitempty" validate:"omitempty,lte=15,gte=0.1"`             // Стандартное отклонение для нормального распределения
+	MinDelay     float64 `json:"min_delay,omitempty" validate:"omitempty,lte=15,gte=2"`             // Минимальная задержка для равномерного распределения
+	MaxDelay     float64 `json:"max_delay,omitempty" validate:"omitempty,lte=15,gte=2"`             // Максимальная задержка для равномерного распределения
+	DiscreteTime float64 `json:"discrete_time,omitempty" validate:"omitempty,gte=1,lte=1440"`       // Время появления для дискретного типа
+	ParkingProb  float64 `json:"parking_prob" validate:"required,lte=1,gte=0"`                      // Вероятность заезда автомобиля на парковку
+	// Веса выбора въезда в порядке обхода топологии по строкам. Если не заданы, въезды равновероятны.
+	EntranceWeights []float64 `json:"entrance_weights,omitempty" validate:"omitempty,dive,gte=0"`
+	EVShare         float64   `json:"ev_share,omitempty" validate:"omitempty,gte=0,lte=1"` // Доля электромобилей в потоке
+	// Интенсивность потока по времени суток (и дням недели) для типа "profile".
+	Profile []IntensityPeriod `json:"profile,omitempty" validate:"omitempty,dive"`
 }
 
+// IntensityPeriod - отрезок профиля интенсивности потока машин.
+//
+// Период действует с часа FromHour до часа ToHour модельного времени в часовом поясе парковки в дни недели Weekdays
+// (0 - воскресенье, как в time.Weekday); без Weekdays - каждый день.
+// Периоды не должны пересекаться; в модельный момент вне всех периодов машины не приезжают.
+type IntensityPeriod struct {
+	FromHour int     `json:"from_hour" validate:"gte=0,lte=23"`
+	ToHour   int     `json:"to_hour" validate:"gte=1,lte=24"`
+	Rate     float64 `json:"rate" validate:"gte=0,lte=10"` // Интенсивность потока (машин в минуту)
+	Weekdays []int   `json:"weekdays,omitempty" validate:"omitempty,dive,gte=0,lte=6"`
+}
+
+// ParkingTimeConfig описывает распределение времени стоянки.
+//
+// Все временные параметры задаются в минутах модельного времени.
 type ParkingTimeConfig struct {
-	Type         string  `json:"type" validate:"oneof=exponential normal uniform discrete"` // "exponential", "normal", "uniform", "discrete"
-	Lambda       float64 `json:"lambda,omitempty" validate:"omitempty,lte=1,gte=0.1"`       // Для экспоненциального распределения
-	Mean         float64 `json:"mean,omitempty" validate:"omitempty,lte=15,gte=2"`          // Среднее время стоянки
-	StdDev       float64 `json:"std_dev,omitempty" validate:"omitempty,lte=15,gte=0.1"`     // Стандартное отклонение для нормального распределения
-	MinDuration  float64 `json:"min_delay,omitempty" validate:"omitempty,lte=15,gte=2"`     // Минимальная длительность для равномерного распределения
-	MaxDuration  float64 `json:"max_delay,omitempty" validate:"omitempty,lte=15,gte=2"`     // Максимальная длительность для равномерного распределения
-	DiscreteTime float64 `json:"discrete_time,omitempty" validate:"omitempty"`              // Дискретное значение длительности стоянки
+	Type         string  `json:"type" validate:"oneof=exponential normal uniform discrete"`   // "exponential", "normal", "uniform", "discrete"
+	Lambda       float64 `json:"lambda,omitempty" validate:"omitempty,lte=1,gte=0.1"`         // Для экспоненциального распределения
+	Mean         float64 `json:"mean,omitempty" validate:"omitempty,lte=15,gte=2"`            // Среднее время стоянки
+	StdDev       float64 `json:"std_dev,omitempty" validate:"omitempty,lte=15,gte=0.1"`       // Стандартное отклонение для нормального распределения
+	MinDuration  float64 `json:"min_delay,omitempty" validate:"omitempty,lte=15,gte=2"`       // Минимальная длительность для равномерного распределения
+	MaxDuration  float64 `json:"max_delay,omitempty" validate:"omitempty,lte=15,gte=2"`       // Максимальная длительность для равномерного распределения
+	DiscreteTime float64 `json:"discrete_time,omitempty" validate:"omitempty,gte=1,lte=1440"` // Дискретное значение длительности стоянки
+}
+
+// ChargingConfig описывает распределение энергии, которую электромобили хотят получить на зарядной станции.
+//
+// Энергия задается в кВт·ч, мощность станции - в кВт.
+type ChargingConfig struct {
+	Type           string  `json:"type" validate:"oneof=normal uniform discrete"`                // "normal", "uniform", "discrete"
+	Mean           float64 `json:"mean,omitempty" validate:"omitempty,lte=150,gte=1"`            // Средний объем для нормального распределения
+	StdDev         float64 `json:"std_dev,omitempty" validate:"omitempty,lte=150,gte=0.1"`       // Стандартное отклонение для нормального распределения
+	MinEnergy      float64 `json:"min_energy,omitempty" validate:"omitempty,lte=150,gte=1"`      // Минимальный объем для равномерного распределения
+	MaxEnergy      float64 `json:"max_energy,omitempty" validate:"omitempty,lte=150,gte=1"`      // Максимальный объем для равномерного распределения
+	DiscreteEnergy float64 `json:"discrete_energy,omitempty" validate:"omitempty,lte=150,gte=1"` // Объем для дискретного типа
+	Power          float64 `json:"power,omitempty" validate:"omitempty,lte=350,gte=1"`           // Мощность станции; если задана, объем ограничен временем стоянки
+}
+
+// QueueConfig описывает очередь машин на въезде, которые ждут освобождения места.
+type QueueConfig struct {
+	Capacity int                `json:"capacity" validate:"required,gte=1,lte=50"` // Сколько машин может ждать одновременно
+	Patience *ParkingTimeConfig `json:"patience" validate:"required"`              // Распределение времени ожидания (задается так же, как время стоянки)
+}
+
+// PricingPolicy описывает динамическую тарификацию по загрузке парковки.
+//
+// Почасовые ставки парковки умножаются на множитель, который зависит от доли занятых мест:
+// на пустой парковке действует EmptyMultiplier, при загрузке не ниже порога - множитель
+// последнего достигнутого порога, в остальное время - 1. Машина платит по множителю,
+// действовавшему в момент заезда. Максимум за сутки и минимальная плата не меняются.
+type PricingPolicy struct {
+	Thresholds      []SurgeThreshold `json:"thresholds" validate:"required,min=1,max=10,dive"`              // Пороги загрузки по возрастанию
+	EmptyMultiplier float64          `json:"empty_multiplier,omitempty" validate:"omitempty,gte=0.1,lte=1"` // Множитель ставок на пустой парковке; по умолчанию 1
+	// Эластичность спроса по цене: при множителе m вероятность заезда умножается на m^(-Elasticity).
+	Elasticity float64 `json:"elasticity,omitempty" validate:"omitempty,gte=0,lte=5"`
+}
+
+// SurgeThreshold - порог загрузки динамической тарификации.
+type SurgeThreshold struct {
+	Occupancy  float64 `json:"occupancy" validate:"gt=0,lte=1"`    // Доля занятых мест, с которой действует порог
+	Multiplier float64 `json:"multiplier" validate:"gte=1,lte=10"` // Множитель ставок
+}
+
+// SubscriberConfig описывает абонентов - владельцев месячных абонементов.
+//
+// Абонентов Count, и каждый из них может быть на парковке только один раз: прибытие, когда все абоненты
+// уже на парковке или в очереди, пропускается. Абоненты ездят легковыми машинами и не платят за стоянку,
+// зарядка оплачивается по тарифу. Если Reserved, за каждым абонентом закреплено обычное место,
+// которое не достается разовым посетителям; parking_prob потока - доля прибытий, когда абонент заезжает.
+type SubscriberConfig struct {
+	Count             int                `json:"count" validate:"required,gte=1,lte=100"` // Количество абонентов
+	Reserved          bool               `json:"reserved,omitempty"`                      // За абонентами закреплены места
+	ArrivalConfig     *ArrivalConfig     `json:"arrival_config" validate:"required"`      // Поток прибытий абонентов
+	ParkingTimeConfig *ParkingTimeConfig `json:"parking_time_config,omitempty"`           // Время стоянки абонентов; если не задано, используется общее
+}
+
+// VehicleClassConfig описывает класс транспорта в потоке машин.
+type VehicleClassConfig struct {
+	Class             models.VehicleClass `json:"class" validate:"oneof=car motorcycle van truck"`
+	Share             float64             `json:"share" validate:"required,gt=0"` // доля класса в потоке (веса нормируются по сумме)
+	ParkingTimeConfig *ParkingTimeConfig  `json:"parking_time_config,omitempty"`  // время стоянки класса; если не задано, используется общее
 }
 
 const (
@@ -70,116 +181,362 @@ const (
 	stateStopped = "stopped"
 )
 
-func NewSession(client EventSender, parking *models.Parking, startTime time.Time, arrivalCfg *ArrivalConfig, parkingCfg *ParkingTimeConfig, log *slog.Logger) *Session {
+// NewSession создает сессию симуляции по параметрам клиента. Ход модельного времени задает pacer.
+//
+// Одинаковые seed, парковка и конфигурации дают одинаковую последовательность случайных величин.
+func NewSession(client EventSender, params *InitParams, pacer Pacer, log *slog.Logger) *Session {
 	ctx, cancel := context.WithCancel(context.Background())
-	parkingLot := models.NewParkingLot(parking)
-	sem := semaphore.NewWeighted(20)
-
-	if startTime.IsZero() {
-		startTime = time.Now()
-	}
-
-	return &Session{
-		log:     log,
-		state:   stateStopped,
-		ctx:     ctx,
-		cancel:  cancel,
-		client:  client,
-		parking: parkingLot,
-		car:     make(map[string]*models.SimulatedCar),
-		timer: &Timer{
-			startTime:   startTime,
-			elapsedTime: startTime,
-			ticker:      nil,
-		},
-		sem:        sem,
-		arrivalCfg: arrivalCfg,
-		eventChan:  make(chan CarEvent, 100),
-		parkingCfg: parkingCfg,
-	}
-}
-
-func (ss *Session) Start() {
+	parkingLot := models.NewParkingLot(params.Parking)
+
+	// модельное время идет в часовом поясе парковки: от него зависят тарифы и профиль прибытий
+	startTime := time.Unix(params.StartTime, 0).In(params.Parking.Location())
+	if params.StartTime == 0 {
+		startTime = time.Now().In(params.Parking.Location())
+	}
+
+	seed := newSeed()
+	if params.Seed != nil {
+		seed = *params.Seed
+	}
+
+	if pacer == nil {
+		pacer = NewRealTimePacer(DefaultSpeed)
+	}
+
+	rnd := newRand(seed)
+
+	strategy, err := models.NewSpotStrategy(params.Strategy, rnd)
+	if err != nil {
+		log.Warn("using default spot strategy", slog.String("err", err.Error()))
+		strategy, _ = models.NewSpotStrategy("", rnd)
+	}
+	parkingLot.SetStrategy(strategy)
+
+	ss := &Session{
+		log:         log,
+		state:       stateStopped,
+		ctx:         ctx,
+		cancel:      cancel,
+		subscribers: make(map[EventSender]*subscriber),
+		parking:     parkingLot,
+		pricing:     pricing.NewEngine(params.Parking),
+		car:         make(map[string]*models.SimulatedCar),
+		clock:       NewClock(startTime, pacer),
+		stats:       newCollector(startTime.Unix(), params.Parking.Currency()),
+		seed:        seed,
+		rnd:         rnd,
+		strategy:    strategy.Name(),
+		arrivalCfg:  params.ArrivalConfig,
+		speed:       1,
+		eventChan:   make(chan interface{}, 100),
+		sent:        make(chan struct{}),
+		flushed:     make(chan struct{}),
+		wake:        make(chan struct{}, 1),
+		parkingCfg:  params.ParkingTimeConfig,
+		classes:     params.VehicleClasses,
+		chargingCfg: params.ChargingConfig,
+		queueCfg:    params.QueueConfig,
+		surge:       params.PricingPolicy,
+		multiplier:  1,
+	}
+
+	if params.Subscribers != nil {
+		ss.subscriberCfg = params.Subscribers
+		ss.passes = newPasses(parkingLot, params.Subscribers)
+		ss.stats.trackSubscribers(reservedSpots(ss.passes))
+	}
+
+	if params.StatsInterval > 0 {
+		ss.statsInterval = time.Duration(params.StatsInterval) * time.Minute
+	}
+
+	if client != nil {
+		ss.controller = client
+		ss.subscribe(client)
+	}
+
+	return ss
+}
+
+// Seed возвращает seed генератора случайных чисел сессии.
+func (ss *Session) Seed() uint64 {
+	return ss.seed
+}
+
+// Strategy возвращает название стратегии выбора парковочного места.
+func (ss *Session) Strategy() string {
+	return ss.strategy
+}
+
+// Start запускает симуляцию.
+func (ss *Session) Start() error {
+	const op = "simulation.session.Start"
+
 	ss.mu.Lock()
-	if ss.state != statePaused && ss.state != stateStopped {
-		ss.log.Error("session already running or stopped")
+	if ss.started {
+		ss.log.Error("session already started")
 		ss.mu.Unlock()
-		return
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationAlreadyStarted)
 	}
+	if ss.ctx.Err() != nil {
+		ss.mu.Unlock()
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationStopped)
+	}
+	ss.begin()
+	ss.looping = true
+	targets := ss.targets()
+	ss.mu.Unlock()
+
+	go ss.eventLoop(targets)
+	go ss.run()
+	ss.log.Info("session started", slog.String("state", stateRunning))
+
+	return nil
+}
+
+// begin запускает модельные часы и планирует первые события. Вызывается под ss.mu.
+func (ss *Session) begin() {
+	ss.started = true
 	ss.state = stateRunning
-	go ss.startTimer()
-	go ss.eventLoop()
+	ss.clock.Run()
 
-	ss.mu.Unlock()
-	go ss.scheduleCar()
-	ss.log.Info("session started", slog.String("state", ss.state))
+	now := ss.clock.Now()
+	ss.updateRate()
+	ss.schedule(now.Add(ss.generateArrivalDelay(ss.arrivalCfg)), kindArrival, "")
+	if ss.subscriberCfg != nil {
+		ss.schedule(now.Add(ss.generateArrivalDelay(ss.subscriberCfg.ArrivalConfig)), kindSubscriber, "")
+	}
+	if ss.sampleInterval > 0 {
+		ss.schedule(now, kindSample, "")
+	}
+	if ss.statsInterval > 0 {
+		ss.schedule(now.Add(ss.statsInterval), kindStats, "")
+	}
 }
 
-func (ss *Session) Pause() {
+// Pause приостанавливает ход модельного времени.
+func (ss *Session) Pause() error {
+	const op = "simulation.session.Pause"
+
 	ss.mu.Lock()
 	defer ss.mu.Unlock()
 
-	for _, car := range ss.car {
-		if car.Timer != nil {
-			car.Timer.Pause()
-		}
+	if ss.state != stateRunning {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotRunning)
 	}
 
 	ss.state = statePaused
+	ss.clock.Freeze()
+	ss.notify()
+
+	ss.log.Info("session paused", slog.String("state", ss.state), slog.Time("sim_time", ss.clock.Now()))
 
-	ss.timer.ticker.Stop()
-	ss.log.Info("session paused", slog.String("state", ss.state))
+	return nil
 }
 
-func (ss *Session) Resume() {
+// Resume продолжает приостановленную симуляцию.
+func (ss *Session) Resume() error {
+	const op = "simulation.session.Resume"
+
 	ss.mu.Lock()
+	defer ss.mu.Unlock()
 
 	if ss.state != statePaused {
-		ss.mu.Unlock()
-		return
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotPaused)
 	}
 
 	ss.state = stateRunning
+	ss.resumeClock()
+	ss.notify()
 
-	for _, car := range ss.car {
-		if car.Timer != nil {
-			car.Timer.Start()
-		}
-	}
-
-	go ss.startTimer()
-
-	ss.mu.Unlock()
-
-	go ss.scheduleCar()
 	ss.log.Info("session resumed", slog.String("state", ss.state))
+
+	return nil
 }
 
+// Stop останавливает симуляцию. Если она запускалась, получатели событий напоследок получают
+// итоговую сводку "summary".
 func (ss *Session) Stop() {
 	ss.mu.Lock()
 
 	if ss.state != stateStopped {
 		ss.state = stateStopped
-		ss.cancel()
+		ss.clock.Freeze()
 	}
-
-	for _, car := range ss.car {
-		if car.Timer != nil {
-			car.Timer.Stop()
-		}
+	if ss.started && ss.ctx.Err() == nil {
+		ss.emitStats(eventSummary)
 	}
+	ss.cancel()
+	if !ss.looping {
+		ss.closeSent()
+	}
+
 	ss.mu.Unlock()
-	ss.log.Info("session stopped", slog.String("state", ss.state))
+
+	ss.flushOnce.Do(func() {
+		go ss.flush()
+	})
+	ss.log.Info("session stopped", slog.String("state", stateStopped))
 }
 
-func (ss *Session) CheckPark(msg string) {
+// SetSpeed меняет скорость симуляции: multiplier модельных минут за реальную секунду.
+// Уже запланированные события происходят в свое модельное время, меняется только темп.
+func (ss *Session) SetSpeed(multiplier float64) error {
+	const op = "simulation.session.SetSpeed"
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if _, ok := ss.clock.pacer.(*RealTimePacer); !ok {
+		return xerrors.Errorf("%s: session is not real-time: %w", op, custErr.ErrInvalidSpeed)
+	}
+	if multiplier < MinSpeedMultiplier || multiplier > MaxSpeedMultiplier {
+		return xerrors.Errorf("%s: multiplier %v out of range: %w", op, multiplier, custErr.ErrInvalidSpeed)
+	}
+
+	ss.speed = multiplier
+	ss.clock.SetPacer(NewRealTimePacer(DefaultSpeed * multiplier))
+	ss.notify()
+
+	ss.log.Info("session speed changed", slog.Float64("speed", multiplier), slog.Time("sim_time", ss.clock.Now()))
+
+	ss.emitSession(SessionEvent{
+		Event:     eventSpeedChanged,
+		TimeStamp: ss.clock.Now().Unix(),
+		Speed:     ss.speed,
+	})
+
+	return nil
+}
+
+// SkipTo перематывает симуляцию вперед до модельного времени t: все события до t
+// обрабатываются сразу, без ожидания, а машины заезжают на парковку, не дожидаясь команды клиента.
+// Когда перемотка закончится, клиент получит "speed-changed".
+func (ss *Session) SkipTo(t time.Time) error {
+	const op = "simulation.session.SkipTo"
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if !ss.started || ss.state == stateStopped {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotStarted)
+	}
+	if !t.After(ss.clock.Now()) {
+		return xerrors.Errorf("%s: %v is not after %v: %w", op, t, ss.clock.Now(), custErr.ErrSkipBackwards)
+	}
+
+	// на время перемотки часы стоят: модельное время сдвигают только обработанные события,
+	// сколько бы реального времени ни заняла их отправка
+	ss.skipTo = t
+	ss.clock.Freeze()
+	ss.notify()
+
+	ss.log.Info("session skipping", slog.Time("from", ss.clock.Now()), slog.Time("to", t))
+
+	return nil
+}
+
+// finishSkip завершает перемотку: выставляет часы на ее конец и сообщает клиенту. Вызывается под ss.mu.
+func (ss *Session) finishSkip() {
+	ss.clock.AdvanceTo(ss.skipTo)
+	skipped := ss.skipTo.Unix()
+	ss.skipTo = time.Time{}
 	if ss.state == stateRunning {
-		args := strings.Split(msg, "park ")
-		for _, carID := range args {
-			if err := uuid.Validate(carID); err == nil {
-				ss.tryToPark(carID)
-			}
+		ss.clock.Run()
+	}
+
+	ss.emitSession(SessionEvent{
+		Event:     eventSpeedChanged,
+		TimeStamp: ss.clock.Now().Unix(),
+		Speed:     ss.speed,
+		SkippedTo: &skipped,
+	})
+}
+
+// CheckPark обрабатывает команду клиента "park <uuid>": машина доехала до въезда
+// и пытается заехать на парковку в текущий модельный момент.
+func (ss *Session) CheckPark(msg string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if !ss.isRunning() {
+		return
+	}
+
+	args := strings.Split(msg, "park ")
+	for _, carID := range args {
+		if err := uuid.Validate(carID); err != nil {
+			continue
 		}
+
+		car, ok := ss.car[carID]
+		if !ok || car.State != eventArrive {
+			continue
+		}
+
+		ss.schedule(ss.clock.Now(), kindPark, carID)
+	}
+
+	ss.notify()
+}
+
+// Park обрабатывает команду клиента "park": машины carIDs доехали до въезда
+// и пытаются заехать на парковку в текущий модельный момент.
+// Если хотя бы одна машина не ждет въезда, команда не выполняется.
+func (ss *Session) Park(carIDs ...string) error {
+	const op = "simulation.session.Park"
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if !ss.isRunning() {
+		return xerrors.Errorf("%s: %w", op, custErr.ErrSimulationNotRunning)
+	}
+
+	for _, carID := range carIDs {
+		car, ok := ss.car[carID]
+		if !ok || car.State != eventArrive {
+			return xerrors.Errorf("%s: car %q: %w", op, carID, custErr.ErrCarNotWaiting)
+		}
+	}
+
+	for _, carID := range carIDs {
+		ss.schedule(ss.clock.Now(), kindPark, carID)
+	}
+	ss.notify()
+
+	return nil
+}
+
+// Stopped проверяет, остановлена ли сессия.
+func (ss *Session) Stopped() bool {
+	return ss.ctx.Err() != nil
+}
+
+// Flushed возвращает канал, который закрывается, когда сессию остановили через Stop
+// и все ее события, включая итоговую сводку, переданы получателям.
+func (ss *Session) Flushed() <-chan struct{} {
+	return ss.flushed
+}
+
+// flush дожидается, пока события остановленной сессии будут переданы получателям.
+func (ss *Session) flush() {
+	<-ss.sent
+	ss.loops.Wait()
+	close(ss.flushed)
+}
+
+// closeSent сообщает горутинам отправки, что новых событий не будет.
+func (ss *Session) closeSent() {
+	ss.sentOnce.Do(func() {
+		close(ss.sent)
+	})
+}
+
+// resumeClock запускает модельные часы, если не идет перемотка: их запустит finishSkip. Вызывается под ss.mu.
+func (ss *Session) resumeClock() {
+	if ss.skipTo.IsZero() {
+		ss.clock.Run()
 	}
 }
 
@@ -187,22 +544,121 @@ func (ss *Session) isRunning() bool {
 	return ss.state == stateRunning && ss.ctx.Err() == nil
 }
 
-func (ss *Session) startTimer() {
-	ss.timer.ticker = time.NewTicker(1 * time.Second)
+// notify будит цикл симуляции после изменения состояния или очереди событий.
+func (ss *Session) notify() {
+	select {
+	case ss.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run - основной цикл симуляции. Достает события из очереди по порядку
+// и обрабатывает их, выжидая реальное время, которое требует Pacer (кроме перемотки).
+// Если задан horizon, сессия останавливается, когда модельное время до него дойдет.
+func (ss *Session) run() {
 	for {
-		select {
-		case _, ok := <-ss.timer.ticker.C:
-			if !ok {
+		ss.mu.Lock()
+
+		if ss.ctx.Err() != nil {
+			ss.mu.Unlock()
+			return
+		}
+
+		next := ss.queue.peek()
+		skipping := !ss.skipTo.IsZero()
+		if skipping && (next == nil || next.at.After(ss.skipTo)) {
+			ss.finishSkip()
+			ss.mu.Unlock()
+			continue
+		}
+
+		// во время перемотки события обрабатываются и на паузе
+		if (ss.state != stateRunning && !skipping) || next == nil {
+			ss.mu.Unlock()
+			if !ss.sleep(0) {
 				return
 			}
-			ss.mu.Lock()
-			if ss.state == stateRunning {
-				ss.timer.elapsedTime = ss.timer.elapsedTime.Add(1 * time.Minute)
-				slog.Info("timer ticked", slog.Time("elapsed_time", ss.timer.elapsedTime))
-			}
+			continue
+		}
+
+		if !ss.horizon.IsZero() && next.at.After(ss.horizon) {
+			ss.clock.AdvanceTo(ss.horizon)
+			ss.state = stateStopped
+			ss.clock.Freeze()
+			ss.cancel()
 			ss.mu.Unlock()
-		case <-ss.ctx.Done():
 			return
 		}
+
+		if delay := ss.clock.RealDelay(next.at); !skipping && delay > time.Millisecond {
+			ss.mu.Unlock()
+			if !ss.sleep(delay) {
+				return
+			}
+			continue
+		}
+
+		heap.Pop(&ss.queue)
+		ss.clock.AdvanceTo(next.at)
+		ss.handle(next)
+
+		ss.mu.Unlock()
+	}
+}
+
+// sleep ждет реальное время d (или без ограничения, если d == 0), пока сессию не разбудят.
+// Вернет false, если сессия остановлена.
+func (ss *Session) sleep(d time.Duration) bool {
+	var timeout <-chan time.Time
+	if d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case <-timeout:
+	case <-ss.wake:
+	case <-ss.ctx.Done():
+		return false
+	}
+
+	return true
+}
+
+// handle обрабатывает наступившее событие. Вызывается под ss.mu.
+func (ss *Session) handle(ev *scheduledEvent) {
+	switch ev.kind {
+	case kindArrival:
+		carID := ss.arriveCar(nil)
+		// при перемотке клиент не успевает прислать "park", машина заезжает сразу
+		if ss.autoPark || !ss.skipTo.IsZero() {
+			ss.schedule(ss.clock.Now(), kindPark, carID)
+		}
+		ss.schedule(ss.clock.Now().Add(ss.generateArrivalDelay(ss.arrivalCfg)), kindArrival, "")
+	case kindSubscriber:
+		// прибытие пропускается, если все абоненты уже на парковке
+		if pass := ss.absentPass(); pass != nil {
+			carID := ss.arriveCar(pass)
+			if ss.autoPark || !ss.skipTo.IsZero() {
+				ss.schedule(ss.clock.Now(), kindPark, carID)
+			}
+		}
+		ss.schedule(ss.clock.Now().Add(ss.generateArrivalDelay(ss.subscriberCfg.ArrivalConfig)), kindSubscriber, "")
+	case kindPark:
+		ss.tryToPark(ev.carID)
+	case kindLeave:
+		ss.leaveCar(ev.carID)
+	case kindRenege:
+		ss.renegeCar(ev.carID)
+	case kindStats:
+		ss.emitStats(eventStats)
+		ss.schedule(ss.clock.Now().Add(ss.statsInterval), kindStats, "")
+	case kindSample:
+		sample := ss.stats.sample(ss.clock.Now().Unix())
+		if ss.exporter != nil {
+			ss.exporter.WriteSample(sample)
+		}
+		ss.schedule(ss.clock.Now().Add(ss.sampleInterval), kindSample, "")
 	}
 }
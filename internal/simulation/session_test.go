@@ -0,0 +1,193 @@
+package simulation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSpeed(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Pacer      Pacer
+		Multiplier float64
+		Error      error
+	}{
+		{Name: "Success", Pacer: NewRealTimePacer(DefaultSpeed), Multiplier: 2},
+		{Name: "Max speed", Pacer: NewRealTimePacer(DefaultSpeed), Multiplier: MaxSpeedMultiplier},
+		{Name: "Zero speed", Pacer: NewRealTimePacer(DefaultSpeed), Multiplier: 0, Error: custErr.ErrInvalidSpeed},
+		{Name: "Negative speed", Pacer: NewRealTimePacer(DefaultSpeed), Multiplier: -1, Error: custErr.ErrInvalidSpeed},
+		{Name: "Speed over max", Pacer: NewRealTimePacer(DefaultSpeed), Multiplier: MaxSpeedMultiplier + 1, Error: custErr.ErrInvalidSpeed},
+		{Name: "Instant session", Pacer: InstantPacer{}, Multiplier: 2, Error: custErr.ErrInvalidSpeed},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := &recordingSender{}
+			session := NewSession(client, newTestParams(), tc.Pacer, slogdiscard.NewDiscardLogger())
+			require.NoError(t, session.Start())
+			t.Cleanup(session.Stop)
+
+			err := session.SetSpeed(tc.Multiplier)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+				assert.Equal(t, 1.0, session.speed)
+				return
+			}
+			require.NoError(t, err)
+
+			var event SessionEvent
+			require.NoError(t, json.Unmarshal(client.message(client.waitFor(t, isEvent(eventSpeedChanged))), &event))
+			assert.Equal(t, tc.Multiplier, event.Speed)
+			assert.Nil(t, event.SkippedTo)
+		})
+	}
+}
+
+func TestSkipTo(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Start  bool
+		Stop   bool
+		Target time.Time
+		Error  error
+	}{
+		{Name: "Success", Start: true, Target: testStart.Add(time.Hour)},
+		{Name: "Not started", Target: testStart.Add(time.Hour), Error: custErr.ErrSimulationNotStarted},
+		{Name: "Stopped", Start: true, Stop: true, Target: testStart.Add(time.Hour), Error: custErr.ErrSimulationNotStarted},
+		{Name: "Backwards", Start: true, Target: testStart.Add(-time.Minute), Error: custErr.ErrSkipBackwards},
+		{Name: "Current time", Start: true, Target: testStart, Error: custErr.ErrSkipBackwards},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := &recordingSender{}
+			session := NewSession(client, newTestParams(), NewRealTimePacer(DefaultSpeed), slogdiscard.NewDiscardLogger())
+			if tc.Start {
+				require.NoError(t, session.Start())
+			}
+			if tc.Stop {
+				session.Stop()
+			}
+			t.Cleanup(session.Stop)
+
+			err := session.SkipTo(tc.Target)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+				return
+			}
+			require.NoError(t, err)
+
+			var event SessionEvent
+			require.NoError(t, json.Unmarshal(client.message(client.waitFor(t, isEvent(eventSpeedChanged))), &event))
+			require.NotNil(t, event.SkippedTo)
+			assert.Equal(t, tc.Target.Unix(), *event.SkippedTo)
+		})
+	}
+}
+
+// TestSkipToSlowController проверяет, что медленный управляющий клиент получает все события перемотки по порядку,
+// хотя их больше, чем вмещает очередь получателя.
+func TestSkipToSlowController(t *testing.T) {
+	controller := &slowSender{delay: 200 * time.Microsecond}
+	session := NewSession(controller, newTestParams(), NewRealTimePacer(DefaultSpeed), slogdiscard.NewDiscardLogger())
+	require.NoError(t, session.Start())
+	t.Cleanup(session.Stop)
+
+	// машина приезжает каждую модельную минуту: за 10 часов приедет 600 машин
+	target := testStart.Add(10*time.Hour + 30*time.Second)
+	require.NoError(t, session.SkipTo(target))
+	skipped := controller.waitFor(t, isEvent(eventSpeedChanged))
+
+	events := controller.events()[:skipped]
+	require.Greater(t, len(events), subscriberBuffer)
+
+	// события каждой машины приходят в порядке arrive -> park -> leave или arrive -> drove-away
+	next := map[string][]string{
+		eventArrive: {eventPark, eventDroveAway},
+		eventPark:   {eventLeave},
+	}
+	last := make(map[string]string)
+	arrivals := 0
+	var timestamp int64
+	for _, event := range events {
+		if event.CarID == "" {
+			continue
+		}
+
+		assert.GreaterOrEqual(t, event.TimeStamp, timestamp, "events out of order")
+		assert.LessOrEqual(t, event.TimeStamp, target.Unix())
+		timestamp = event.TimeStamp
+
+		if prev, ok := last[event.CarID]; ok {
+			assert.Contains(t, next[prev], event.Event, "car %s: %s after %s", event.CarID, event.Event, prev)
+		} else {
+			assert.Equal(t, eventArrive, event.Event, "car %s: first event", event.CarID)
+			arrivals++
+		}
+		last[event.CarID] = event.Event
+	}
+	assert.Equal(t, 600, arrivals)
+}
+
+// TestStatsInterval проверяет, что события "stats" приходят, только если клиент задал их шаг.
+func TestStatsInterval(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Interval int
+		Expected int
+	}{
+		{Name: "Default", Interval: 0, Expected: 0},
+		{Name: "Every 10 minutes", Interval: 10, Expected: 6},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			params := newTestParams()
+			params.StatsInterval = tc.Interval
+
+			client := &recordingSender{}
+			session := NewSession(client, params, NewRealTimePacer(DefaultSpeed), slogdiscard.NewDiscardLogger())
+			require.NoError(t, session.Start())
+			t.Cleanup(session.Stop)
+
+			require.NoError(t, session.SkipTo(testStart.Add(time.Hour+30*time.Second)))
+			skipped := client.waitFor(t, isEvent(eventSpeedChanged))
+
+			stats := 0
+			for _, event := range client.events()[:skipped] {
+				if event.Event == eventStats {
+					stats++
+				}
+			}
+			assert.Equal(t, tc.Expected, stats)
+		})
+	}
+}
+
+// TestStatsStrategy проверяет, что "stats" и "summary" называют стратегию выбора места, по которой получены показатели.
+func TestStatsStrategy(t *testing.T) {
+	params := newTestParams()
+	params.Strategy = "fill-far-first"
+	params.StatsInterval = 10
+
+	client := &recordingSender{}
+	session := NewSession(client, params, NewRealTimePacer(DefaultSpeed), slogdiscard.NewDiscardLogger())
+	require.NoError(t, session.Start())
+
+	require.NoError(t, session.SkipTo(testStart.Add(time.Hour)))
+	client.waitFor(t, isEvent(eventSpeedChanged))
+	session.Stop()
+	<-session.Flushed()
+
+	for _, name := range []string{eventStats, eventSummary} {
+		var stats StatsEvent
+		require.NoError(t, json.Unmarshal(client.message(client.waitFor(t, isEvent(name))), &stats))
+		assert.Equal(t, "fill-far-first", stats.Strategy, name)
+	}
+}
@@ -0,0 +1,81 @@
+package simulation
+
+import (
+	"sort"
+
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// eventSnapshot - полное состояние сессии, которое клиент получает при переподключении.
+const eventSnapshot = "snapshot"
+
+// Snapshot - состояние сессии в модельный момент времени.
+type Snapshot struct {
+	Event     string        `json:"event"`      // "snapshot"
+	TimeStamp int64         `json:"timestamp"`  // текущее модельное время
+	StartTime int64         `json:"start_time"` // модельное время начала симуляции
+	State     string        `json:"state"`      // "running", "paused", "stopped"
+	Started   bool          `json:"started"`    // симуляция запускалась командой "start"
+	Speed     float64       `json:"speed"`      // множитель скорости относительно DefaultSpeed
+	Seed      uint64        `json:"seed"`
+	Strategy  string        `json:"strategy"`
+	Occupied  int           `json:"occupied"` // количество занятых мест
+	Cars      []CarSnapshot `json:"cars"`     // машины на парковке и перед ней
+	Queue     []string      `json:"queue"`    // id машин в очереди на въезд в порядке прибытия
+}
+
+// CarSnapshot - состояние машины в снимке сессии.
+type CarSnapshot struct {
+	CarID     string              `json:"car_id"`
+	State     string              `json:"state"` // "arrive" - ждет въезда, "queued" - в очереди, "park" - на месте
+	Class     models.VehicleClass `json:"class"`
+	Electric  bool                `json:"electric,omitempty"`
+	Entrance  models.PathPoint    `json:"entrance"`
+	ParkX     *int                `json:"park_x,omitempty"`     // х координата занятого места
+	ParkY     *int                `json:"park_y,omitempty"`     // y координата занятого места
+	Charger   bool                `json:"charger,omitempty"`    // место с зарядной станцией
+	EnterTime *int64              `json:"enter_time,omitempty"` // модельное время заезда на место
+}
+
+// snapshot собирает снимок состояния сессии. Вызывается под ss.mu.
+func (ss *Session) snapshot() *Snapshot {
+	snap := &Snapshot{
+		Event:     eventSnapshot,
+		TimeStamp: ss.clock.Now().Unix(),
+		StartTime: ss.clock.StartTime().Unix(),
+		State:     ss.state,
+		Started:   ss.started,
+		Speed:     ss.speed,
+		Seed:      ss.seed,
+		Strategy:  ss.strategy,
+		Cars:      make([]CarSnapshot, 0, len(ss.car)),
+		Queue:     append([]string{}, ss.waiting...),
+	}
+
+	for _, car := range ss.car {
+		carSnap := CarSnapshot{
+			CarID:    car.CarID,
+			State:    car.State,
+			Class:    car.Class,
+			Electric: car.Electric,
+			Entrance: car.Entrance,
+		}
+
+		if car.Spot != nil {
+			x, y, enter := car.Spot.X, car.Spot.Y, car.EnterTime.Unix()
+			carSnap.ParkX = &x
+			carSnap.ParkY = &y
+			carSnap.Charger = car.Spot.IsCharger()
+			carSnap.EnterTime = &enter
+			snap.Occupied++
+		}
+
+		snap.Cars = append(snap.Cars, carSnap)
+	}
+
+	sort.Slice(snap.Cars, func(i, j int) bool {
+		return snap.Cars[i].CarID < snap.Cars[j].CarID
+	})
+
+	return snap
+}
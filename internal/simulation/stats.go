@@ -0,0 +1,471 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+
+	"github.com/PIRSON21/parking/internal/lib/money"
+)
+
+// defaultSampleInterval - шаг замеров загрузки парковки по умолчанию (в минутах).
+const defaultSampleInterval = 15
+
+// dwellHistMinutes - сколько минутных корзин в гистограмме времени стоянки; более долгие стоянки
+// попадают в последнюю корзину.
+const dwellHistMinutes = 24 * 60
+
+// Report - итоговая статистика прогона симуляции.
+type Report struct {
+	StartTime       int64             `json:"start_time"`       // модельное время начала
+	EndTime         int64             `json:"end_time"`         // модельное время окончания
+	Seed            uint64            `json:"seed"`             // seed генератора случайных чисел
+	Strategy        string            `json:"strategy"`         // стратегия выбора парковочного места
+	Capacity        int               `json:"capacity"`         // количество парковочных мест
+	Arrivals        int               `json:"arrivals"`         // сколько машин появилось
+	Parked          int               `json:"parked"`           // сколько машин заехало
+	DroveAway       int               `json:"drove_away"`       // сколько машин проехало мимо
+	Queued          int               `json:"queued"`           // сколько машин вставало в очередь на въезд
+	Reneged         int               `json:"reneged"`          // сколько машин не дождалось места в очереди
+	PeakQueue       int               `json:"peak_queue"`       // максимальная длина очереди на въезд
+	Left            int               `json:"left"`             // сколько машин уехало с парковки
+	Revenue         money.Money       `json:"revenue"`          // выручка по уехавшим машинам
+	ChargingRevenue money.Money       `json:"charging_revenue"` // часть выручки за зарядку электромобилей
+	Energy          float64           `json:"energy"`           // энергия, отпущенная зарядными станциями, кВт·ч
+	MeanDwell       float64           `json:"mean_dwell"`       // среднее время стоянки уехавших машин в минутах
+	DwellP50        float64           `json:"dwell_p50"`        // медиана времени стоянки в минутах
+	DwellP90        float64           `json:"dwell_p90"`        // 90-й перцентиль времени стоянки в минутах
+	DwellP95        float64           `json:"dwell_p95"`        // 95-й перцентиль времени стоянки в минутах
+	RejectionRate   float64           `json:"rejection_rate"`   // доля машин, проехавших мимо или не дождавшихся места
+	Utilisation     float64           `json:"utilisation"`      // средняя по времени доля занятых мест
+	PeakOccupancy   int               `json:"peak_occupancy"`   // максимальное количество занятых мест
+	Occupancy       []OccupancySample `json:"occupancy"`        // замеры загрузки парковки
+	// RateChanges, Surcharge - число изменений ставок и итог надбавок к стоимости стоянки
+	// (при скидках - отрицательный); только при динамической тарификации.
+	RateChanges int          `json:"rate_changes,omitempty"`
+	Surcharge   *money.Money `json:"surcharge,omitempty"`
+	// ReservedSpots, Subscribers, WalkIns - закрепленные за абонентами места и раздельная статистика
+	// абонентов и разовых посетителей; только если в симуляции есть абоненты.
+	ReservedSpots int           `json:"reserved_spots,omitempty"`
+	Subscribers   *SegmentStats `json:"subscribers,omitempty"`
+	WalkIns       *SegmentStats `json:"walk_ins,omitempty"`
+}
+
+// SegmentStats - статистика по части потока машин: абонентам или разовым посетителям.
+type SegmentStats struct {
+	Arrivals      int         `json:"arrivals"`       // сколько машин появилось
+	Parked        int         `json:"parked"`         // сколько машин заехало
+	Left          int         `json:"left"`           // сколько машин уехало с парковки
+	Revenue       money.Money `json:"revenue"`        // выручка по уехавшим машинам
+	Occupied      int         `json:"occupied"`       // занято мест на момент замера
+	PeakOccupancy int         `json:"peak_occupancy"` // максимальное количество занятых мест
+	Utilisation   float64     `json:"utilisation"`    // средняя по времени доля всех мест парковки, занятых этими машинами
+}
+
+// OccupancySample - замер загрузки парковки в модельный момент времени.
+type OccupancySample struct {
+	TimeStamp   int64 `json:"timestamp"`
+	Occupied    int   `json:"occupied"`
+	Queued      int   `json:"queued"`                // длина очереди на въезд
+	Subscribers *int  `json:"subscribers,omitempty"` // сколько мест занято абонентами
+}
+
+// StatsEvent - текущие показатели сессии: "stats" по ходу симуляции и "summary" при ее остановке.
+type StatsEvent struct {
+	Event           string       `json:"event"`                  // "stats", "summary"
+	TimeStamp       int64        `json:"timestamp"`              // модельное время замера
+	Strategy        string       `json:"strategy"`               // стратегия выбора парковочного места
+	Capacity        int          `json:"capacity"`               // количество парковочных мест
+	Occupied        int          `json:"occupied"`               // занято мест сейчас
+	PeakOccupancy   int          `json:"peak_occupancy"`         // максимальное количество занятых мест
+	QueueLength     int          `json:"queue_length"`           // длина очереди на въезд сейчас
+	Arrivals        int          `json:"arrivals"`               // сколько машин появилось
+	Parked          int          `json:"parked"`                 // сколько машин заехало
+	DroveAway       int          `json:"drove_away"`             // сколько машин проехало мимо
+	Reneged         int          `json:"reneged"`                // сколько машин не дождалось места в очереди
+	Left            int          `json:"left"`                   // сколько машин уехало с парковки
+	Revenue         money.Money  `json:"revenue"`                // выручка по уехавшим машинам
+	ChargingRevenue money.Money  `json:"charging_revenue"`       // часть выручки за зарядку электромобилей
+	RejectionRate   float64      `json:"rejection_rate"`         // доля машин, проехавших мимо или не дождавшихся места
+	Utilisation     float64      `json:"utilisation"`            // средняя по времени доля занятых мест
+	MeanDwell       float64      `json:"mean_dwell"`             // среднее время стоянки уехавших машин в минутах
+	DwellP50        float64      `json:"dwell_p50"`              // медиана времени стоянки в минутах
+	DwellP90        float64      `json:"dwell_p90"`              // 90-й перцентиль времени стоянки в минутах
+	DwellP95        float64      `json:"dwell_p95"`              // 95-й перцентиль времени стоянки в минутах
+	Multiplier      *float64     `json:"multiplier,omitempty"`   // текущий множитель ставок (при динамической тарификации)
+	RateChanges     int          `json:"rate_changes,omitempty"` // сколько раз менялись ставки
+	Surcharge       *money.Money `json:"surcharge,omitempty"`    // итог надбавок к стоимости стоянки
+	// Subscribers, WalkIns - раздельная статистика абонентов и разовых посетителей.
+	Subscribers *SegmentStats `json:"subscribers,omitempty"`
+	WalkIns     *SegmentStats `json:"walk_ins,omitempty"`
+}
+
+// collector накапливает статистику по событиям сессии.
+type collector struct {
+	arrivals   int
+	parked     int
+	droveAway  int
+	queued     int
+	reneged    int
+	queueLen   int // текущая длина очереди на въезд
+	peakQueue  int
+	left       int
+	revenue    money.Money
+	charging   money.Money // выручка за зарядку
+	energy     float64     // отпущенная энергия, кВт·ч
+	dwellTotal int64       // суммарное время стоянки уехавших машин в секундах
+	dwells     []int64     // время стоянки каждой уехавшей машины в секундах
+	dwellHist  []int       // сколько машин простояло целое число минут (индекс); для перцентилей в "stats"
+	occupied   int
+	peak       int
+	area       int64 // интеграл занятых мест по времени (место-секунды)
+	lastChange int64 // время последнего изменения загрузки
+	enteredAt  map[string]int64
+	samples    []OccupancySample
+	multiplier *float64     // текущий множитель ставок (при динамической тарификации)
+	rates      int          // сколько раз менялись ставки
+	surcharge  *money.Money // итог надбавок динамической тарификации
+	reserved   int          // мест закреплено за абонентами
+	// subscribers, walkIns - счетчики абонентов и разовых посетителей; nil, если абонентов нет.
+	subscribers *segment
+	walkIns     *segment
+}
+
+// segment накапливает статистику по части потока машин.
+type segment struct {
+	arrivals int
+	parked   int
+	left     int
+	revenue  money.Money
+	occupied int
+	peak     int
+	area     int64 // интеграл занятых мест по времени (место-секунды)
+}
+
+// stats возвращает статистику части потока за отрезок длиной duration секунд на парковке
+// вместимостью capacity.
+func (s *segment) stats(duration int64, capacity int) *SegmentStats {
+	stats := &SegmentStats{
+		Arrivals:      s.arrivals,
+		Parked:        s.parked,
+		Left:          s.left,
+		Revenue:       s.revenue,
+		Occupied:      s.occupied,
+		PeakOccupancy: s.peak,
+	}
+	if capacity > 0 && duration > 0 {
+		stats.Utilisation = float64(s.area) / float64(int64(capacity)*duration)
+	}
+
+	return stats
+}
+
+// newCollector создает сборщик статистики с начала модельного времени start.
+// Выручка считается в валюте currency.
+func newCollector(start int64, currency string) *collector {
+	return &collector{
+		revenue:    money.New(0, currency),
+		charging:   money.New(0, currency),
+		lastChange: start,
+		enteredAt:  make(map[string]int64),
+		dwellHist:  make([]int, dwellHistMinutes+1),
+	}
+}
+
+// trackSubscribers включает раздельный учет абонентов и разовых посетителей.
+// reserved - сколько мест закреплено за абонентами.
+func (c *collector) trackSubscribers(reserved int) {
+	c.reserved = reserved
+	c.subscribers = &segment{revenue: money.New(0, c.revenue.Currency)}
+	c.walkIns = &segment{revenue: money.New(0, c.revenue.Currency)}
+}
+
+// segmentOf возвращает счетчики части потока, к которой относится событие event,
+// или nil, если абоненты не учитываются отдельно.
+func (c *collector) segmentOf(event CarEvent) *segment {
+	if event.Subscriber {
+		return c.subscribers
+	}
+
+	return c.walkIns
+}
+
+// accumulate добавляет к интегралу загрузки отрезок до момента timestamp.
+func (c *collector) accumulate(timestamp int64) {
+	if timestamp > c.lastChange {
+		elapsed := timestamp - c.lastChange
+		c.area += int64(c.occupied) * elapsed
+		if c.subscribers != nil {
+			c.subscribers.area += int64(c.subscribers.occupied) * elapsed
+			c.walkIns.area += int64(c.walkIns.occupied) * elapsed
+		}
+		c.lastChange = timestamp
+	}
+}
+
+// observe учитывает событие в статистике.
+func (c *collector) observe(event CarEvent) {
+	if event.QueueLength != nil {
+		c.queueLen = *event.QueueLength
+		if c.queueLen > c.peakQueue {
+			c.peakQueue = c.queueLen
+		}
+	}
+
+	switch event.Event {
+	case eventArrive:
+		c.arrivals++
+	case eventDroveAway:
+		c.droveAway++
+	case eventQueued:
+		c.queued++
+	case eventReneged:
+		c.reneged++
+	case eventPark:
+		c.accumulate(event.TimeStamp)
+		c.parked++
+		c.occupied++
+		if c.occupied > c.peak {
+			c.peak = c.occupied
+		}
+		c.enteredAt[event.CarID] = event.TimeStamp
+	case eventLeave:
+		c.accumulate(event.TimeStamp)
+		c.left++
+		c.occupied--
+		if event.Price != nil {
+			c.revenue = c.revenue.Add(*event.Price)
+		}
+		if event.ChargingPrice != nil {
+			c.charging = c.charging.Add(*event.ChargingPrice)
+		}
+		if event.Energy != nil {
+			c.energy += *event.Energy
+		}
+		if event.Surcharge != nil {
+			c.addSurcharge(*event.Surcharge)
+		}
+		if entered, ok := c.enteredAt[event.CarID]; ok {
+			c.dwellTotal += event.TimeStamp - entered
+			c.dwells = append(c.dwells, event.TimeStamp-entered)
+			c.dwellHist[dwellBucket(event.TimeStamp-entered)]++
+			delete(c.enteredAt, event.CarID)
+		}
+	}
+
+	if seg := c.segmentOf(event); seg != nil {
+		seg.observe(event)
+	}
+}
+
+// observe учитывает событие event в счетчиках части потока.
+// Интеграл загрузки к этому моменту уже должен быть накоплен.
+func (s *segment) observe(event CarEvent) {
+	switch event.Event {
+	case eventArrive:
+		s.arrivals++
+	case eventPark:
+		s.parked++
+		s.occupied++
+		if s.occupied > s.peak {
+			s.peak = s.occupied
+		}
+	case eventLeave:
+		s.left++
+		s.occupied--
+		if event.Price != nil {
+			s.revenue = s.revenue.Add(*event.Price)
+		}
+	}
+}
+
+// observeRate учитывает изменение ставок динамической тарификации.
+func (c *collector) observeRate(event RateEvent) {
+	multiplier := event.Multiplier
+	c.multiplier = &multiplier
+	c.rates++
+	c.addSurcharge(money.New(0, event.Rate.Currency))
+}
+
+// addSurcharge добавляет надбавку к итогу надбавок.
+func (c *collector) addSurcharge(amount money.Money) {
+	if c.surcharge == nil {
+		c.surcharge = &money.Money{Currency: c.revenue.Currency}
+	}
+	*c.surcharge = c.surcharge.Add(amount)
+}
+
+// sample добавляет замер загрузки парковки и возвращает его.
+func (c *collector) sample(timestamp int64) OccupancySample {
+	sample := OccupancySample{
+		TimeStamp: timestamp,
+		Occupied:  c.occupied,
+		Queued:    c.queueLen,
+	}
+	if c.subscribers != nil {
+		occupied := c.subscribers.occupied
+		sample.Subscribers = &occupied
+	}
+	c.samples = append(c.samples, sample)
+
+	return sample
+}
+
+// report формирует итоговую статистику за отрезок модельного времени [start, end].
+func (c *collector) report(start, end int64, capacity int) *Report {
+	r := c.totals(start, end, capacity)
+	c.exactPercentiles(r)
+
+	return r
+}
+
+// totals формирует статистику за отрезок модельного времени [start, end] без перцентилей времени стоянки.
+func (c *collector) totals(start, end int64, capacity int) *Report {
+	c.accumulate(end)
+
+	r := &Report{
+		StartTime:       start,
+		EndTime:         end,
+		Capacity:        capacity,
+		Arrivals:        c.arrivals,
+		Parked:          c.parked,
+		DroveAway:       c.droveAway,
+		Queued:          c.queued,
+		Reneged:         c.reneged,
+		PeakQueue:       c.peakQueue,
+		Left:            c.left,
+		Revenue:         c.revenue,
+		ChargingRevenue: c.charging,
+		Energy:          c.energy,
+		PeakOccupancy:   c.peak,
+		Occupancy:       append([]OccupancySample{}, c.samples...),
+		RateChanges:     c.rates,
+	}
+	if c.surcharge != nil {
+		surcharge := *c.surcharge
+		r.Surcharge = &surcharge
+	}
+
+	if c.subscribers != nil {
+		r.ReservedSpots = c.reserved
+		r.Subscribers = c.subscribers.stats(end-start, capacity)
+		r.WalkIns = c.walkIns.stats(end-start, capacity)
+	}
+
+	if c.left > 0 {
+		r.MeanDwell = float64(c.dwellTotal) / float64(c.left) / 60
+	}
+
+	if c.arrivals > 0 {
+		r.RejectionRate = float64(c.droveAway+c.reneged) / float64(c.arrivals)
+	}
+
+	if capacity > 0 && end > start {
+		r.Utilisation = float64(c.area) / float64(int64(capacity)*(end-start))
+	}
+
+	return r
+}
+
+// exactPercentiles считает перцентили времени стоянки r по всем уехавшим машинам.
+// Сортирует все времена стоянки, поэтому вызывается только для итогов.
+func (c *collector) exactPercentiles(r *Report) {
+	if len(c.dwells) == 0 {
+		return
+	}
+
+	dwells := append([]int64{}, c.dwells...)
+	sort.Slice(dwells, func(i, j int) bool { return dwells[i] < dwells[j] })
+
+	r.DwellP50 = percentile(dwells, 50)
+	r.DwellP90 = percentile(dwells, 90)
+	r.DwellP95 = percentile(dwells, 95)
+}
+
+// histPercentiles считает перцентили времени стоянки r по гистограмме с точностью до минуты.
+// Время не зависит от количества уехавших машин, поэтому подходит для частых событий "stats".
+func (c *collector) histPercentiles(r *Report) {
+	if len(c.dwells) == 0 {
+		return
+	}
+
+	r.DwellP50 = histPercentile(c.dwellHist, len(c.dwells), 50)
+	r.DwellP90 = histPercentile(c.dwellHist, len(c.dwells), 90)
+	r.DwellP95 = histPercentile(c.dwellHist, len(c.dwells), 95)
+}
+
+// live формирует текущие показатели сессии на модельный момент now. Перцентили времени стоянки
+// точные только в итоговой сводке "summary", в "stats" - с точностью до минуты.
+func (c *collector) live(event string, start, now int64, capacity int) StatsEvent {
+	r := c.totals(start, now, capacity)
+	if event == eventSummary {
+		c.exactPercentiles(r)
+	} else {
+		c.histPercentiles(r)
+	}
+
+	return StatsEvent{
+		Event:           event,
+		TimeStamp:       now,
+		Capacity:        capacity,
+		Occupied:        c.occupied,
+		PeakOccupancy:   r.PeakOccupancy,
+		QueueLength:     c.queueLen,
+		Arrivals:        r.Arrivals,
+		Parked:          r.Parked,
+		DroveAway:       r.DroveAway,
+		Reneged:         r.Reneged,
+		Left:            r.Left,
+		Revenue:         r.Revenue,
+		ChargingRevenue: r.ChargingRevenue,
+		RejectionRate:   r.RejectionRate,
+		Utilisation:     r.Utilisation,
+		MeanDwell:       r.MeanDwell,
+		DwellP50:        r.DwellP50,
+		DwellP90:        r.DwellP90,
+		DwellP95:        r.DwellP95,
+		Multiplier:      c.multiplier,
+		RateChanges:     r.RateChanges,
+		Surcharge:       r.Surcharge,
+		Subscribers:     r.Subscribers,
+		WalkIns:         r.WalkIns,
+	}
+}
+
+// percentile возвращает перцентиль p отсортированных времен стоянки sorted (в секундах) в минутах
+// методом ближайшего ранга.
+func percentile(sorted []int64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return float64(sorted[rank-1]) / 60
+}
+
+// dwellBucket возвращает корзину гистограммы времени стоянки dwell (в секундах).
+func dwellBucket(dwell int64) int {
+	if dwell/60 > dwellHistMinutes {
+		return dwellHistMinutes
+	}
+
+	return int(dwell / 60)
+}
+
+// histPercentile возвращает перцентиль p по гистограмме hist из total времен стоянки в минутах
+// методом ближайшего ранга.
+func histPercentile(hist []int, total int, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	seen := 0
+	for minutes, count := range hist {
+		seen += count
+		if seen >= rank {
+			return float64(minutes)
+		}
+	}
+
+	return float64(len(hist) - 1)
+}
@@ -0,0 +1,59 @@
+package simulation
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectorDwellPercentiles(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Dwells        []int64    // время стоянки в секундах
+		ExpectedStats [3]float64 // p50, p90, p95 в "stats": с точностью до минуты
+		ExpectedFinal [3]float64 // p50, p90, p95 в "summary"
+	}{
+		{
+			Name: "No cars left",
+		},
+		{
+			Name:          "Whole minutes",
+			Dwells:        []int64{600, 120, 300, 1200, 60, 240, 180, 900, 360, 420},
+			ExpectedStats: [3]float64{5, 15, 20},
+			ExpectedFinal: [3]float64{5, 15, 20},
+		},
+		{
+			Name:          "Seconds",
+			Dwells:        []int64{90, 150, 30},
+			ExpectedStats: [3]float64{1, 2, 2},
+			ExpectedFinal: [3]float64{1.5, 2.5, 2.5},
+		},
+		{
+			Name:          "Longer than histogram",
+			Dwells:        []int64{60, 3 * 24 * 60 * 60},
+			ExpectedStats: [3]float64{1, dwellHistMinutes, dwellHistMinutes},
+			ExpectedFinal: [3]float64{1, 3 * 24 * 60, 3 * 24 * 60},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c := newCollector(0, "RUB")
+			for i, dwell := range tc.Dwells {
+				carID := strconv.Itoa(i)
+				c.observe(CarEvent{Event: eventPark, CarID: carID, TimeStamp: 0})
+				c.observe(CarEvent{Event: eventLeave, CarID: carID, TimeStamp: dwell})
+			}
+
+			stats := c.live(eventStats, 0, 1, 1)
+			assert.Equal(t, tc.ExpectedStats, [3]float64{stats.DwellP50, stats.DwellP90, stats.DwellP95})
+
+			summary := c.live(eventSummary, 0, 1, 1)
+			assert.Equal(t, tc.ExpectedFinal, [3]float64{summary.DwellP50, summary.DwellP90, summary.DwellP95})
+
+			report := c.report(0, 1, 1)
+			assert.Equal(t, tc.ExpectedFinal, [3]float64{report.DwellP50, report.DwellP90, report.DwellP95})
+		})
+	}
+}
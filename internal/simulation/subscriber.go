@@ -0,0 +1,70 @@
+package simulation
+
+import (
+	"github.com/PIRSON21/parking/internal/models"
+)
+
+// newPasses выдает абонементы абонентам из cfg. Если места закрепляются,
+// абоненты получают закрепленные места парковки parking по порядку номеров.
+func newPasses(parking *models.ParkingLot, cfg *SubscriberConfig) []*models.SeasonPass {
+	passes := make([]*models.SeasonPass, cfg.Count)
+
+	var spots []*models.ParkingPoint
+	if cfg.Reserved {
+		spots = parking.ReserveSpots(cfg.Count)
+	}
+
+	for i := range passes {
+		passes[i] = &models.SeasonPass{ID: i + 1}
+		if i < len(spots) {
+			passes[i].Spot = spots[i]
+		}
+	}
+
+	return passes
+}
+
+// reservedSpots считает закрепленные за абонентами места.
+func reservedSpots(passes []*models.SeasonPass) int {
+	count := 0
+	for _, pass := range passes {
+		if pass.Spot != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// absentPass возвращает абонемент абонента, которого нет на парковке, с наименьшим номером
+// или nil, если на парковке все абоненты.
+func (ss *Session) absentPass() *models.SeasonPass {
+	for _, pass := range ss.passes {
+		if !pass.OnSite {
+			return pass
+		}
+	}
+
+	return nil
+}
+
+// occupySpot занимает место для машины car: закрепленное место абонента или место, выбранное стратегией.
+func (ss *Session) occupySpot(car *models.SimulatedCar) (*models.ParkingPoint, bool) {
+	if car.Pass != nil && car.Pass.Spot != nil {
+		return car.Pass.Spot, ss.parking.OccupyReserved(car.Pass.Spot)
+	}
+
+	return ss.parking.OccupySpot(models.SpotRequest{
+		Entrance: car.Entrance,
+		Class:    car.Class,
+		Charger:  car.Electric,
+	})
+}
+
+// removeCar убирает машину из симуляции. Абонент снова может приехать.
+func (ss *Session) removeCar(car *models.SimulatedCar) {
+	if car.Pass != nil {
+		car.Pass.OnSite = false
+	}
+	delete(ss.car, car.CarID)
+}
@@ -0,0 +1,103 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPasses(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Config        SubscriberConfig
+		ExpectedSpots []*models.PathPoint
+	}{
+		{
+			Name:          "Without reserved spots",
+			Config:        SubscriberConfig{Count: 2},
+			ExpectedSpots: []*models.PathPoint{nil, nil},
+		},
+		{
+			Name:          "Reserved spots in row order",
+			Config:        SubscriberConfig{Count: 2, Reserved: true},
+			ExpectedSpots: []*models.PathPoint{{X: 0, Y: 1}, {X: 0, Y: 2}},
+		},
+		{
+			Name:          "Not enough spots to reserve",
+			Config:        SubscriberConfig{Count: 4, Reserved: true},
+			ExpectedSpots: []*models.PathPoint{{X: 0, Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 3}, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			passes := newPasses(models.NewParkingLot(newTestParams().Parking), &tc.Config)
+			require.Len(t, passes, len(tc.ExpectedSpots))
+
+			reserved := 0
+			for i, pass := range passes {
+				assert.Equal(t, i+1, pass.ID)
+				assert.False(t, pass.OnSite)
+
+				if tc.ExpectedSpots[i] == nil {
+					assert.Nil(t, pass.Spot)
+					continue
+				}
+				reserved++
+				require.NotNil(t, pass.Spot)
+				assert.Equal(t, *tc.ExpectedSpots[i], models.PathPoint{X: pass.Spot.X, Y: pass.Spot.Y})
+			}
+			assert.Equal(t, reserved, reservedSpots(passes))
+		})
+	}
+}
+
+// newSubscriberSession создает сессию с одним абонентом, за которым закреплено место.
+func newSubscriberSession() *Session {
+	params := newTestParams()
+	params.Subscribers = &SubscriberConfig{
+		Count:         1,
+		Reserved:      true,
+		ArrivalConfig: &ArrivalConfig{Type: "discrete", DiscreteTime: 10, ParkingProb: 1},
+	}
+
+	return NewSession(&recordingSender{}, params, InstantPacer{}, slogdiscard.NewDiscardLogger())
+}
+
+func TestOccupySpot(t *testing.T) {
+	ss := newSubscriberSession()
+	pass := ss.passes[0]
+	entrance := ss.parking.Entrances[0]
+
+	// разовым посетителям достаются только два незакрепленных места
+	for i := 0; i < 2; i++ {
+		spot, ok := ss.occupySpot(&models.SimulatedCar{Class: models.Car, Entrance: entrance})
+		require.True(t, ok)
+		assert.NotSame(t, pass.Spot, spot)
+	}
+	_, ok := ss.occupySpot(&models.SimulatedCar{Class: models.Car, Entrance: entrance})
+	assert.False(t, ok)
+
+	// абонент встает на свое место, даже когда остальные заняты
+	spot, ok := ss.occupySpot(&models.SimulatedCar{Class: models.Car, Entrance: entrance, Pass: pass})
+	require.True(t, ok)
+	assert.Same(t, pass.Spot, spot)
+}
+
+func TestAbsentPass(t *testing.T) {
+	ss := newSubscriberSession()
+	pass := ss.passes[0]
+	assert.Same(t, pass, ss.absentPass())
+
+	carID := ss.arriveCar(pass)
+	assert.True(t, pass.OnSite)
+	assert.Nil(t, ss.absentPass(), "абонент уже на парковке")
+
+	ss.removeCar(ss.car[carID])
+	assert.False(t, pass.OnSite)
+	assert.NotContains(t, ss.car, carID)
+	assert.Same(t, pass, ss.absentPass())
+}
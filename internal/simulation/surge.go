@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"math"
+)
+
+// multiplierFor возвращает множитель ставок при occupied занятых местах из capacity.
+func (p *PricingPolicy) multiplierFor(occupied, capacity int) float64 {
+	if occupied == 0 && p.EmptyMultiplier > 0 {
+		return p.EmptyMultiplier
+	}
+	if capacity == 0 {
+		return 1
+	}
+
+	share := float64(occupied) / float64(capacity)
+	multiplier := 1.0
+	for _, threshold := range p.Thresholds {
+		if share >= threshold.Occupancy {
+			multiplier = threshold.Multiplier
+		}
+	}
+
+	return multiplier
+}
+
+// demandFactor возвращает, во сколько раз меняется вероятность заезда при множителе ставок multiplier.
+// Со скидкой вероятность растет; если она становится больше 1, заезжают все водители.
+func (p *PricingPolicy) demandFactor(multiplier float64) float64 {
+	if p.Elasticity == 0 || multiplier <= 0 {
+		return 1
+	}
+
+	return math.Pow(multiplier, -p.Elasticity)
+}
+
+// updateRate пересчитывает множитель ставок по текущей загрузке парковки
+// и сообщает о его изменении. Вызывается под ss.mu.
+func (ss *Session) updateRate() {
+	if ss.surge == nil {
+		return
+	}
+
+	capacity := ss.parking.Capacity()
+	multiplier := ss.surge.multiplierFor(ss.stats.occupied, capacity)
+	if multiplier == ss.multiplier {
+		return
+	}
+
+	previous := ss.multiplier
+	ss.multiplier = multiplier
+	now := ss.clock.Now()
+
+	ss.log.Debug("rate changed", "time", now, "multiplier", multiplier, "occupied", ss.stats.occupied)
+
+	ss.emitRate(RateEvent{
+		Event:      eventRateChanged,
+		TimeStamp:  now.Unix(),
+		Multiplier: multiplier,
+		Previous:   previous,
+		Occupied:   ss.stats.occupied,
+		Capacity:   capacity,
+		Rate:       ss.pricing.WithMultiplier(multiplier).Rate(now),
+	})
+}
@@ -0,0 +1,63 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingPolicyMultiplier(t *testing.T) {
+	policy := &PricingPolicy{
+		Thresholds: []SurgeThreshold{
+			{Occupancy: 0.5, Multiplier: 1.5},
+			{Occupancy: 0.9, Multiplier: 2},
+		},
+		EmptyMultiplier: 0.5,
+	}
+
+	cases := []struct {
+		Name     string
+		Policy   *PricingPolicy
+		Occupied int
+		Capacity int
+		Expected float64
+	}{
+		{Name: "Empty parking", Policy: policy, Occupied: 0, Capacity: 10, Expected: 0.5},
+		{Name: "Empty parking without discount", Policy: &PricingPolicy{Thresholds: policy.Thresholds}, Occupied: 0, Capacity: 10, Expected: 1},
+		{Name: "Below first threshold", Policy: policy, Occupied: 4, Capacity: 10, Expected: 1},
+		{Name: "On first threshold", Policy: policy, Occupied: 5, Capacity: 10, Expected: 1.5},
+		{Name: "Between thresholds", Policy: policy, Occupied: 8, Capacity: 10, Expected: 1.5},
+		{Name: "On last threshold", Policy: policy, Occupied: 9, Capacity: 10, Expected: 2},
+		{Name: "Full parking", Policy: policy, Occupied: 10, Capacity: 10, Expected: 2},
+		{Name: "No spots", Policy: &PricingPolicy{Thresholds: policy.Thresholds}, Occupied: 0, Capacity: 0, Expected: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, tc.Policy.multiplierFor(tc.Occupied, tc.Capacity))
+		})
+	}
+}
+
+func TestPricingPolicyDemandFactor(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Elasticity float64
+		Multiplier float64
+		Expected   float64
+	}{
+		{Name: "Inelastic demand", Elasticity: 0, Multiplier: 2, Expected: 1},
+		{Name: "Base rate", Elasticity: 1.5, Multiplier: 1, Expected: 1},
+		{Name: "Surge", Elasticity: 1, Multiplier: 2, Expected: 0.5},
+		{Name: "Strong elasticity", Elasticity: 2, Multiplier: 2, Expected: 0.25},
+		{Name: "Discount", Elasticity: 1, Multiplier: 0.5, Expected: 2},
+		{Name: "Zero multiplier", Elasticity: 1, Multiplier: 0, Expected: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			policy := &PricingPolicy{Elasticity: tc.Elasticity}
+			assert.InDelta(t, tc.Expected, policy.demandFactor(tc.Multiplier), 1e-9)
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/PIRSON21/parking/internal/http-server/handler/user"
 	"github.com/PIRSON21/parking/internal/lib/api/request"
 	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/money"
 	"github.com/PIRSON21/parking/internal/models"
 	"github.com/jackc/pgx/v5/pgconn"
 	"golang.org/x/crypto/bcrypt"
@@ -51,7 +52,7 @@ func MustConnectDB(cfg *config.Config) *Storage {
 func (s *Storage) GetAdminParkings(search string) ([]*models.Parking, error) {
 	query := `
 			SELECT
-			    parking_id, parking_name, parking_address, parking_width, parking_height, day_tariff, night_tariff, parking_topology
+			    parking_id, parking_name, parking_address, parking_width, parking_height, day_tariff, night_tariff, energy_tariff, currency, time_zone, tariff_periods, tariff_rules, holidays, parking_topology
 			FROM parkings
 			WHERE parking_name ILIKE $1
     `
@@ -63,7 +64,7 @@ func (s *Storage) GetAdminParkings(search string) ([]*models.Parking, error) {
 func (s *Storage) GetManagerParkings(userID int, search string) ([]*models.Parking, error) {
 	query := `
 			SELECT
-			    parking_id, parking_name, parking_address, parking_width, parking_height, day_tariff, night_tariff, parking_topology
+			    parking_id, parking_name, parking_address, parking_width, parking_height, day_tariff, night_tariff, energy_tariff, currency, time_zone, tariff_periods, tariff_rules, holidays, parking_topology
 			FROM parkings
 			WHERE parking_name ILIKE $1 AND manager_id = $2
     `
@@ -94,12 +95,19 @@ func (s *Storage) fetchParkings(query string, args ...interface{}) ([]*models.Pa
 
 	for rows.Next() {
 		var parking models.Parking
-		var topology string
+		var topology, tariffPeriods, holidays string
+		var tariffRules sql.NullString
+		var tariffs tariffColumns
 
-		err = rows.Scan(&parking.ID, &parking.Name, &parking.Address, &parking.Width, &parking.Height, &parking.DayTariff, &parking.NightTariff, &topology)
+		err = rows.Scan(&parking.ID, &parking.Name, &parking.Address, &parking.Width, &parking.Height, &tariffs.day, &tariffs.night, &tariffs.energy, &tariffs.currency, &parking.TimeZone, &tariffPeriods, &tariffRules, &holidays, &topology)
 		if err != nil {
 			log.Printf("%s: error while reading rows: %v", op, err)
 		}
+		tariffs.apply(&parking)
+
+		if err = unmarshalTariffs(&parking, tariffPeriods, tariffRules, holidays); err != nil {
+			log.Printf("%s: error while unmarshalling tariffs: %v", op, err)
+		}
 
 		if err = json.Unmarshal([]byte(topology), &parking.Cells); err != nil {
 			log.Printf("%s: error while unmarshalling topology %q: %v", op, topology, err)
@@ -122,8 +130,8 @@ func (s *Storage) AddParking(parking *models.Parking) error {
 	tx, err := s.db.Begin()
 
 	stmt, err := s.db.Prepare(`
-		INSERT INTO parkings (parking_name, parking_address, parking_width, parking_height, day_tariff, night_tariff, parking_topology, manager_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO parkings (parking_name, parking_address, parking_width, parking_height, day_tariff, night_tariff, energy_tariff, currency, time_zone, tariff_periods, tariff_rules, holidays, parking_topology, manager_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING parking_id;
 	`)
 	if err != nil {
@@ -136,13 +144,16 @@ func (s *Storage) AddParking(parking *models.Parking) error {
 		topology = []byte("[]")
 	}
 
+	tariffPeriods, holidays := marshalTariffs(parking.TariffPeriods, parking.Holidays)
+	tariffRules := marshalTariffRules(parking.TariffRules)
+
 	var managerID sql.NullInt64
 	if parking.Manager != nil {
 		managerID.Int64 = int64(parking.Manager.ID)
 		managerID.Valid = true
 	}
 
-	err = stmt.QueryRow(&parking.Name, &parking.Address, &parking.Width, &parking.Height, &parking.DayTariff, &parking.NightTariff, &topology, &managerID).Scan(&parking.ID)
+	err = stmt.QueryRow(&parking.Name, &parking.Address, &parking.Width, &parking.Height, moneyAmount(parking.DayTariff), moneyAmount(parking.NightTariff), moneyAmount(parking.EnergyTariff), parking.Currency(), parking.Location().String(), &tariffPeriods, &tariffRules, &holidays, &topology, &managerID).Scan(&parking.ID)
 	if err != nil {
 		tx.Rollback()
 		var pgErr *pgconn.PgError
@@ -164,7 +175,7 @@ func (s *Storage) GetParkingByID(parkingID int, userID int) (*models.Parking, er
 
 	stmt, err := s.db.Prepare(`
 	SELECT
-	    parking_id, parking_name, parking_address, parking_width, parking_height, manager_id, day_tariff, night_tariff, parking_topology
+	    parking_id, parking_name, parking_address, parking_width, parking_height, manager_id, day_tariff, night_tariff, energy_tariff, currency, time_zone, tariff_periods, tariff_rules, holidays, parking_topology
 	FROM parkings
 	WHERE parking_id = $1;
 	`)
@@ -172,10 +183,12 @@ func (s *Storage) GetParkingByID(parkingID int, userID int) (*models.Parking, er
 		return nil, xerrors.Errorf("%s: error while preparing statement: %w", op, err)
 	}
 
-	var topology string
+	var topology, tariffPeriods, holidays string
+	var tariffRules sql.NullString
+	var tariffs tariffColumns
 	var parking models.Parking
 	var managerID sql.NullInt64
-	if err = stmt.QueryRow(parkingID).Scan(&parking.ID, &parking.Name, &parking.Address, &parking.Width, &parking.Height, &managerID, &parking.DayTariff, &parking.NightTariff, &topology); err != nil {
+	if err = stmt.QueryRow(parkingID).Scan(&parking.ID, &parking.Name, &parking.Address, &parking.Width, &parking.Height, &managerID, &tariffs.day, &tariffs.night, &tariffs.energy, &tariffs.currency, &parking.TimeZone, &tariffPeriods, &tariffRules, &holidays, &topology); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, custErr.ErrParkingNotFound
 		}
@@ -187,6 +200,12 @@ func (s *Storage) GetParkingByID(parkingID int, userID int) (*models.Parking, er
 		return nil, xerrors.Errorf("%s: error while unmarshalling parking topology: %w", op, err)
 	}
 
+	tariffs.apply(&parking)
+
+	if err = unmarshalTariffs(&parking, tariffPeriods, tariffRules, holidays); err != nil {
+		return nil, xerrors.Errorf("%s: error while unmarshalling parking tariffs: %w", op, err)
+	}
+
 	if managerID.Valid {
 		manID := int(managerID.Int64)
 		if userID != 0 && manID != userID {
@@ -565,6 +584,11 @@ func (s *Storage) UpdateParking(changes *parking.ParkingPatch, cellStruct []*mod
 		return nil, xerrors.Errorf("%s: error while begining transaction: %w", op, err)
 	}
 
+	if err = s.checkPatchCurrency(changes); err != nil {
+		tx.Rollback()
+		return nil, xerrors.Errorf("%s: %w", op, err)
+	}
+
 	var updates []string
 	var args []interface{}
 	idx := 1
@@ -586,12 +610,40 @@ func (s *Storage) UpdateParking(changes *parking.ParkingPatch, cellStruct []*mod
 	}
 	if changes.NightTariff != nil {
 		updates = append(updates, fmt.Sprintf("night_tariff = $%d", idx))
-		args = append(args, *changes.NightTariff)
+		args = append(args, changes.NightTariff.Amount)
 		idx++
 	}
 	if changes.DayTariff != nil {
 		updates = append(updates, fmt.Sprintf("day_tariff = $%d", idx))
-		args = append(args, *changes.DayTariff)
+		args = append(args, changes.DayTariff.Amount)
+		idx++
+	}
+	if changes.EnergyTariff != nil {
+		updates = append(updates, fmt.Sprintf("energy_tariff = $%d", idx))
+		args = append(args, changes.EnergyTariff.Amount)
+		idx++
+	}
+	if changes.TariffPeriods != nil || changes.Holidays != nil {
+		tariffPeriods, holidays := marshalTariffs(changes.TariffPeriods, changes.Holidays)
+		if changes.TariffPeriods != nil {
+			updates = append(updates, fmt.Sprintf("tariff_periods = $%d", idx))
+			args = append(args, tariffPeriods)
+			idx++
+		}
+		if changes.Holidays != nil {
+			updates = append(updates, fmt.Sprintf("holidays = $%d", idx))
+			args = append(args, holidays)
+			idx++
+		}
+	}
+	if changes.TariffRules != nil {
+		updates = append(updates, fmt.Sprintf("tariff_rules = $%d", idx))
+		args = append(args, marshalTariffRules(changes.TariffRules))
+		idx++
+	}
+	if changes.TimeZone != nil {
+		updates = append(updates, fmt.Sprintf("time_zone = $%d", idx))
+		args = append(args, *changes.TimeZone)
 		idx++
 	}
 	if changes.Width != nil {
@@ -651,6 +703,117 @@ func (s *Storage) UpdateParking(changes *parking.ParkingPatch, cellStruct []*mod
 	return parking, err
 }
 
+// tariffColumns - суммы тарифов парковки в том виде, в котором они хранятся в БД: в копейках и валюта отдельно.
+type tariffColumns struct {
+	day, night int64
+	energy     sql.NullInt64
+	currency   string
+}
+
+// apply записывает тарифы в parking.
+func (c *tariffColumns) apply(parking *models.Parking) {
+	parking.DayTariff = money.NewPtr(c.day, c.currency)
+	parking.NightTariff = money.NewPtr(c.night, c.currency)
+	if c.energy.Valid {
+		parking.EnergyTariff = money.NewPtr(c.energy.Int64, c.currency)
+	}
+}
+
+// moneyAmount возвращает сумму в копейках для записи в БД или NULL, если суммы нет.
+func moneyAmount(m *money.Money) sql.NullInt64 {
+	if m == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: m.Amount, Valid: true}
+}
+
+// checkPatchCurrency проверяет, что все суммы в изменениях парковки указаны в ее валюте.
+func (s *Storage) checkPatchCurrency(changes *parking.ParkingPatch) error {
+	const op = "storage.postgresql.checkPatchCurrency"
+
+	var amounts []*money.Money
+	amounts = append(amounts, changes.DayTariff, changes.NightTariff, changes.EnergyTariff)
+	for _, period := range changes.TariffPeriods {
+		amounts = append(amounts, period.Rate)
+	}
+	if changes.TariffRules != nil {
+		amounts = append(amounts, changes.TariffRules.DailyCap, changes.TariffRules.MinCharge)
+	}
+
+	var currency string
+	for _, amount := range amounts {
+		if amount == nil {
+			continue
+		}
+
+		if currency == "" {
+			if err := s.db.QueryRow(`SELECT currency FROM parkings WHERE parking_id = $1`, changes.ID).Scan(&currency); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return custErr.ErrParkingNotFound
+				}
+				return xerrors.Errorf("%s: error while getting parking currency: %w", op, err)
+			}
+		}
+
+		if amount.Currency != currency {
+			return custErr.ErrCurrencyMismatch
+		}
+	}
+
+	return nil
+}
+
+// marshalTariffs готовит тарифные периоды и праздники парковки к записи в JSONB-колонки.
+func marshalTariffs(periods []models.TariffPeriod, holidays []string) (string, string) {
+	periodsJSON, err := json.Marshal(periods)
+	if err != nil || periods == nil {
+		periodsJSON = []byte("[]")
+	}
+
+	holidaysJSON, err := json.Marshal(holidays)
+	if err != nil || holidays == nil {
+		holidaysJSON = []byte("[]")
+	}
+
+	return string(periodsJSON), string(holidaysJSON)
+}
+
+// marshalTariffRules готовит правила тарификации парковки к записи в JSONB-колонку.
+// Без правил записывается NULL.
+func marshalTariffRules(rules *models.TariffRules) sql.NullString {
+	if rules == nil {
+		return sql.NullString{}
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// unmarshalTariffs записывает в parking тарифные периоды, правила тарификации и праздники из JSONB-колонок.
+func unmarshalTariffs(parking *models.Parking, periods string, rules sql.NullString, holidays string) error {
+	if err := json.Unmarshal([]byte(periods), &parking.TariffPeriods); err != nil {
+		return xerrors.Errorf("tariff periods %q: %w", periods, err)
+	}
+
+	if rules.Valid {
+		parking.TariffRules = &models.TariffRules{}
+		if err := json.Unmarshal([]byte(rules.String), parking.TariffRules); err != nil {
+			return xerrors.Errorf("tariff rules %q: %w", rules.String, err)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(holidays), &parking.Holidays); err != nil {
+		return xerrors.Errorf("holidays %q: %w", holidays, err)
+	}
+
+	return nil
+}
+
 func (s *Storage) updateParkingCells(changes *parking.ParkingPatch, cellStruct []*models.ParkingCellStruct) error {
 	const op = "storage.postgresql.updateParkingCells"
 
@@ -666,3 +829,230 @@ func (s *Storage) updateParkingCells(changes *parking.ParkingPatch, cellStruct [
 
 	return nil
 }
+
+// CreateSimulationRun сохраняет в БД начало прогона симуляции: параметры, seed и стратегию.
+// Записывает в run его ID и время начала.
+func (s *Storage) CreateSimulationRun(run *models.SimulationRun) error {
+	const op = "storage.postgresql.CreateSimulationRun"
+
+	stmt, err := s.db.Prepare(`
+	INSERT INTO simulation_run (parking_id, manager_id, seed, strategy, params)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING run_id, started_at;
+	`)
+	if err != nil {
+		return xerrors.Errorf("%s: error while preparing statement: %w", op, err)
+	}
+
+	var parkingID sql.NullInt64
+	if run.ParkingID != nil {
+		parkingID.Int64 = int64(*run.ParkingID)
+		parkingID.Valid = true
+	}
+
+	err = stmt.QueryRow(parkingID, run.ManagerID, int64(run.Seed), run.Strategy, []byte(run.Params)).Scan(&run.ID, &run.StartedAt)
+	if err != nil {
+		return xerrors.Errorf("%s: error while executing statement: %w", op, err)
+	}
+
+	return nil
+}
+
+// AddSimulationEvents сохраняет в БД события прогона симуляции одной транзакцией.
+func (s *Storage) AddSimulationEvents(runID int, events []models.SimulationEvent) error {
+	const op = "storage.postgresql.AddSimulationEvents"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return xerrors.Errorf("%s: error while starting transaction: %w", op, err)
+	}
+
+	stmt, err := tx.Prepare(`
+	INSERT INTO simulation_event (run_id, seq, event_type, sim_time, car_id, payload)
+	VALUES ($1, $2, $3, $4, $5, $6);
+	`)
+	if err != nil {
+		tx.Rollback()
+		return xerrors.Errorf("%s: error while preparing statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	for _, event := range events {
+		_, err = stmt.Exec(runID, event.Seq, event.Type, event.TimeStamp, event.CarID, []byte(event.Payload))
+		if err != nil {
+			tx.Rollback()
+			return xerrors.Errorf("%s: error while executing statement: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return xerrors.Errorf("%s: error while committing transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// FinishSimulationRun сохраняет в БД итоговую сводку прогона симуляции и время его окончания.
+// Если сводки нет (симуляцию так и не запустили), сохраняется только время окончания.
+func (s *Storage) FinishSimulationRun(runID int, summary json.RawMessage) error {
+	const op = "storage.postgresql.FinishSimulationRun"
+
+	stmt, err := s.db.Prepare(`
+	UPDATE simulation_run
+	SET summary = $1, finished_at = NOW()
+	WHERE run_id = $2;
+	`)
+	if err != nil {
+		return xerrors.Errorf("%s: error while preparing statement: %w", op, err)
+	}
+
+	var summaryVal interface{}
+	if len(summary) > 0 {
+		summaryVal = []byte(summary)
+	}
+
+	if _, err = stmt.Exec(summaryVal, runID); err != nil {
+		return xerrors.Errorf("%s: error while executing statement: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetSimulationRuns получает из БД прогоны симуляции парковки parkingID, запущенные менеджером managerID,
+// от новых к старым. Параметры и события прогонов не загружаются.
+func (s *Storage) GetSimulationRuns(parkingID int, managerID int) ([]*models.SimulationRun, error) {
+	const op = "storage.postgresql.GetSimulationRuns"
+
+	stmt, err := s.db.Prepare(`
+	SELECT run_id, parking_id, manager_id, seed, strategy, summary, started_at, finished_at
+	FROM simulation_run
+	WHERE parking_id = $1 AND manager_id = $2
+	ORDER BY started_at DESC, run_id DESC;
+	`)
+	if err != nil {
+		return nil, xerrors.Errorf("%s: error while preparing statement: %w", op, err)
+	}
+
+	rows, err := stmt.Query(parkingID, managerID)
+	if err != nil {
+		return nil, xerrors.Errorf("%s: error while getting rows: %w", op, err)
+	}
+	defer rows.Close()
+
+	var runs []*models.SimulationRun
+
+	for rows.Next() {
+		var run models.SimulationRun
+		var runParkingID sql.NullInt64
+		var seed int64
+		var summary []byte
+		var finishedAt sql.NullTime
+
+		err = rows.Scan(&run.ID, &runParkingID, &run.ManagerID, &seed, &run.Strategy, &summary, &run.StartedAt, &finishedAt)
+		if err != nil {
+			return nil, xerrors.Errorf("%s: error while reading rows: %w", op, err)
+		}
+
+		fillSimulationRun(&run, runParkingID, seed, summary, finishedAt)
+		runs = append(runs, &run)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, xerrors.Errorf("%s: error with rows: %w", op, err)
+	}
+
+	return runs, nil
+}
+
+// GetSimulationRun получает из БД прогон симуляции runID вместе с параметрами.
+// Менеджеру managerID доступны только его прогоны, для чужих вернется custErr.ErrSimulationRunNotFound.
+func (s *Storage) GetSimulationRun(runID int, managerID int) (*models.SimulationRun, error) {
+	const op = "storage.postgresql.GetSimulationRun"
+
+	stmt, err := s.db.Prepare(`
+	SELECT run_id, parking_id, manager_id, seed, strategy, params, summary, started_at, finished_at
+	FROM simulation_run
+	WHERE run_id = $1 AND manager_id = $2;
+	`)
+	if err != nil {
+		return nil, xerrors.Errorf("%s: error while preparing statement: %w", op, err)
+	}
+
+	var run models.SimulationRun
+	var parkingID sql.NullInt64
+	var seed int64
+	var params, summary []byte
+	var finishedAt sql.NullTime
+
+	err = stmt.QueryRow(runID, managerID).Scan(&run.ID, &parkingID, &run.ManagerID, &seed, &run.Strategy, &params, &summary, &run.StartedAt, &finishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, custErr.ErrSimulationRunNotFound
+		}
+		return nil, xerrors.Errorf("%s: error while executing statement: %w", op, err)
+	}
+
+	run.Params = params
+	fillSimulationRun(&run, parkingID, seed, summary, finishedAt)
+
+	return &run, nil
+}
+
+// fillSimulationRun переносит в run поля прогона, которые в БД могут быть NULL.
+func fillSimulationRun(run *models.SimulationRun, parkingID sql.NullInt64, seed int64, summary []byte, finishedAt sql.NullTime) {
+	run.Seed = uint64(seed)
+
+	if parkingID.Valid {
+		id := int(parkingID.Int64)
+		run.ParkingID = &id
+	}
+
+	if len(summary) > 0 {
+		run.Summary = summary
+	}
+
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+}
+
+// GetSimulationEvents получает из БД события прогона симуляции runID в порядке их появления.
+func (s *Storage) GetSimulationEvents(runID int) ([]models.SimulationEvent, error) {
+	const op = "storage.postgresql.GetSimulationEvents"
+
+	stmt, err := s.db.Prepare(`
+	SELECT seq, event_type, sim_time, car_id, payload
+	FROM simulation_event
+	WHERE run_id = $1
+	ORDER BY seq;
+	`)
+	if err != nil {
+		return nil, xerrors.Errorf("%s: error while preparing statement: %w", op, err)
+	}
+
+	rows, err := stmt.Query(runID)
+	if err != nil {
+		return nil, xerrors.Errorf("%s: error while getting rows: %w", op, err)
+	}
+	defer rows.Close()
+
+	events := []models.SimulationEvent{}
+
+	for rows.Next() {
+		var event models.SimulationEvent
+		var payload []byte
+
+		if err = rows.Scan(&event.Seq, &event.Type, &event.TimeStamp, &event.CarID, &payload); err != nil {
+			return nil, xerrors.Errorf("%s: error while reading rows: %w", op, err)
+		}
+
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, xerrors.Errorf("%s: error with rows: %w", op, err)
+	}
+
+	return events, nil
+}
@@ -2,23 +2,293 @@ package postgresql
 
 import (
 	"database/sql"
-	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/pashagolub/pgxmock/v4"
+	"encoding/json"
+	"errors"
+	"regexp"
 	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestStorage_fetchParkings(t *testing.T) {
+// newMockStorage создает Storage поверх sqlmock.
+func newMockStorage(t *testing.T) (*Storage, sqlmock.Sqlmock) {
+	t.Helper()
+
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("error while creating mocks: %v", err)
 	}
+	t.Cleanup(func() { db.Close() })
 
-	s := &Storage{db}
+	return &Storage{db}, mock
+}
+
+func TestStorage_fetchParkings(t *testing.T) {
+	s, mock := newMockStorage(t)
 
 	query := `SELECT 
 		    parking_id, parking_name, parking_address, parking_width, parking_height, day_tariff, night_tariff, parking_topology
 		FROM parkings
 		WHERE parking_name ILIKE $1 AND manager_id = $2`
-	mock.ExpectPrepare(query)
-	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows())
+	mock.ExpectPrepare(regexp.QuoteMeta(query)).
+		ExpectQuery().
+		WithArgs("%Центр%", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"parking_id"}))
+
+	parkings, err := s.fetchParkings(query, "%Центр%", 1)
+	require.NoError(t, err)
+	assert.Empty(t, parkings)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var (
+	createRunQuery   = regexp.QuoteMeta(`INSERT INTO simulation_run (parking_id, manager_id, seed, strategy, params)`)
+	addEventsQuery   = regexp.QuoteMeta(`INSERT INTO simulation_event (run_id, seq, event_type, sim_time, car_id, payload)`)
+	finishRunQuery   = regexp.QuoteMeta(`UPDATE simulation_run`)
+	getRunsQuery     = regexp.QuoteMeta(`SELECT run_id, parking_id, manager_id, seed, strategy, summary, started_at, finished_at`)
+	getRunQuery      = regexp.QuoteMeta(`SELECT run_id, parking_id, manager_id, seed, strategy, params, summary, started_at, finished_at`)
+	getEventsQuery   = regexp.QuoteMeta(`SELECT seq, event_type, sim_time, car_id, payload`)
+	runStartedAt     = time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	runFinishedAt    = time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+	errTestDB        = errors.New("connection refused")
+	testRunParams    = json.RawMessage(`{"seed":42}`)
+	testRunSummary   = json.RawMessage(`{"event":"summary","arrivals":3}`)
+	testEventPayload = json.RawMessage(`{"event":"arrive","car_id":"a"}`)
+)
+
+func TestStorage_CreateSimulationRun(t *testing.T) {
+	cases := []struct {
+		Name      string
+		ParkingID *int
+		Arg       interface{}
+		Error     error
+	}{
+		{
+			Name:      "With parking",
+			ParkingID: test.NewInt(7),
+			Arg:       sql.NullInt64{Int64: 7, Valid: true},
+		},
+		{
+			Name: "Without parking",
+			Arg:  sql.NullInt64{},
+		},
+		{
+			Name:      "DB error",
+			ParkingID: test.NewInt(7),
+			Arg:       sql.NullInt64{Int64: 7, Valid: true},
+			Error:     errTestDB,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s, mock := newMockStorage(t)
+
+			query := mock.ExpectPrepare(createRunQuery).
+				ExpectQuery().
+				WithArgs(tc.Arg, 3, int64(42), "nearest-entrance", []byte(testRunParams))
+			if tc.Error != nil {
+				query.WillReturnError(tc.Error)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"run_id", "started_at"}).AddRow(5, runStartedAt))
+			}
+
+			run := &models.SimulationRun{
+				ParkingID: tc.ParkingID,
+				ManagerID: 3,
+				Seed:      42,
+				Strategy:  "nearest-entrance",
+				Params:    testRunParams,
+			}
+			err := s.CreateSimulationRun(run)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 5, run.ID)
+				assert.Equal(t, runStartedAt, run.StartedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorage_AddSimulationEvents(t *testing.T) {
+	events := []models.SimulationEvent{
+		{Seq: 1, Type: "arrive", TimeStamp: 100, CarID: "a", Payload: testEventPayload},
+		{Seq: 2, Type: "park", TimeStamp: 160, CarID: "a", Payload: testEventPayload},
+	}
+
+	cases := []struct {
+		Name     string
+		FailedAt int // номер события, на котором БД вернет ошибку; 0 - без ошибок
+	}{
+		{Name: "Success"},
+		{Name: "Rollback on error", FailedAt: 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s, mock := newMockStorage(t)
+
+			mock.ExpectBegin()
+			prepare := mock.ExpectPrepare(addEventsQuery)
+			for _, event := range events {
+				exec := prepare.ExpectExec().
+					WithArgs(5, event.Seq, event.Type, event.TimeStamp, event.CarID, []byte(event.Payload))
+				if event.Seq == tc.FailedAt {
+					exec.WillReturnError(errTestDB)
+					break
+				}
+				exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if tc.FailedAt != 0 {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			err := s.AddSimulationEvents(5, events)
+			if tc.FailedAt != 0 {
+				assert.ErrorIs(t, err, errTestDB)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorage_FinishSimulationRun(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Summary json.RawMessage
+		Arg     interface{}
+	}{
+		{Name: "With summary", Summary: testRunSummary, Arg: []byte(testRunSummary)},
+		{Name: "Never started", Summary: nil, Arg: nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s, mock := newMockStorage(t)
+
+			mock.ExpectPrepare(finishRunQuery).
+				ExpectExec().
+				WithArgs(tc.Arg, 5).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			assert.NoError(t, s.FinishSimulationRun(5, tc.Summary))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorage_GetSimulationRuns(t *testing.T) {
+	s, mock := newMockStorage(t)
+
+	mock.ExpectPrepare(getRunsQuery).
+		ExpectQuery().
+		WithArgs(7, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"run_id", "parking_id", "manager_id", "seed", "strategy", "summary", "started_at", "finished_at"}).
+			AddRow(6, 7, 3, int64(43), "random", nil, runStartedAt, nil).
+			AddRow(5, 7, 3, int64(42), "nearest-entrance", []byte(testRunSummary), runStartedAt, runFinishedAt))
+
+	runs, err := s.GetSimulationRuns(7, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.SimulationRun{
+		{ID: 6, ParkingID: test.NewInt(7), ManagerID: 3, Seed: 43, Strategy: "random", StartedAt: runStartedAt},
+		{ID: 5, ParkingID: test.NewInt(7), ManagerID: 3, Seed: 42, Strategy: "nearest-entrance", Summary: testRunSummary, StartedAt: runStartedAt, FinishedAt: &runFinishedAt},
+	}, runs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorage_GetSimulationRun(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Rows     *sqlmock.Rows
+		Expected *models.SimulationRun
+		Error    error
+	}{
+		{
+			Name: "Success",
+			Rows: sqlmock.NewRows([]string{"run_id", "parking_id", "manager_id", "seed", "strategy", "params", "summary", "started_at", "finished_at"}).
+				AddRow(5, nil, 3, int64(42), "nearest-entrance", []byte(testRunParams), []byte(testRunSummary), runStartedAt, runFinishedAt),
+			Expected: &models.SimulationRun{
+				ID: 5, ManagerID: 3, Seed: 42, Strategy: "nearest-entrance",
+				Params: testRunParams, Summary: testRunSummary, StartedAt: runStartedAt, FinishedAt: &runFinishedAt,
+			},
+		},
+		{
+			Name:  "Not found",
+			Rows:  sqlmock.NewRows([]string{"run_id"}),
+			Error: custErr.ErrSimulationRunNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s, mock := newMockStorage(t)
+
+			mock.ExpectPrepare(getRunQuery).
+				ExpectQuery().
+				WithArgs(5, 3).
+				WillReturnRows(tc.Rows)
+
+			run, err := s.GetSimulationRun(5, 3)
+			if tc.Error != nil {
+				assert.ErrorIs(t, err, tc.Error)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.Expected, run)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorage_GetSimulationEvents(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Rows     *sqlmock.Rows
+		Expected []models.SimulationEvent
+	}{
+		{
+			Name: "Success",
+			Rows: sqlmock.NewRows([]string{"seq", "event_type", "sim_time", "car_id", "payload"}).
+				AddRow(1, "arrive", int64(100), "a", []byte(testEventPayload)).
+				AddRow(2, "park", int64(160), "a", []byte(testEventPayload)),
+			Expected: []models.SimulationEvent{
+				{Seq: 1, Type: "arrive", TimeStamp: 100, CarID: "a", Payload: testEventPayload},
+				{Seq: 2, Type: "park", TimeStamp: 160, CarID: "a", Payload: testEventPayload},
+			},
+		},
+		{
+			Name:     "No events",
+			Rows:     sqlmock.NewRows([]string{"seq", "event_type", "sim_time", "car_id", "payload"}),
+			Expected: []models.SimulationEvent{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			s, mock := newMockStorage(t)
+
+			mock.ExpectPrepare(getEventsQuery).
+				ExpectQuery().
+				WithArgs(5).
+				WillReturnRows(tc.Rows)
+
+			events, err := s.GetSimulationEvents(5)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, events)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
 }
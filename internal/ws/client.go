@@ -1,7 +1,6 @@
 package ws
 
 import (
-	"fmt"
 	"log/slog"
 	"sync"
 	"time"
@@ -12,20 +11,50 @@ import (
 type Client struct {
 	// Conn - подключение к клиенту
 	Conn *websocket.Conn
-	// SendChan - канал для отправки сообщений клиенту
+	// SendChan - канал для отправки сообщений клиенту. Не закрывается: о конце соединения сообщает Done
 	SendChan chan []byte
-	Done     chan struct{}
-	once     sync.Once
+	// Done закрывается, когда соединение с клиентом закрыто
+	Done chan struct{}
+	once sync.Once
 }
 
 // Stop закрывает соединение с клиентом.
 func (c *Client) Stop() {
 	c.Conn.Close()
+	c.finish()
+}
+
+// finish сообщает циклам клиента и отправителям, что соединение закрыто. Done закрывается только один раз.
+func (c *Client) finish() {
 	c.once.Do(func() {
 		close(c.Done)
 	})
 }
 
+// closeTimeout - сколько Close ждет отправки очереди сообщений, прежде чем закрыть соединение сразу.
+const closeTimeout = 5 * time.Second
+
+// Close закрывает соединение с клиентом после отправки уже поставленных в очередь сообщений.
+func (c *Client) Close() {
+	timeout := time.After(closeTimeout)
+
+	select {
+	case c.SendChan <- nil:
+	case <-c.Done:
+		return
+	case <-timeout:
+		c.Stop()
+		return
+	}
+
+	// ждем, пока WriteLoop отправит очередь и закроет соединение
+	select {
+	case <-c.Done:
+	case <-timeout:
+		c.Stop()
+	}
+}
+
 // NewClient создает клиента сервера.
 func NewClient(conn *websocket.Conn) *Client {
 	send := make(chan []byte, 256)
@@ -48,7 +77,7 @@ func (c *Client) ReadLoop(log *slog.Logger, onMessage func(msg []byte)) {
 			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 				log.Error("error while reading from webSocket conn", slog.String("err", err.Error()))
 			}
-			close(c.SendChan)
+			c.finish()
 			return
 		}
 		onMessage(msg)
@@ -62,17 +91,19 @@ func (c *Client) WriteLoop(log *slog.Logger) {
 	log = log.With(slog.String("op", op))
 
 	defer c.Conn.Close()
-	defer c.once.Do(func() {
-		close(c.Done)
-	})
+	defer c.finish()
 
 	ticker := time.NewTicker(15 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case msg, ok := <-c.SendChan:
-			if !ok {
+		case <-c.Done:
+			return
+		case msg := <-c.SendChan:
+			// пустое сообщение ставит Close: очередь отправлена, соединение закрывается
+			if msg == nil {
+				c.Conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 				return
 			}
 
@@ -95,14 +126,11 @@ func (c *Client) WriteLoop(log *slog.Logger) {
 	}
 }
 
+// Send ставит сообщение в очередь отправки клиента. Если очередь заполнена, ждет, пока WriteLoop
+// ее разгрузит или соединение закроется: так симуляция не обгоняет управляющего клиента.
 func (c *Client) Send(msg []byte) {
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Println(r)
-		}
-	}()
 	select {
 	case c.SendChan <- msg:
-	default:
+	case <-c.Done:
 	}
 }
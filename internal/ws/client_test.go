@@ -0,0 +1,51 @@
+package ws_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/ws"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientCloseAfterDisconnect проверяет, что после обрыва соединения Close и Send сразу возвращаются,
+// сколько бы раз их ни вызвали.
+func TestClientCloseAfterDisconnect(t *testing.T) {
+	closed := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		log := slogdiscard.NewDiscardLogger()
+		client := ws.NewClient(conn)
+		go client.WriteLoop(log)
+		go client.ReadLoop(log, func([]byte) {})
+
+		<-client.Done
+		client.Close()
+		client.Close()
+		client.Send([]byte("late"))
+		close(closed)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	select {
+	case <-closed:
+	case <-time.After(readTimeout):
+		assert.Fail(t, "client is not closed")
+	}
+}
@@ -1,13 +1,15 @@
 package ws
 
 import (
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/PIRSON21/parking/internal/config"
+	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
 	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
 	custom_validator "github.com/PIRSON21/parking/internal/lib/validator"
 	"github.com/PIRSON21/parking/internal/models"
 	"github.com/PIRSON21/parking/internal/simulation"
@@ -19,17 +21,39 @@ import (
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
+	Subprotocols:    []string{Subprotocol},
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
 }
 
-func WebSocketHandler(log *slog.Logger, cfg *config.Config) http.HandlerFunc {
+// RunStore сохраняет прогоны симуляции и находит в БД парковки, к которым они относятся.
+type RunStore interface {
+	simulation.RunSaver
+	GetParkingByID(parkingID int, userID int) (*models.Parking, error)
+}
+
+// WebSocketHandler запускает симуляцию для клиента по WebSocket.
+//
+// Клиент с подпротоколом Subprotocol общается сообщениями в конвертах Envelope,
+// остальные клиенты - по старому строковому протоколу.
+//
+// Сессия живет в реестре registry под токеном, который клиент получает после init.
+// Если соединение оборвалось, менеджер может переподключиться с параметром ?token=<токен>
+// в течение grace-периода: он получит снимок состояния сессии и продолжит получать ее события.
+// По тому же токену к сессии подключаются наблюдатели (ObserveHandler).
+//
+// Параметры, события машин и итоговая сводка каждой симуляции сохраняются через runStore.
+// Если прогон не сохраняется, клиент узнает причину в ответе на init (record_error).
+func WebSocketHandler(log *slog.Logger, cfg *config.Config, registry *simulation.Registry, runStore RunStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.With(
+		log := log.With(
 			slog.String("reqID", middleware.GetReqID(r.Context())),
 		)
 
+		userID, _ := r.Context().Value(authMiddleware.UserIDKey).(int)
+		token := r.URL.Query().Get("token")
+
 		// upgrade rest request to websocket connection
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
@@ -42,67 +66,186 @@ func WebSocketHandler(log *slog.Logger, cfg *config.Config) http.HandlerFunc {
 		// создаем новый клиент
 		client := NewClient(conn)
 
-		var initParams struct {
-			Parking           *models.Parking               `json:"parking" validate:"required"`
-			ArrivalConfig     *simulation.ArrivalConfig     `json:"arrival_config" validate:"required"`
-			ParkingTimeConfig *simulation.ParkingTimeConfig `json:"parking_time_config" validate:"required"`
-			StartTime         int64                         `json:"start_time" validate:"required"`
+		if conn.Subprotocol() == Subprotocol {
+			serveEnvelope(log, client, registry, runStore, userID, token)
+			return
 		}
 
-		err = conn.ReadJSON(&initParams)
+		serveLegacy(log, client, registry, runStore, userID, token)
+	}
+}
+
+// serveEnvelope обслуживает клиента по протоколу с конвертами.
+// Если передан token, клиент переподключается к существующей сессии.
+func serveEnvelope(log *slog.Logger, client *Client, registry *simulation.Registry, runStore RunStore, userID int, token string) {
+	var session *simulation.Session
+	if token == "" {
+		var ok bool
+		session, token, ok = readInit(log, client, registry, runStore, userID)
+		if !ok {
+			return
+		}
+	} else {
+		var err error
+		session, err = registry.Attach(token, userID, eventSender{client: client})
 		if err != nil {
-			log.Error("error while reading params", slog.String("err", err.Error()))
-			conn.WriteJSON(resp.UnknownError("error while reading params"))
+			log.Error("error while reconnecting to session", slog.String("err", err.Error()))
+			writeReject(client, nil, err, nil)
 			return
 		}
+		log.Debug("client reconnected to session", slog.Uint64("seed", session.Seed()))
+	}
+
+	go client.WriteLoop(log)
+
+	go client.ReadLoop(log, envelopeReadFunc(session, client, registry, token))
+
+	<-client.Done
+	registry.Detach(token)
+}
+
+// readInit читает команды клиента, пока не придут корректные параметры симуляции,
+// создает по ним сессию, регистрирует ее в реестре и начинает запись прогона.
+// Вернет false, если соединение закрылось.
+func readInit(log *slog.Logger, client *Client, registry *simulation.Registry, runStore RunStore, userID int) (*simulation.Session, string, bool) {
+	for {
+		_, msg, err := client.Conn.ReadMessage()
+		if err != nil {
+			log.Error("error while reading params", slog.String("err", err.Error()))
+			return nil, "", false
+		}
+
+		env, err := parseEnvelope(msg)
+		if err == nil && env.Type != commandInit {
+			err = errInitRequired
+		}
+		if err != nil {
+			writeReject(client, env, err, nil)
+			continue
+		}
+
+		var initParams simulation.InitParams
+		if err = json.Unmarshal(env.Payload, &initParams); err != nil {
+			log.Error("error while decoding params", slog.String("err", err.Error()))
+			writeReject(client, env, errBadPayload, nil)
+			continue
+		}
 		log.Debug("params from client", slog.Any("params", initParams))
 
-		valid := custom_validator.CreateNewValidator()
-		// добавление кастомной валидации для параметров моделирования
-		valid.RegisterStructValidation(custom_validator.ArrivalConfigStructLevelValidation, simulation.ArrivalConfig{})
-		valid.RegisterStructValidation(custom_validator.ParkingTimeConfigStructLevelValidation, simulation.ParkingTimeConfig{})
-		if err := valid.Struct(&initParams); err != nil {
+		valid := custom_validator.CreateSimulationValidator()
+		if err = valid.Struct(&initParams); err != nil {
 			log.Error("validation error", slog.String("err", err.Error()))
-			validErr := err.(validator.ValidationErrors)
-			conn.WriteJSON(resp.RecursiveValidationError(validErr))
-			return
+
+			var validErr validator.ValidationErrors
+			if errors.As(err, &validErr) {
+				writeReject(client, env, errBadPayload, resp.RecursiveValidationError(validErr))
+			} else {
+				writeReject(client, env, errBadPayload, nil)
+			}
+			continue
 		}
 		log.Debug("params validation passed", slog.Any("params", initParams))
 
 		// создаем сессию клиента
 		session := simulation.NewSession(
-			client, initParams.Parking, time.Unix(initParams.StartTime, 0),
-			initParams.ArrivalConfig, initParams.ParkingTimeConfig, log,
+			eventSender{client: client}, &initParams, simulation.NewRealTimePacer(simulation.DefaultSpeed), log,
 		)
-		log.Debug("session created", slog.Any("session", session))
+		token := registry.Add(session, userID)
+		log.Debug("session created", slog.Uint64("seed", session.Seed()))
 
-		go client.WriteLoop(log)
+		ack := InitAckPayload{
+			Seed:     session.Seed(),
+			Strategy: session.Strategy(),
+			Token:    token,
+		}
+		ack.RunID, err = recordRun(log, session, &initParams, userID, runStore)
+		if err != nil {
+			ack.RecordError = rootError(err).Error()
+		}
+		sendAck(client, env.RequestID, ack)
 
-		go client.ReadLoop(log, readFunc(session, client))
+		return session, token, true
+	}
+}
 
-		client.Send([]byte("ok"))
+// writeReject отвечает на команду до запуска циклов клиента, записывая ошибку напрямую в соединение.
+func writeReject(client *Client, env *Envelope, err error, details interface{}) {
+	var requestID, command string
+	if env != nil {
+		requestID, command = env.RequestID, env.Type
+	}
 
-		<-client.Done
-		session.Stop()
+	data, err := newEnvelope(messageError, requestID, ErrorPayload{
+		Command: command,
+		Reason:  rootError(err).Error(),
+		Details: details,
+	})
+	if err != nil {
+		return
 	}
+
+	client.Conn.WriteMessage(websocket.TextMessage, data)
 }
 
-func readFunc(session *simulation.Session, client *Client) func(msg []byte) {
+// envelopeReadFunc обрабатывает команды клиента в конвертах. На каждую команду
+// клиент получает "ack" или "error" с тем же request_id.
+func envelopeReadFunc(session *simulation.Session, client *Client, registry *simulation.Registry, token string) func(msg []byte) {
 	return func(msg []byte) {
-		switch string(msg) {
-		case "start":
-			session.Start()
-		case "pause":
-			session.Pause()
-		case "resume":
-			session.Resume()
-		case "stop":
-			session.Stop()
-			client.Stop()
+		env, err := parseEnvelope(msg)
+		if err != nil {
+			var requestID, command string
+			if env != nil {
+				requestID, command = env.RequestID, env.Type
+			}
+			sendReject(client, requestID, command, err, nil)
+			return
+		}
+
+		switch env.Type {
+		case commandInit:
+			sendReject(client, env.RequestID, env.Type, errAlreadyInitiate, nil)
+		case commandStop:
+			// соединение закрывает клиент, получив подтверждение после итоговой сводки
+			registry.Remove(token)
+			<-session.Flushed()
+			sendAck(client, env.RequestID, nil)
 		default:
-			if str := string(msg); strings.HasPrefix(str, "park") {
-				go session.CheckPark(str)
+			if err = dispatch(session, env); err != nil {
+				sendReject(client, env.RequestID, env.Type, err, nil)
+				return
+			}
+			sendAck(client, env.RequestID, nil)
+		}
+	}
+}
+
+// recordRun начинает запись прогона сессии и возвращает его id. Парковку прогона ищет в БД по id
+// из параметров клиента среди парковок менеджера userID.
+// Если сохранить прогон не удалось, симуляция идет без записи, а вернется причина для клиента.
+func recordRun(log *slog.Logger, session *simulation.Session, params *simulation.InitParams, userID int, runStore RunStore) (int, error) {
+	if runStore == nil {
+		return 0, nil
+	}
+
+	parkingID := 0
+	if params.Parking.ID != 0 {
+		parking, err := runStore.GetParkingByID(params.Parking.ID, userID)
+		if err != nil {
+			log.Error("error while getting simulation run parking", slog.String("err", err.Error()), slog.Int("parking_id", params.Parking.ID))
+			if errors.Is(err, custErr.ErrParkingNotFound) || errors.Is(err, custErr.ErrParkingAccessDenied) {
+				return 0, errRunParkingNotFound
 			}
+			return 0, errRunNotRecorded
 		}
+		parkingID = parking.ID
 	}
+
+	run, err := simulation.StartRecording(session, params, parkingID, userID, runStore, log)
+	if err != nil {
+		log.Error("error while saving simulation run", slog.String("err", err.Error()))
+		return 0, errRunNotRecorded
+	}
+	log.Debug("simulation run recording", slog.Int("run_id", run.ID))
+
+	return run.ID, nil
 }
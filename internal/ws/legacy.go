@@ -0,0 +1,175 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	custom_validator "github.com/PIRSON21/parking/internal/lib/validator"
+	"github.com/PIRSON21/parking/internal/simulation"
+	"github.com/go-playground/validator/v10"
+)
+
+// serveLegacy обслуживает клиента по старому строковому протоколу:
+// параметры симуляции приходят JSON, команды - строками "start", "pause", "park <uuid>" и т.д.
+// Если передан token, клиент переподключается к существующей сессии.
+func serveLegacy(log *slog.Logger, client *Client, registry *simulation.Registry, runStore RunStore, userID int, token string) {
+	conn := client.Conn
+
+	if token != "" {
+		session, err := registry.Attach(token, userID, client)
+		if err != nil {
+			log.Error("error while reconnecting to session", slog.String("err", err.Error()))
+			conn.WriteJSON(resp.UnknownError(rootError(err).Error()))
+			return
+		}
+		log.Debug("client reconnected to session", slog.Uint64("seed", session.Seed()))
+
+		serveLegacySession(log, client, session, registry, token)
+		return
+	}
+
+	var initParams simulation.InitParams
+
+	err := conn.ReadJSON(&initParams)
+	if err != nil {
+		log.Error("error while reading params", slog.String("err", err.Error()))
+		conn.WriteJSON(resp.UnknownError("error while reading params"))
+		return
+	}
+	log.Debug("params from client", slog.Any("params", initParams))
+
+	valid := custom_validator.CreateSimulationValidator()
+	if err := valid.Struct(&initParams); err != nil {
+		log.Error("validation error", slog.String("err", err.Error()))
+
+		var validErr validator.ValidationErrors
+		if errors.As(err, &validErr) {
+			conn.WriteJSON(resp.RecursiveValidationError(validErr))
+		} else {
+			conn.WriteJSON(resp.UnknownError("error while validating params"))
+		}
+		return
+	}
+	log.Debug("params validation passed", slog.Any("params", initParams))
+
+	// создаем сессию клиента
+	session := simulation.NewSession(
+		client, &initParams, simulation.NewRealTimePacer(simulation.DefaultSpeed), log,
+	)
+	token = registry.Add(session, userID)
+	log.Debug("session created", slog.Any("session", session))
+	runID, recordErr := recordRun(log, session, &initParams, userID, runStore)
+
+	client.Send([]byte("ok"))
+	sendSessionInfo(client, session, token, runID, recordErr)
+
+	serveLegacySession(log, client, session, registry, token)
+}
+
+// serveLegacySession обменивается сообщениями с клиентом, пока тот не отключится.
+func serveLegacySession(log *slog.Logger, client *Client, session *simulation.Session, registry *simulation.Registry, token string) {
+	go client.WriteLoop(log)
+
+	go client.ReadLoop(log, readFunc(session, client, registry, token))
+
+	<-client.Done
+	registry.Detach(token)
+}
+
+// readFunc обрабатывает строковые команды клиента. Неизвестные команды отклоняются ошибкой.
+func readFunc(session *simulation.Session, client *Client, registry *simulation.Registry, token string) func(msg []byte) {
+	return func(msg []byte) {
+		switch string(msg) {
+		case "start":
+			session.Start()
+		case "pause":
+			session.Pause()
+		case "resume":
+			session.Resume()
+		case "stop":
+			registry.Remove(token)
+			<-session.Flushed()
+			client.Close()
+		default:
+			str := string(msg)
+			switch {
+			case strings.HasPrefix(str, "park"):
+				go session.CheckPark(str)
+			case strings.HasPrefix(str, "speed "):
+				setSpeed(session.SetSpeed, client, strings.TrimPrefix(str, "speed "))
+			case strings.HasPrefix(str, "skip "):
+				skipTo(session.SkipTo, client, strings.TrimPrefix(str, "skip "))
+			default:
+				sendError(client, errUnknownCommand)
+			}
+		}
+	}
+}
+
+// setSpeed обрабатывает команду "speed <множитель>": множитель скорости относительно
+// одной модельной минуты в реальную секунду. Скорость меняет set.
+func setSpeed(set func(multiplier float64) error, client *Client, arg string) {
+	multiplier, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+	if err != nil {
+		sendError(client, custErr.ErrInvalidSpeed)
+		return
+	}
+
+	if err = set(multiplier); err != nil {
+		sendError(client, err)
+	}
+}
+
+// skipTo обрабатывает команду "skip <unix-время>": перемотку до модельного времени. Перематывает skip.
+func skipTo(skip func(t time.Time) error, client *Client, arg string) {
+	timestamp, err := strconv.ParseInt(strings.TrimSpace(arg), 10, 64)
+	if err != nil {
+		sendError(client, custErr.ErrInvalidSkipTime)
+		return
+	}
+
+	if err = skip(time.Unix(timestamp, 0)); err != nil {
+		sendError(client, err)
+	}
+}
+
+// sendError отправляет клиенту текст исходной ошибки без служебных подробностей.
+func sendError(client *Client, err error) {
+	data, err := json.Marshal(resp.UnknownError(rootError(err).Error()))
+	if err != nil {
+		return
+	}
+
+	client.Send(data)
+}
+
+// sendSessionInfo сообщает клиенту параметры созданной сессии (seed и стратегию парковки),
+// чтобы ее можно было воспроизвести, токен для переподключения к ней и id сохраненного прогона
+// или причину recordErr, по которой прогон не сохраняется.
+func sendSessionInfo(client *Client, session *simulation.Session, token string, runID int, recordErr error) {
+	info := map[string]interface{}{
+		"event":    "init",
+		"seed":     session.Seed(),
+		"strategy": session.Strategy(),
+		"token":    token,
+	}
+	if runID != 0 {
+		info["run_id"] = runID
+	}
+	if recordErr != nil {
+		info["record_error"] = rootError(recordErr).Error()
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+
+	client.Send(data)
+}
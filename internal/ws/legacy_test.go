@@ -0,0 +1,96 @@
+package ws_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyMessage - сообщение сервера по старому протоколу: событие симуляции или ошибка.
+type legacyMessage struct {
+	Event string `json:"event"`
+	CarID string `json:"car_id"`
+	Token string `json:"token"`
+	Error string `json:"error"`
+	// RecordError - причина, по которой прогон не сохраняется (в сообщении "init").
+	RecordError string `json:"record_error"`
+}
+
+// readLegacy читает сообщения сервера по старому протоколу, пока не придет подходящее под match.
+func readLegacy(t *testing.T, conn *websocket.Conn, match func(msg legacyMessage) bool) legacyMessage {
+	t.Helper()
+
+	for {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(readTimeout)))
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		var msg legacyMessage
+		if json.Unmarshal(data, &msg) != nil {
+			continue
+		}
+		if match(msg) {
+			return msg
+		}
+	}
+}
+
+// sendLegacy отправляет строковую команду старого протокола.
+func sendLegacy(t *testing.T, conn *websocket.Conn, command string) {
+	t.Helper()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(command)))
+}
+
+func TestLegacyProtocol(t *testing.T) {
+	server, _ := newServer(t, time.Minute)
+	conn := dial(t, server, "", "")
+
+	require.NoError(t, conn.WriteJSON(newInitParams()))
+
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(data))
+
+	info := readLegacy(t, conn, func(msg legacyMessage) bool { return msg.Event == "init" })
+	assert.NotEmpty(t, info.Token)
+
+	// "start" запускает симуляцию: машины начинают приезжать и ждут команды "park"
+	sendLegacy(t, conn, "speed 10")
+	sendLegacy(t, conn, "start")
+	arrived := readLegacy(t, conn, func(msg legacyMessage) bool { return msg.Event == "arrive" })
+	require.NotEmpty(t, arrived.CarID)
+
+	sendLegacy(t, conn, "park "+arrived.CarID)
+	parked := readLegacy(t, conn, func(msg legacyMessage) bool { return msg.Event == "park" })
+	assert.Equal(t, arrived.CarID, parked.CarID)
+
+	sendLegacy(t, conn, "teleport")
+	rejected := readLegacy(t, conn, func(msg legacyMessage) bool { return msg.Error != "" })
+	assert.Equal(t, "неизвестная команда", rejected.Error)
+
+	sendLegacy(t, conn, "speed fast")
+	rejected = readLegacy(t, conn, func(msg legacyMessage) bool { return msg.Error != "" })
+	assert.Equal(t, "недопустимая скорость симуляции", rejected.Error)
+
+	sendLegacy(t, conn, "stop")
+	readLegacy(t, conn, func(msg legacyMessage) bool { return msg.Event == "summary" })
+}
+
+func TestLegacyProtocolInvalidParams(t *testing.T) {
+	server, _ := newServer(t, time.Minute)
+	conn := dial(t, server, "", "")
+
+	params := newInitParams()
+	params.ArrivalConfig = nil
+	require.NoError(t, conn.WriteJSON(params))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(readTimeout)))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"InitParams":{"arrival_config":"Не указано поле"}}`, string(data))
+}
@@ -0,0 +1,98 @@
+package ws
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/PIRSON21/parking/internal/config"
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	"github.com/PIRSON21/parking/internal/simulation"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ObserveHandler подключает наблюдателя к идущей симуляции по токену (?token=<токен>).
+//
+// Наблюдатель получает снимок состояния сессии и затем те же события, что и управляющий клиент,
+// но его команды отклоняются. Когда сессия останавливается, наблюдатель получает итоговую сводку
+// и соединение закрывается.
+//
+// Подключиться может любой вошедший пользователь, знающий токен: менеджер, передавший токен,
+// открывает доступ к просмотру своей симуляции (см. simulation.Registry.Observe).
+func ObserveHandler(log *slog.Logger, cfg *config.Config, registry *simulation.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("reqID", middleware.GetReqID(r.Context())),
+		)
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Error("error while upgrading webSocket conn", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+		defer conn.Close()
+
+		client := NewClient(conn)
+		envelope := conn.Subprotocol() == Subprotocol
+
+		// в протоколе с конвертами события упаковываются в конверты
+		var observer simulation.EventSender = client
+		if envelope {
+			observer = eventSender{client: client}
+		}
+
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			err = errTokenRequired
+		}
+
+		var session *simulation.Session
+		if err == nil {
+			session, err = registry.Observe(token, observer)
+		}
+		if err != nil {
+			log.Error("error while joining session", slog.String("err", err.Error()))
+			if envelope {
+				writeReject(client, nil, err, nil)
+			} else {
+				conn.WriteJSON(resp.UnknownError(rootError(err).Error()))
+			}
+			return
+		}
+		log.Debug("observer joined session", slog.Uint64("seed", session.Seed()))
+
+		go client.WriteLoop(log)
+
+		go client.ReadLoop(log, observerReadFunc(client, envelope))
+
+		select {
+		case <-client.Done:
+		case <-session.Flushed():
+			// наблюдатель получил итоговую сводку, больше событий не будет
+			client.Close()
+		}
+		session.RemoveObserver(observer)
+	}
+}
+
+// observerReadFunc отклоняет команды наблюдателя.
+func observerReadFunc(client *Client, envelope bool) func(msg []byte) {
+	return func(msg []byte) {
+		if !envelope {
+			sendError(client, errReadOnly)
+			return
+		}
+
+		env, err := parseEnvelope(msg)
+		if err != nil {
+			var requestID, command string
+			if env != nil {
+				requestID, command = env.RequestID, env.Type
+			}
+			sendReject(client, requestID, command, err, nil)
+			return
+		}
+
+		sendReject(client, env.RequestID, env.Type, errReadOnly, nil)
+	}
+}
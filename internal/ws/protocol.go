@@ -0,0 +1,211 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/simulation"
+)
+
+// ProtocolVersion - версия протокола сообщений /ws/simulate.
+const ProtocolVersion = 1
+
+// Subprotocol - имя подпротокола WebSocket, которым клиент выбирает протокол с конвертами.
+// Клиенты без подпротокола работают по старому строковому протоколу.
+const Subprotocol = "parking.v1"
+
+// Envelope - конверт любого сообщения протокола: команды клиента и ответа или события сервера.
+type Envelope struct {
+	Version   int             `json:"v"`                    // версия протокола
+	Type      string          `json:"type"`                 // тип сообщения
+	RequestID string          `json:"request_id,omitempty"` // id команды, на которую отвечает сервер
+	Payload   json.RawMessage `json:"payload,omitempty"`    // тело сообщения
+}
+
+// Типы команд клиента.
+const (
+	commandInit   = "init"   // параметры симуляции (InitParams)
+	commandStart  = "start"  // запуск симуляции
+	commandPause  = "pause"  // пауза
+	commandResume = "resume" // продолжение после паузы
+	commandStop   = "stop"   // остановка симуляции; соединение закрывает клиент
+	commandPark   = "park"   // машины доехали до въезда (ParkPayload)
+	commandSpeed  = "speed"  // изменение скорости (SpeedPayload)
+	commandSkip   = "skip"   // перемотка вперед (SkipPayload)
+	commandSeek   = "seek"   // перемотка воспроизведения прогона вперед или назад (SkipPayload)
+)
+
+// Типы сообщений сервера.
+const (
+	messageAck   = "ack"   // команда выполнена
+	messageError = "error" // команда отклонена (ErrorPayload)
+	messageEvent = "event" // событие симуляции
+)
+
+// ParkPayload - тело команды "park".
+type ParkPayload struct {
+	CarIDs []string `json:"car_ids"`
+}
+
+// SpeedPayload - тело команды "speed".
+type SpeedPayload struct {
+	Multiplier float64 `json:"multiplier"` // множитель скорости относительно одной модельной минуты в реальную секунду
+}
+
+// SkipPayload - тело команды "skip".
+type SkipPayload struct {
+	To int64 `json:"to"` // модельное время (unix), до которого нужно перемотать симуляцию
+}
+
+// InitAckPayload - тело ответа на команду "init".
+type InitAckPayload struct {
+	Seed     uint64 `json:"seed"`
+	Strategy string `json:"strategy"`
+	Token    string `json:"token"`            // токен для переподключения к сессии (?token=)
+	RunID    int    `json:"run_id,omitempty"` // id сохраненного прогона
+	// Причина, по которой прогон не сохраняется; симуляция при этом идет.
+	RecordError string `json:"record_error,omitempty"`
+}
+
+// ErrorPayload - тело ответа "error".
+type ErrorPayload struct {
+	Command string      `json:"command,omitempty"` // отклоненная команда
+	Reason  string      `json:"reason"`            // причина
+	Details interface{} `json:"details,omitempty"` // подробности (например, ошибки валидации)
+}
+
+// Ошибки разбора сообщений протокола.
+var (
+	errBadMessage      = errors.New("некорректное сообщение")
+	errBadVersion      = errors.New("неподдерживаемая версия протокола")
+	errUnknownCommand  = errors.New("неизвестная команда")
+	errBadPayload      = errors.New("некорректное тело команды")
+	errAlreadyInitiate = errors.New("симуляция уже создана")
+	errInitRequired    = errors.New("сначала нужно прислать параметры симуляции (init)")
+	errTokenRequired   = errors.New("не указан токен симуляции")
+	errReadOnly        = errors.New("наблюдатель не может управлять симуляцией")
+)
+
+// Причины, по которым прогон симуляции не сохраняется.
+var (
+	errRunNotRecorded     = errors.New("не удалось сохранить прогон симуляции")
+	errRunParkingNotFound = errors.New("прогон симуляции не сохраняется: парковка не найдена")
+)
+
+// newEnvelope собирает конверт сообщения сервера.
+func newEnvelope(msgType string, requestID string, payload interface{}) ([]byte, error) {
+	var raw json.RawMessage
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		raw = data
+	}
+
+	return json.Marshal(&Envelope{
+		Version:   ProtocolVersion,
+		Type:      msgType,
+		RequestID: requestID,
+		Payload:   raw,
+	})
+}
+
+// sendEnvelope отправляет клиенту сообщение в конверте.
+func sendEnvelope(client *Client, msgType string, requestID string, payload interface{}) {
+	data, err := newEnvelope(msgType, requestID, payload)
+	if err != nil {
+		return
+	}
+
+	client.Send(data)
+}
+
+// sendAck подтверждает выполнение команды.
+func sendAck(client *Client, requestID string, payload interface{}) {
+	sendEnvelope(client, messageAck, requestID, payload)
+}
+
+// sendReject сообщает клиенту, что команда command отклонена по причине err.
+func sendReject(client *Client, requestID string, command string, err error, details interface{}) {
+	sendEnvelope(client, messageError, requestID, ErrorPayload{
+		Command: command,
+		Reason:  rootError(err).Error(),
+		Details: details,
+	})
+}
+
+// parseEnvelope разбирает команду клиента и проверяет версию протокола.
+func parseEnvelope(msg []byte) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(msg, &env); err != nil || env.Type == "" {
+		return nil, errBadMessage
+	}
+
+	if env.Version != ProtocolVersion {
+		return &env, errBadVersion
+	}
+
+	return &env, nil
+}
+
+// eventSender упаковывает события симуляции в конверты протокола.
+type eventSender struct {
+	client *Client
+}
+
+// Send нужна для имплементации интерфейса simulation.EventSender.
+func (s eventSender) Send(data []byte) {
+	msg, err := json.Marshal(&Envelope{
+		Version: ProtocolVersion,
+		Type:    messageEvent,
+		Payload: data,
+	})
+	if err != nil {
+		return
+	}
+
+	s.client.Send(msg)
+}
+
+// dispatch выполняет команду env над сессией. Команды "init" и "stop" обрабатываются соединением.
+func dispatch(session *simulation.Session, env *Envelope) error {
+	switch env.Type {
+	case commandStart:
+		return session.Start()
+	case commandPause:
+		return session.Pause()
+	case commandResume:
+		return session.Resume()
+	case commandPark:
+		var payload ParkPayload
+		if err := json.Unmarshal(env.Payload, &payload); err != nil || len(payload.CarIDs) == 0 {
+			return errBadPayload
+		}
+		return session.Park(payload.CarIDs...)
+	case commandSpeed:
+		var payload SpeedPayload
+		if err := json.Unmarshal(env.Payload, &payload); err != nil {
+			return errBadPayload
+		}
+		return session.SetSpeed(payload.Multiplier)
+	case commandSkip:
+		var payload SkipPayload
+		if err := json.Unmarshal(env.Payload, &payload); err != nil || payload.To == 0 {
+			return errBadPayload
+		}
+		return session.SkipTo(time.Unix(payload.To, 0))
+	default:
+		return errUnknownCommand
+	}
+}
+
+// rootError возвращает исходную ошибку без служебных подробностей.
+func rootError(err error) error {
+	for errors.Unwrap(err) != nil {
+		err = errors.Unwrap(err)
+	}
+
+	return err
+}
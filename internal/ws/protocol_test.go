@@ -0,0 +1,311 @@
+package ws_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/PIRSON21/parking/internal/simulation"
+	"github.com/PIRSON21/parking/internal/ws"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readTimeout - сколько тест ждет сообщения сервера.
+const readTimeout = 5 * time.Second
+
+// newInitParams создает параметры симуляции: машина приезжает каждую модельную минуту и стоит 12 минут.
+func newInitParams() simulation.InitParams {
+	seed := uint64(42)
+
+	return simulation.InitParams{
+		Parking: &models.Parking{
+			Name:        "1: Центр",
+			Address:     "ул. Пушкина, д. Колотушкина",
+			Width:       4,
+			Height:      4,
+			DayTariff:   test.NewMoney(10000),
+			NightTariff: test.NewMoney(10000),
+			TimeZone:    "UTC",
+			Cells: [][]models.ParkingCell{
+				{".", "P", "P", "P"},
+				{".", ".", ".", "."},
+				{"D", "D", ".", "."},
+				{".", "I", "O", "."},
+			},
+		},
+		ArrivalConfig: &simulation.ArrivalConfig{
+			Type:         "discrete",
+			DiscreteTime: 1,
+			ParkingProb:  1,
+		},
+		ParkingTimeConfig: &simulation.ParkingTimeConfig{
+			Type:         "discrete",
+			DiscreteTime: 12,
+		},
+		StartTime: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC).Unix(),
+		Seed:      &seed,
+	}
+}
+
+// newServer запускает тестовый сервер с WebSocketHandler и реестром сессий с grace-периодом grace.
+func newServer(t *testing.T, grace time.Duration) (*httptest.Server, *simulation.Registry) {
+	t.Helper()
+
+	log := slogdiscard.NewDiscardLogger()
+	registry := simulation.NewRegistry(grace, log)
+	server := httptest.NewServer(ws.WebSocketHandler(log, &config.Config{Environment: test.EnvLocal}, registry, nil))
+	t.Cleanup(server.Close)
+
+	return server, registry
+}
+
+// dial подключается к серверу server с подпротоколом subprotocol (пустой - старый протокол)
+// и параметрами запроса query.
+func dial(t *testing.T, server *httptest.Server, subprotocol string, query string) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{HandshakeTimeout: readTimeout}
+	if subprotocol != "" {
+		dialer.Subprotocols = []string{subprotocol}
+	}
+
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// sendCommand отправляет команду в конверте.
+func sendCommand(t *testing.T, conn *websocket.Conn, version int, cmdType string, requestID string, payload interface{}) {
+	t.Helper()
+
+	var raw json.RawMessage
+	if payload != nil {
+		raw = test.MustMarshal(payload)
+	}
+
+	require.NoError(t, conn.WriteJSON(ws.Envelope{Version: version, Type: cmdType, RequestID: requestID, Payload: raw}))
+}
+
+// readReply читает сообщения сервера, пропуская события симуляции, до ответа на команду.
+func readReply(t *testing.T, conn *websocket.Conn) ws.Envelope {
+	t.Helper()
+
+	for {
+		var env ws.Envelope
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(readTimeout)))
+		require.NoError(t, conn.ReadJSON(&env))
+		if env.Type != "event" {
+			return env
+		}
+	}
+}
+
+// initSession создает сессию командой "init" и возвращает ответ на нее.
+func initSession(t *testing.T, conn *websocket.Conn) ws.InitAckPayload {
+	t.Helper()
+
+	sendCommand(t, conn, ws.ProtocolVersion, "init", "init-1", newInitParams())
+
+	reply := readReply(t, conn)
+	require.Equal(t, "ack", reply.Type, string(reply.Payload))
+	assert.Equal(t, "init-1", reply.RequestID)
+
+	var ack ws.InitAckPayload
+	require.NoError(t, json.Unmarshal(reply.Payload, &ack))
+
+	return ack
+}
+
+func TestEnvelopeProtocol(t *testing.T) {
+	server, _ := newServer(t, time.Minute)
+	conn := dial(t, server, ws.Subprotocol, "")
+
+	ack := initSession(t, conn)
+	assert.Equal(t, uint64(42), ack.Seed)
+	assert.Equal(t, "nearest-entrance", ack.Strategy)
+	assert.NotEmpty(t, ack.Token)
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name           string
+		Version        int
+		Type           string
+		Payload        interface{}
+		ExpectedType   string
+		ExpectedReason string
+	}{
+		{
+			Name:           "Pause while not running",
+			Type:           "pause",
+			ExpectedType:   "error",
+			ExpectedReason: custErr.ErrSimulationNotRunning.Error(),
+		},
+		{
+			Name:         "Start",
+			Type:         "start",
+			ExpectedType: "ack",
+		},
+		{
+			Name:           "Start twice",
+			Type:           "start",
+			ExpectedType:   "error",
+			ExpectedReason: custErr.ErrSimulationAlreadyStarted.Error(),
+		},
+		{
+			Name:         "Pause",
+			Type:         "pause",
+			ExpectedType: "ack",
+		},
+		{
+			Name:         "Resume",
+			Type:         "resume",
+			ExpectedType: "ack",
+		},
+		{
+			Name:         "Speed",
+			Type:         "speed",
+			Payload:      ws.SpeedPayload{Multiplier: 2},
+			ExpectedType: "ack",
+		},
+		{
+			Name:           "Speed over max",
+			Type:           "speed",
+			Payload:        ws.SpeedPayload{Multiplier: 2000},
+			ExpectedType:   "error",
+			ExpectedReason: custErr.ErrInvalidSpeed.Error(),
+		},
+		{
+			Name:         "Skip",
+			Type:         "skip",
+			Payload:      ws.SkipPayload{To: start.Add(time.Hour).Unix()},
+			ExpectedType: "ack",
+		},
+		{
+			Name:           "Skip without time",
+			Type:           "skip",
+			Payload:        ws.SkipPayload{},
+			ExpectedType:   "error",
+			ExpectedReason: "некорректное тело команды",
+		},
+		{
+			Name:           "Park car that is not waiting",
+			Type:           "park",
+			Payload:        ws.ParkPayload{CarIDs: []string{uuid.NewString()}},
+			ExpectedType:   "error",
+			ExpectedReason: custErr.ErrCarNotWaiting.Error(),
+		},
+		{
+			Name:           "Park without cars",
+			Type:           "park",
+			Payload:        ws.ParkPayload{},
+			ExpectedType:   "error",
+			ExpectedReason: "некорректное тело команды",
+		},
+		{
+			Name:           "Init twice",
+			Type:           "init",
+			Payload:        newInitParams(),
+			ExpectedType:   "error",
+			ExpectedReason: "симуляция уже создана",
+		},
+		{
+			Name:           "Unknown command",
+			Type:           "teleport",
+			ExpectedType:   "error",
+			ExpectedReason: "неизвестная команда",
+		},
+		{
+			Name:           "Unsupported version",
+			Version:        2,
+			Type:           "pause",
+			ExpectedType:   "error",
+			ExpectedReason: "неподдерживаемая версия протокола",
+		},
+		{
+			Name:         "Stop",
+			Type:         "stop",
+			ExpectedType: "ack",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			version := tc.Version
+			if version == 0 {
+				version = ws.ProtocolVersion
+			}
+			requestID := uuid.NewString()
+
+			sendCommand(t, conn, version, tc.Type, requestID, tc.Payload)
+
+			reply := readReply(t, conn)
+			require.Equal(t, tc.ExpectedType, reply.Type, "command %d: %s", i, reply.Payload)
+			assert.Equal(t, requestID, reply.RequestID)
+
+			if tc.ExpectedType != "error" {
+				return
+			}
+
+			var payload ws.ErrorPayload
+			require.NoError(t, json.Unmarshal(reply.Payload, &payload))
+			assert.Equal(t, tc.Type, payload.Command)
+			assert.Equal(t, tc.ExpectedReason, payload.Reason)
+		})
+	}
+}
+
+func TestEnvelopeProtocolBeforeInit(t *testing.T) {
+	server, _ := newServer(t, time.Minute)
+	conn := dial(t, server, ws.Subprotocol, "")
+
+	cases := []struct {
+		Name           string
+		Message        string
+		ExpectedReason string
+	}{
+		{
+			Name:           "Not JSON",
+			Message:        `start`,
+			ExpectedReason: "некорректное сообщение",
+		},
+		{
+			Name:           "Command before init",
+			Message:        `{"v":1,"type":"start","request_id":"1"}`,
+			ExpectedReason: "сначала нужно прислать параметры симуляции (init)",
+		},
+		{
+			Name:           "Init without params",
+			Message:        `{"v":1,"type":"init","request_id":"1","payload":"params"}`,
+			ExpectedReason: "некорректное тело команды",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(tc.Message)))
+
+			reply := readReply(t, conn)
+			require.Equal(t, "error", reply.Type)
+
+			var payload ws.ErrorPayload
+			require.NoError(t, json.Unmarshal(reply.Payload, &payload))
+			assert.Equal(t, tc.ExpectedReason, payload.Reason)
+		})
+	}
+
+	// после отклоненных команд сессию все еще можно создать
+	initSession(t, conn)
+}
@@ -0,0 +1,150 @@
+package ws_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/PIRSON21/parking/internal/simulation"
+	"github.com/PIRSON21/parking/internal/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunStore хранит прогоны в памяти и знает только парковки parkings.
+type fakeRunStore struct {
+	mu        sync.Mutex
+	parkings  map[int]*models.Parking
+	createErr error
+	runs      []models.SimulationRun
+}
+
+// CreateSimulationRun нужна для имплементации интерфейса ws.RunStore.
+func (s *fakeRunStore) CreateSimulationRun(run *models.SimulationRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.createErr != nil {
+		return s.createErr
+	}
+	run.ID = len(s.runs) + 1
+	s.runs = append(s.runs, *run)
+
+	return nil
+}
+
+// AddSimulationEvents нужна для имплементации интерфейса ws.RunStore.
+func (s *fakeRunStore) AddSimulationEvents(int, []models.SimulationEvent) error {
+	return nil
+}
+
+// FinishSimulationRun нужна для имплементации интерфейса ws.RunStore.
+func (s *fakeRunStore) FinishSimulationRun(int, json.RawMessage) error {
+	return nil
+}
+
+// GetParkingByID нужна для имплементации интерфейса ws.RunStore.
+func (s *fakeRunStore) GetParkingByID(parkingID int, _ int) (*models.Parking, error) {
+	parking, ok := s.parkings[parkingID]
+	if !ok {
+		return nil, custErr.ErrParkingNotFound
+	}
+
+	return parking, nil
+}
+
+// newStoreServer запускает тестовый сервер с WebSocketHandler, который сохраняет прогоны в store.
+func newStoreServer(t *testing.T, store ws.RunStore) *httptest.Server {
+	t.Helper()
+
+	log := slogdiscard.NewDiscardLogger()
+	registry := simulation.NewRegistry(time.Minute, log)
+	server := httptest.NewServer(ws.WebSocketHandler(log, &config.Config{Environment: test.EnvLocal}, registry, store))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRecordRun(t *testing.T) {
+	cases := []struct {
+		Name              string
+		ParkingID         int
+		CreateErr         error
+		ExpectedRunID     int
+		ExpectedParkingID *int
+		ExpectedError     string
+	}{
+		{
+			Name:          "Without parking",
+			ExpectedRunID: 1,
+		},
+		{
+			Name:              "Parking from storage",
+			ParkingID:         7,
+			ExpectedRunID:     1,
+			ExpectedParkingID: test.NewInt(7),
+		},
+		{
+			Name:          "Unknown parking",
+			ParkingID:     8,
+			ExpectedError: "прогон симуляции не сохраняется: парковка не найдена",
+		},
+		{
+			Name:          "Storage error",
+			CreateErr:     errors.New("insert failed"),
+			ExpectedError: "не удалось сохранить прогон симуляции",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			store := &fakeRunStore{
+				parkings:  map[int]*models.Parking{7: {ID: 7}},
+				createErr: tc.CreateErr,
+			}
+			conn := dial(t, newStoreServer(t, store), ws.Subprotocol, "")
+
+			params := newInitParams()
+			params.Parking.ID = tc.ParkingID
+			sendCommand(t, conn, ws.ProtocolVersion, "init", "init-1", params)
+
+			reply := readReply(t, conn)
+			require.Equal(t, "ack", reply.Type, string(reply.Payload))
+
+			var ack ws.InitAckPayload
+			require.NoError(t, json.Unmarshal(reply.Payload, &ack))
+			assert.NotEmpty(t, ack.Token)
+			assert.Equal(t, tc.ExpectedRunID, ack.RunID)
+			assert.Equal(t, tc.ExpectedError, ack.RecordError)
+
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			if tc.ExpectedRunID == 0 {
+				assert.Empty(t, store.runs)
+				return
+			}
+			require.Len(t, store.runs, 1)
+			assert.Equal(t, tc.ExpectedParkingID, store.runs[0].ParkingID)
+		})
+	}
+}
+
+func TestRecordRunLegacy(t *testing.T) {
+	conn := dial(t, newStoreServer(t, &fakeRunStore{}), "", "")
+
+	params := newInitParams()
+	params.Parking.ID = 8
+	require.NoError(t, conn.WriteJSON(params))
+
+	info := readLegacy(t, conn, func(msg legacyMessage) bool { return msg.Event == "init" })
+	assert.NotEmpty(t, info.Token)
+	assert.Equal(t, "прогон симуляции не сохраняется: парковка не найдена", info.RecordError)
+}
@@ -0,0 +1,187 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	authMiddleware "github.com/PIRSON21/parking/internal/lib/api/auth/middleware"
+	resp "github.com/PIRSON21/parking/internal/lib/api/response"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/simulation"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ReplayHandler воспроизводит менеджеру сохраненный прогон симуляции по WebSocket.
+//
+// Клиент сначала получает описание прогона (событие "replay" с параметрами симуляции),
+// затем после "start" - события машин в том же виде, что и в живой сессии.
+// Поддерживаются команды "pause", "resume", "speed", "stop" и перемотка в любую сторону ("seek" или "skip").
+// Команды "park" принимаются и игнорируются: машины заезжают в сохраненное время.
+func ReplayHandler(log *slog.Logger, cfg *config.Config, runLoader simulation.RunLoader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("reqID", middleware.GetReqID(r.Context())),
+		)
+
+		runID, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("error while getting ID from url", slog.String("err", err.Error()))
+			http.NotFound(w, r)
+			return
+		}
+
+		userID, _ := r.Context().Value(authMiddleware.UserIDKey).(int)
+
+		run, err := runLoader.GetSimulationRun(runID, userID)
+		if err != nil {
+			if errors.Is(err, custErr.ErrSimulationRunNotFound) {
+				log.Debug("simulation run not found", slog.Int("runID", runID), slog.Int("userID", userID))
+				http.NotFound(w, r)
+				return
+			}
+
+			log.Error("error while getting simulation run from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		events, err := runLoader.GetSimulationEvents(runID)
+		if err != nil {
+			log.Error("error while getting simulation events from DB", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Error("error while upgrading webSocket conn", slog.String("err", err.Error()))
+			resp.ErrorHandler(w, r, cfg, err)
+			return
+		}
+		defer conn.Close()
+
+		client := NewClient(conn)
+		envelope := conn.Subprotocol() == Subprotocol
+
+		// в протоколе с конвертами события упаковываются в конверты
+		var sender simulation.EventSender = client
+		if envelope {
+			sender = eventSender{client: client}
+		}
+
+		replay := simulation.NewReplay(sender, run, events, log)
+		onMessage := replayReadFunc(replay, client)
+		if envelope {
+			onMessage = replayEnvelopeReadFunc(replay, client)
+		}
+		log.Debug("replay created", slog.Int("run_id", run.ID), slog.Int("events", len(events)))
+
+		if data, err := json.Marshal(replay.Info()); err == nil {
+			sender.Send(data)
+		}
+
+		go client.WriteLoop(log)
+
+		go client.ReadLoop(log, onMessage)
+
+		<-client.Done
+		replay.Stop()
+	}
+}
+
+// replayEnvelopeReadFunc обрабатывает команды воспроизведения в конвертах. На каждую команду
+// клиент получает "ack" или "error" с тем же request_id.
+func replayEnvelopeReadFunc(replay *simulation.Replay, client *Client) func(msg []byte) {
+	return func(msg []byte) {
+		env, err := parseEnvelope(msg)
+		if err != nil {
+			var requestID, command string
+			if env != nil {
+				requestID, command = env.RequestID, env.Type
+			}
+			sendReject(client, requestID, command, err, nil)
+			return
+		}
+
+		if err = dispatchReplay(replay, env); err != nil {
+			sendReject(client, env.RequestID, env.Type, err, nil)
+			return
+		}
+		sendAck(client, env.RequestID, nil)
+	}
+}
+
+// dispatchReplay выполняет команду env над воспроизведением.
+func dispatchReplay(replay *simulation.Replay, env *Envelope) error {
+	switch env.Type {
+	case commandInit:
+		return errAlreadyInitiate
+	case commandStart:
+		return replay.Start()
+	case commandPause:
+		return replay.Pause()
+	case commandResume:
+		return replay.Resume()
+	case commandStop:
+		// соединение закрывает клиент, получив подтверждение
+		replay.Stop()
+		return nil
+	case commandPark:
+		return nil
+	case commandSpeed:
+		var payload SpeedPayload
+		if err := json.Unmarshal(env.Payload, &payload); err != nil {
+			return errBadPayload
+		}
+		return replay.SetSpeed(payload.Multiplier)
+	case commandSkip, commandSeek:
+		var payload SkipPayload
+		if err := json.Unmarshal(env.Payload, &payload); err != nil || payload.To == 0 {
+			return errBadPayload
+		}
+		return replay.Seek(time.Unix(payload.To, 0))
+	default:
+		return errUnknownCommand
+	}
+}
+
+// replayReadFunc обрабатывает строковые команды воспроизведения. Неизвестные команды отклоняются ошибкой.
+func replayReadFunc(replay *simulation.Replay, client *Client) func(msg []byte) {
+	return func(msg []byte) {
+		var err error
+
+		str := string(msg)
+		switch {
+		case str == "start":
+			err = replay.Start()
+		case str == "pause":
+			err = replay.Pause()
+		case str == "resume":
+			err = replay.Resume()
+		case str == "stop":
+			replay.Stop()
+			client.Close()
+		case strings.HasPrefix(str, "park"):
+			// машины заезжают в сохраненное время
+		case strings.HasPrefix(str, "speed "):
+			setSpeed(replay.SetSpeed, client, strings.TrimPrefix(str, "speed "))
+		case strings.HasPrefix(str, "skip "):
+			skipTo(replay.Seek, client, strings.TrimPrefix(str, "skip "))
+		case strings.HasPrefix(str, "seek "):
+			skipTo(replay.Seek, client, strings.TrimPrefix(str, "seek "))
+		default:
+			err = errUnknownCommand
+		}
+
+		if err != nil {
+			sendError(client, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package ws_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PIRSON21/parking/internal/config"
+	custErr "github.com/PIRSON21/parking/internal/lib/errors"
+	"github.com/PIRSON21/parking/internal/lib/logger/handlers/slogdiscard"
+	"github.com/PIRSON21/parking/internal/lib/test"
+	"github.com/PIRSON21/parking/internal/models"
+	"github.com/PIRSON21/parking/internal/simulation"
+	"github.com/PIRSON21/parking/internal/ws"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runLoader отдает один сохраненный прогон.
+type runLoader struct {
+	run    *models.SimulationRun
+	events []models.SimulationEvent
+}
+
+// GetSimulationRun нужна для имплементации интерфейса simulation.RunLoader.
+func (l *runLoader) GetSimulationRun(runID int, _ int) (*models.SimulationRun, error) {
+	if runID != l.run.ID {
+		return nil, custErr.ErrSimulationRunNotFound
+	}
+
+	return l.run, nil
+}
+
+// GetSimulationEvents нужна для имплементации интерфейса simulation.RunLoader.
+func (l *runLoader) GetSimulationEvents(int) ([]models.SimulationEvent, error) {
+	return l.events, nil
+}
+
+// newRunLoader создает прогон длиной в час: машина a стоит с 10:01 до 10:15, машина b приезжает в 10:20.
+func newRunLoader(t *testing.T) *runLoader {
+	t.Helper()
+
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	stored := []struct {
+		Event  string
+		CarID  string
+		Minute int
+	}{
+		{Event: "arrive", CarID: "a", Minute: 1},
+		{Event: "park", CarID: "a", Minute: 1},
+		{Event: "leave", CarID: "a", Minute: 15},
+		{Event: "arrive", CarID: "b", Minute: 20},
+	}
+
+	events := make([]models.SimulationEvent, len(stored))
+	for i, s := range stored {
+		timestamp := start.Add(time.Duration(s.Minute) * time.Minute).Unix()
+		events[i] = models.SimulationEvent{
+			Seq:       i + 1,
+			Type:      s.Event,
+			TimeStamp: timestamp,
+			CarID:     s.CarID,
+			Payload:   test.MustMarshal(simulation.CarEvent{Event: s.Event, CarID: s.CarID, Class: models.Car, TimeStamp: timestamp}),
+		}
+	}
+
+	return &runLoader{
+		run: &models.SimulationRun{
+			ID:        7,
+			Seed:      42,
+			Strategy:  "nearest-entrance",
+			Params:    []byte(`{"start_time":` + strconv.FormatInt(start.Unix(), 10) + `}`),
+			Summary:   []byte(`{"event":"summary","timestamp":` + strconv.FormatInt(start.Add(time.Hour).Unix(), 10) + `}`),
+			StartedAt: start,
+		},
+		events: events,
+	}
+}
+
+// newReplayServer запускает тестовый сервер с ReplayHandler.
+func newReplayServer(t *testing.T, loader simulation.RunLoader) *httptest.Server {
+	t.Helper()
+
+	router := chi.NewRouter()
+	router.Get("/ws/replay/{id}", ws.ReplayHandler(slogdiscard.NewDiscardLogger(), &config.Config{Environment: test.EnvLocal}, loader))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// readUntilReply читает сообщения сервера до ответа на команду и возвращает события, пришедшие перед ним.
+func readUntilReply(t *testing.T, conn *websocket.Conn) ([]json.RawMessage, ws.Envelope) {
+	t.Helper()
+
+	var events []json.RawMessage
+	for {
+		var env ws.Envelope
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(readTimeout)))
+		require.NoError(t, conn.ReadJSON(&env))
+		if env.Type != "event" {
+			return events, env
+		}
+		events = append(events, env.Payload)
+	}
+}
+
+// readCarEvent читает сообщения сервера до первого события машины.
+func readCarEvent(t *testing.T, conn *websocket.Conn) simulation.CarEvent {
+	t.Helper()
+
+	for {
+		var env ws.Envelope
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(readTimeout)))
+		require.NoError(t, conn.ReadJSON(&env))
+		if env.Type != "event" {
+			continue
+		}
+
+		var event simulation.CarEvent
+		require.NoError(t, json.Unmarshal(env.Payload, &event))
+		if event.CarID != "" {
+			return event
+		}
+	}
+}
+
+func TestReplayHandlerSeek(t *testing.T) {
+	loader := newRunLoader(t)
+	server := newReplayServer(t, loader)
+	conn := dial(t, server, ws.Subprotocol, "/ws/replay/7")
+
+	var env ws.Envelope
+	require.NoError(t, conn.ReadJSON(&env))
+	var info simulation.ReplayInfo
+	require.NoError(t, json.Unmarshal(env.Payload, &info))
+	assert.Equal(t, "replay", info.Event)
+	assert.Equal(t, len(loader.events), info.Events)
+
+	// в 10:10 машина a стоит на месте, следующее событие - ее отъезд в 10:15
+	seekTo := info.StartTime + 10*60
+	sendCommand(t, conn, ws.ProtocolVersion, "seek", "seek-1", ws.SkipPayload{To: seekTo})
+	events, reply := readUntilReply(t, conn)
+	require.Equal(t, "ack", reply.Type, string(reply.Payload))
+	assert.Equal(t, "seek-1", reply.RequestID)
+	require.Len(t, events, 2)
+
+	var snapshot simulation.Snapshot
+	require.NoError(t, json.Unmarshal(events[0], &snapshot))
+	assert.Equal(t, "snapshot", snapshot.Event)
+	assert.Equal(t, seekTo, snapshot.TimeStamp)
+	assert.Equal(t, 1, snapshot.Occupied)
+	require.Len(t, snapshot.Cars, 1)
+	assert.Equal(t, "a", snapshot.Cars[0].CarID)
+
+	var skipped simulation.SessionEvent
+	require.NoError(t, json.Unmarshal(events[1], &skipped))
+	require.NotNil(t, skipped.SkippedTo)
+	assert.Equal(t, seekTo, *skipped.SkippedTo)
+
+	sendCommand(t, conn, ws.ProtocolVersion, "speed", "speed-1", ws.SpeedPayload{Multiplier: simulation.MaxSpeedMultiplier})
+	sendCommand(t, conn, ws.ProtocolVersion, "start", "start-1", nil)
+
+	next := readCarEvent(t, conn)
+	assert.Equal(t, "leave", next.Event)
+	assert.Equal(t, "a", next.CarID)
+	assert.Equal(t, loader.events[2].TimeStamp, next.TimeStamp)
+
+	next = readCarEvent(t, conn)
+	assert.Equal(t, "arrive", next.Event)
+	assert.Equal(t, "b", next.CarID)
+}
+
+func TestReplayHandlerRejects(t *testing.T) {
+	loader := newRunLoader(t)
+	server := newReplayServer(t, loader)
+	conn := dial(t, server, ws.Subprotocol, "/ws/replay/7")
+
+	cases := []struct {
+		Name           string
+		Type           string
+		Payload        interface{}
+		ExpectedReason string
+	}{
+		{
+			Name:           "Seek out of run",
+			Type:           "seek",
+			Payload:        ws.SkipPayload{To: loader.events[0].TimeStamp + 24*60*60},
+			ExpectedReason: custErr.ErrInvalidSeekTime.Error(),
+		},
+		{
+			Name:           "Pause before start",
+			Type:           "pause",
+			ExpectedReason: custErr.ErrSimulationNotRunning.Error(),
+		},
+		{
+			Name:           "Unknown command",
+			Type:           "teleport",
+			ExpectedReason: "неизвестная команда",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			sendCommand(t, conn, ws.ProtocolVersion, tc.Type, tc.Name, tc.Payload)
+
+			reply := readReply(t, conn)
+			require.Equal(t, "error", reply.Type)
+			assert.Equal(t, tc.Name, reply.RequestID)
+
+			var payload ws.ErrorPayload
+			require.NoError(t, json.Unmarshal(reply.Payload, &payload))
+			assert.Equal(t, tc.ExpectedReason, payload.Reason)
+		})
+	}
+}